@@ -27,6 +27,20 @@ import (
 	profileRepo "github.com/kisssonik/hearts/internal/profile/repository"
 	profileService "github.com/kisssonik/hearts/internal/profile/service"
 
+	photoHandler "github.com/kisssonik/hearts/internal/photo/handler"
+	"github.com/kisssonik/hearts/internal/photo/moderation"
+	photoRepo "github.com/kisssonik/hearts/internal/photo/repository"
+	photoService "github.com/kisssonik/hearts/internal/photo/service"
+
+	"github.com/kisssonik/hearts/internal/verification/facematch"
+	verificationHandler "github.com/kisssonik/hearts/internal/verification/handler"
+	verificationRepo "github.com/kisssonik/hearts/internal/verification/repository"
+	verificationService "github.com/kisssonik/hearts/internal/verification/service"
+
+	blockHandler "github.com/kisssonik/hearts/internal/block/handler"
+	blockRepo "github.com/kisssonik/hearts/internal/block/repository"
+	blockService "github.com/kisssonik/hearts/internal/block/service"
+
 	reviewHandler "github.com/kisssonik/hearts/internal/review/handler"
 	reviewRepo "github.com/kisssonik/hearts/internal/review/repository"
 	reviewService "github.com/kisssonik/hearts/internal/review/service"
@@ -42,9 +56,11 @@ import (
 	"github.com/kisssonik/hearts/pkg/auth"
 	"github.com/kisssonik/hearts/pkg/config"
 	"github.com/kisssonik/hearts/pkg/database"
+	"github.com/kisssonik/hearts/pkg/imaging"
 	"github.com/kisssonik/hearts/pkg/logger"
 	"github.com/kisssonik/hearts/pkg/middleware"
 	"github.com/kisssonik/hearts/pkg/queue"
+	"github.com/kisssonik/hearts/pkg/quota"
 	"github.com/kisssonik/hearts/pkg/storage"
 	"github.com/kisssonik/hearts/pkg/websocket"
 	"go.uber.org/zap"
@@ -138,6 +154,13 @@ func main() {
 	kafkaConsumer := queue.NewKafkaConsumer(cfg.Kafka.Brokers, cfg.Kafka.Topic, cfg.Kafka.GroupID, appLogger)
 	defer kafkaConsumer.Close()
 
+	// Photo moderation (Topic: photo-moderation)
+	moderationProducer := queue.NewKafkaProducer(cfg.Kafka.Brokers, "photo-moderation", appLogger)
+	defer moderationProducer.Close()
+
+	moderationConsumer := queue.NewKafkaConsumer(cfg.Kafka.Brokers, "photo-moderation", "hearts-photo-moderator", appLogger)
+	defer moderationConsumer.Close()
+
 	// WebSocket Hub
 	wsHub := websocket.NewHub()
 	go wsHub.Run()
@@ -148,18 +171,49 @@ func main() {
 	userService := service.NewUserService(userRepo, authService)
 	userHandler := handler.NewUserHandler(userService, authService, appLogger)
 
-	pRepo := profileRepo.NewProfileRepository(dbPool)
-	pService := profileService.NewProfileService(pRepo)
-	pHandler := profileHandler.NewProfileHandler(pService, storageProvider, appLogger)
+	// Photo uploads and selfies share one limit on images decoded at once.
+	imageSlots := imaging.NewSlots(max(cfg.Photo.MaxInFlight, 1))
+
+	phRepo := photoRepo.NewPhotoRepository(dbPool)
+	phService := photoService.NewPhotoService(phRepo, storageProvider, moderationProducer, moderation.NewStubClassifier(), photoService.Config{
+		MaxBytes:     cfg.Photo.MaxBytes,
+		MaxCount:     cfg.Photo.MaxCount,
+		MinDimension: cfg.Photo.MinDimension,
+		MaxDimension: cfg.Photo.MaxDimension,
+		MaxPixels:    cfg.Photo.MaxPixels,
+		Slots:        imageSlots,
+	})
+	phHandler := photoHandler.NewPhotoHandler(phService, appLogger)
+
+	vRepo := verificationRepo.NewVerificationRepository(dbPool)
+	vService := verificationService.NewVerificationService(vRepo, phRepo, storageProvider, facematch.NewStubComparer(), imageSlots)
+	vHandler := verificationHandler.NewVerificationHandler(vService, appLogger)
 
 	nRepo := notificationRepo.NewNotificationRepository(dbPool)
 	nService := notificationService.NewNotificationService(nRepo, wsHub, notificationProducer)
 	nHandler := notificationHandler.NewNotificationHandler(nService, appLogger)
 
+	pRepo := profileRepo.NewProfileRepository(dbPool)
+	pService := profileService.NewProfileService(pRepo, nService, profileService.Config{
+		MinAge:      cfg.Profile.MinAge,
+		NotifyViews: cfg.Profile.NotifyViews,
+	})
+	pHandler := profileHandler.NewProfileHandler(pService, storageProvider, appLogger)
+
 	lRepo := likeRepo.NewLikeRepository(dbPool)
-	lService := likeService.NewLikeService(lRepo, pRepo, nService, kafkaProducer)
+	lService := likeService.NewLikeService(lRepo, pRepo, nService, kafkaProducer, wsHub, likeService.Config{
+		Entitlements:     likeService.StaticEntitlements{ReceivedLikes: cfg.Likes.ReceivedFullList},
+		SuperLikesPerDay: cfg.Likes.SuperLikesPerDay,
+		Limiter:          quota.NewPostgresLimiter(dbPool),
+		LikeLimit:        quota.Limit{Max: cfg.Likes.LikeLimit, Window: cfg.Likes.LikeWindow},
+		RewindWindow:     cfg.Likes.RewindWindow,
+	})
 	lHandler := likeHandler.NewLikeHandler(lService, storageProvider, appLogger)
 
+	bRepo := blockRepo.NewBlockRepository(dbPool)
+	bService := blockService.NewBlockService(bRepo)
+	bHandler := blockHandler.NewBlockHandler(bService, appLogger)
+
 	rRepo := reviewRepo.NewReviewRepository(dbPool)
 	rService := reviewService.NewReviewService(rRepo, lRepo)
 	rHandler := reviewHandler.NewReviewHandler(rService, appLogger)
@@ -213,7 +267,27 @@ func main() {
 		}
 	}()
 
+	// Start Photo Moderation Worker
+	go func() {
+		appLogger.Info("Starting photo moderation worker")
+		ctx := context.Background()
+		err := moderationConsumer.Subscribe(ctx, func(ctx context.Context, msg []byte) error {
+			var m photoService.ModerationMessage
+			if err := json.Unmarshal(msg, &m); err != nil {
+				return err
+			}
+			return phService.ProcessModeration(ctx, m.PhotoID)
+		})
+		if err != nil {
+			appLogger.Error("Moderation consumer stopped", zap.Error(err))
+		}
+	}()
+
 	authMiddleware := auth.Middleware(authService, appLogger)
+	adminMiddleware := auth.RequireAdmin(cfg.Auth.AdminUserIDs)
+	adminOnly := func(h http.HandlerFunc) http.Handler {
+		return authMiddleware(adminMiddleware(h))
+	}
 
 	mux := http.NewServeMux()
 
@@ -239,24 +313,56 @@ func main() {
 
 	// Protected routes
 	mux.Handle("GET /api/v1/users/me", authMiddleware(http.HandlerFunc(userHandler.Me)))
+	mux.Handle("POST /api/v1/users/{userID}/block", authMiddleware(http.HandlerFunc(bHandler.Block)))
+	mux.Handle("DELETE /api/v1/users/{userID}/block", authMiddleware(http.HandlerFunc(bHandler.Unblock)))
 
 	mux.Handle("POST /api/v1/profiles", authMiddleware(http.HandlerFunc(pHandler.Create)))
 	mux.Handle("PUT /api/v1/profiles", authMiddleware(http.HandlerFunc(pHandler.Update)))
 	mux.Handle("PUT /api/v1/profiles/me", authMiddleware(http.HandlerFunc(pHandler.Update)))
-	mux.Handle("POST /api/v1/profiles/upload", authMiddleware(http.HandlerFunc(pHandler.UploadPhoto)))
+	mux.Handle("PATCH /api/v1/profiles/me", authMiddleware(http.HandlerFunc(pHandler.Patch)))
+	mux.Handle("POST /api/v1/profiles/upload", authMiddleware(http.HandlerFunc(phHandler.UploadLegacy)))
+	mux.Handle("POST /api/v1/profiles/me/photos", authMiddleware(http.HandlerFunc(phHandler.Upload)))
+	mux.Handle("GET /api/v1/profiles/me/photos", authMiddleware(http.HandlerFunc(phHandler.List)))
+	mux.Handle("PUT /api/v1/profiles/me/photos/order", authMiddleware(http.HandlerFunc(phHandler.Reorder)))
+	mux.Handle("PUT /api/v1/profiles/me/photos/{photoID}/primary", authMiddleware(http.HandlerFunc(phHandler.SetPrimary)))
+	mux.Handle("DELETE /api/v1/profiles/me/photos/{photoID}", authMiddleware(http.HandlerFunc(phHandler.Delete)))
 	mux.Handle("GET /api/v1/profiles/search", authMiddleware(http.HandlerFunc(pHandler.Search)))
 	mux.Handle("GET /api/v1/profiles/me", authMiddleware(http.HandlerFunc(pHandler.GetMe)))
-	mux.HandleFunc("GET /api/v1/profiles/{userID}", pHandler.Get)
+	mux.Handle("PUT /api/v1/profiles/me/passport", authMiddleware(http.HandlerFunc(pHandler.SetPassport)))
+	mux.Handle("DELETE /api/v1/profiles/me/passport", authMiddleware(http.HandlerFunc(pHandler.ClearPassport)))
+	mux.Handle("GET /api/v1/profiles/me/revisions", authMiddleware(http.HandlerFunc(pHandler.Revisions)))
+	mux.Handle("POST /api/v1/profiles/me/revisions/{version}/restore", authMiddleware(http.HandlerFunc(pHandler.RestoreRevision)))
+	mux.Handle("GET /api/v1/profiles/me/viewers", authMiddleware(http.HandlerFunc(pHandler.Viewers)))
+	mux.Handle("GET /api/v1/profiles/{userID}", authMiddleware(http.HandlerFunc(pHandler.Get)))
+
+	mux.Handle("POST /api/v1/verification/challenge", authMiddleware(http.HandlerFunc(vHandler.StartChallenge)))
+	mux.Handle("POST /api/v1/verification/selfie", authMiddleware(http.HandlerFunc(vHandler.SubmitSelfie)))
+	mux.Handle("GET /api/v1/verification", authMiddleware(http.HandlerFunc(vHandler.GetStatus)))
 
 	mux.Handle("POST /api/v1/likes", authMiddleware(http.HandlerFunc(lHandler.Like)))
+	mux.Handle("GET /api/v1/likes/received", authMiddleware(http.HandlerFunc(lHandler.ReceivedLikes)))
+	mux.Handle("POST /api/v1/likes/rewind", authMiddleware(http.HandlerFunc(lHandler.Rewind)))
 	mux.Handle("GET /api/v1/matches", authMiddleware(http.HandlerFunc(lHandler.GetMatches)))
+	mux.Handle("DELETE /api/v1/matches/{userID}", authMiddleware(http.HandlerFunc(lHandler.Unmatch)))
 
 	mux.Handle("GET /api/v1/notifications", authMiddleware(http.HandlerFunc(nHandler.List)))
 
 	mux.Handle("POST /api/v1/reviews", authMiddleware(http.HandlerFunc(rHandler.Create)))
-	mux.HandleFunc("GET /api/v1/users/{userID}/reviews", rHandler.List)
+	mux.Handle("GET /api/v1/users/{userID}/reviews", authMiddleware(http.HandlerFunc(rHandler.List)))
 
 	mux.Handle("GET /api/v1/chats/{otherUserID}/messages", authMiddleware(http.HandlerFunc(cHandler.GetHistory)))
+	mux.Handle("POST /api/v1/chats/{otherUserID}/read", authMiddleware(http.HandlerFunc(cHandler.MarkRead)))
+
+	// Admin / support routes
+	mux.Handle("PUT /api/v1/admin/profiles/{userID}/birth-date", adminOnly(pHandler.SetBirthDate))
+	mux.Handle("GET /api/v1/admin/profiles/{userID}/revisions", adminOnly(pHandler.AdminRevisions))
+	mux.Handle("POST /api/v1/admin/profiles/{userID}/revisions/{version}/restore", adminOnly(pHandler.AdminRestoreRevision))
+	mux.Handle("GET /api/v1/admin/photos/moderation", adminOnly(phHandler.ModerationQueue))
+	mux.Handle("POST /api/v1/admin/photos/{photoID}/approve", adminOnly(phHandler.Approve))
+	mux.Handle("POST /api/v1/admin/photos/{photoID}/reject", adminOnly(phHandler.Reject))
+	mux.Handle("GET /api/v1/admin/verifications", adminOnly(vHandler.ReviewQueue))
+	mux.Handle("POST /api/v1/admin/verifications/{verificationID}/approve", adminOnly(vHandler.Approve))
+	mux.Handle("POST /api/v1/admin/verifications/{verificationID}/reject", adminOnly(vHandler.Reject))
 
 	// Ticket generation
 	mux.Handle("POST /api/v1/chat/ticket", authMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
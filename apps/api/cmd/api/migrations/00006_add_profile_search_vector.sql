@@ -0,0 +1,42 @@
+-- +goose Up
+-- =================================================================
+-- Full-text search over profile content.
+-- The vector is maintained by a trigger so every write path keeps it
+-- in sync without the application having to know about it.
+-- =================================================================
+ALTER TABLE
+    profiles
+ADD
+    COLUMN search_vector TSVECTOR;
+
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION profiles_search_vector_update() RETURNS TRIGGER AS $$
+BEGIN
+    NEW.search_vector :=
+        setweight(to_tsvector('english', COALESCE(NEW.bio, '')), 'A') ||
+        setweight(to_tsvector('english', COALESCE(array_to_string(NEW.self_described_strengths, ' '), '')), 'B') ||
+        setweight(to_tsvector('english', COALESCE(array_to_string(NEW.self_described_flaws, ' '), '')), 'C');
+    RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+CREATE TRIGGER trg_profiles_search_vector
+    BEFORE INSERT OR UPDATE OF bio, self_described_strengths, self_described_flaws
+    ON profiles
+    FOR EACH ROW EXECUTE FUNCTION profiles_search_vector_update();
+
+-- Backfill existing rows by touching the indexed columns.
+UPDATE profiles SET bio = bio;
+
+CREATE INDEX idx_profiles_search_vector ON profiles USING GIN (search_vector);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_profiles_search_vector;
+
+DROP TRIGGER IF EXISTS trg_profiles_search_vector ON profiles;
+
+DROP FUNCTION IF EXISTS profiles_search_vector_update();
+
+ALTER TABLE
+    profiles DROP COLUMN search_vector;
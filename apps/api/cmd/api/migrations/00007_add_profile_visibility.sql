@@ -0,0 +1,19 @@
+-- +goose Up
+-- =================================================================
+-- Profile visibility
+-- visible   - shown in discovery to everyone
+-- paused    - hidden from discovery; matches and chats keep working
+-- incognito - only shown to users the profile owner has liked
+-- =================================================================
+ALTER TABLE
+    profiles
+ADD
+    COLUMN visibility VARCHAR(20) NOT NULL DEFAULT 'visible' CHECK (visibility IN ('visible', 'paused', 'incognito'));
+
+CREATE INDEX idx_profiles_visibility ON profiles(visibility);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_profiles_visibility;
+
+ALTER TABLE
+    profiles DROP COLUMN visibility;
@@ -0,0 +1,33 @@
+-- +goose Up
+-- =================================================================
+-- Location privacy
+-- Exact coordinates are only ever read back by their owner. Discovery
+-- (radius search and distance buckets) runs against a jittered copy
+-- so repeated queries cannot trilaterate a user's real position.
+-- =================================================================
+ALTER TABLE
+    profiles
+ADD
+    COLUMN fuzzed_latitude DOUBLE PRECISION;
+
+ALTER TABLE
+    profiles
+ADD
+    COLUMN fuzzed_longitude DOUBLE PRECISION;
+
+-- Backfill: roughly +/- 1 km of noise, snapped to two decimal places.
+UPDATE
+    profiles
+SET
+    fuzzed_latitude = ROUND((latitude + (random() - 0.5) * 0.02) :: NUMERIC, 2),
+    fuzzed_longitude = ROUND((longitude + (random() - 0.5) * 0.02) :: NUMERIC, 2)
+WHERE
+    latitude IS NOT NULL
+    AND longitude IS NOT NULL;
+
+-- +goose Down
+ALTER TABLE
+    profiles DROP COLUMN fuzzed_latitude;
+
+ALTER TABLE
+    profiles DROP COLUMN fuzzed_longitude;
@@ -0,0 +1,43 @@
+-- +goose Up
+-- =================================================================
+-- Profile Photos
+-- Source of truth for a user's photos. profiles.photos is kept as a
+-- denormalised, ordered list of ready photo keys for cheap reads.
+-- =================================================================
+CREATE TABLE IF NOT EXISTS profile_photos (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    storage_key TEXT NOT NULL,
+    position INT NOT NULL DEFAULT 0,
+    is_primary BOOLEAN NOT NULL DEFAULT FALSE,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'ready', 'failed')),
+    width INT,
+    height INT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_profile_photos_user_id ON profile_photos(user_id, position);
+
+-- At most one primary photo per user.
+CREATE UNIQUE INDEX IF NOT EXISTS idx_profile_photos_primary ON profile_photos(user_id)
+WHERE
+    is_primary;
+
+-- Carry over photos stored inline on profiles.
+INSERT INTO
+    profile_photos (user_id, storage_key, position, is_primary, status)
+SELECT
+    p.user_id,
+    photo.key,
+    (photo.ord - 1) :: INT,
+    photo.ord = 1,
+    'ready'
+FROM
+    profiles p
+    CROSS JOIN LATERAL unnest(p.photos) WITH ORDINALITY AS photo(key, ord)
+WHERE
+    p.photos IS NOT NULL;
+
+-- +goose Down
+DROP TABLE IF EXISTS profile_photos;
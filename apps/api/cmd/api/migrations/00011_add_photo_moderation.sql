@@ -0,0 +1,34 @@
+-- +goose Up
+-- =================================================================
+-- Photo Moderation
+-- Only approved photos are synced to profiles.photos and shown to
+-- other users. Photos that were already live are grandfathered in.
+-- =================================================================
+ALTER TABLE profile_photos
+ADD COLUMN IF NOT EXISTS moderation_status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (
+        moderation_status IN ('pending', 'approved', 'rejected')
+    ),
+ADD COLUMN IF NOT EXISTS moderation_reason TEXT,
+ADD COLUMN IF NOT EXISTS moderated_by UUID REFERENCES users(id) ON DELETE SET NULL,
+ADD COLUMN IF NOT EXISTS moderated_at TIMESTAMPTZ;
+
+UPDATE profile_photos
+SET
+    moderation_status = 'approved',
+    moderated_at = NOW()
+WHERE
+    status = 'ready';
+
+CREATE INDEX IF NOT EXISTS idx_profile_photos_moderation_queue ON profile_photos(created_at)
+WHERE
+    status = 'ready'
+    AND moderation_status = 'pending';
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_profile_photos_moderation_queue;
+
+ALTER TABLE profile_photos
+DROP COLUMN IF EXISTS moderated_at,
+DROP COLUMN IF EXISTS moderated_by,
+DROP COLUMN IF EXISTS moderation_reason,
+DROP COLUMN IF EXISTS moderation_status;
@@ -0,0 +1,37 @@
+-- +goose Up
+-- =================================================================
+-- Selfie Verification
+-- Each row is one attempt: a pose challenge, the selfie submitted
+-- for it, and the decision. profiles.is_verified is set when an
+-- attempt is approved.
+-- =================================================================
+CREATE TABLE IF NOT EXISTS verifications (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    pose VARCHAR(50) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'challenged' CHECK (
+        status IN ('challenged', 'submitted', 'approved', 'rejected')
+    ),
+    selfie_key TEXT,
+    score DOUBLE PRECISION,
+    reason TEXT,
+    reviewed_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_verifications_user_id ON verifications(user_id, created_at DESC);
+
+CREATE INDEX IF NOT EXISTS idx_verifications_review_queue ON verifications(updated_at)
+WHERE
+    status = 'submitted';
+
+ALTER TABLE profiles
+ADD COLUMN IF NOT EXISTS is_verified BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- +goose Down
+ALTER TABLE profiles
+DROP COLUMN IF EXISTS is_verified;
+
+DROP TABLE IF EXISTS verifications;
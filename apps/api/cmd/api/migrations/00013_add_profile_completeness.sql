@@ -0,0 +1,42 @@
+-- +goose Up
+-- =================================================================
+-- Profile completeness, used as a discovery ranking signal.
+-- Like the search vector, the score is maintained by a trigger so
+-- photo syncs and profile edits keep it current. The weights must
+-- match profile.completenessWeights.
+-- =================================================================
+ALTER TABLE
+    profiles
+ADD
+    COLUMN completeness_score SMALLINT NOT NULL DEFAULT 0;
+
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION profiles_completeness_update() RETURNS TRIGGER AS $$
+BEGIN
+    NEW.completeness_score :=
+        (CASE WHEN COALESCE(cardinality(NEW.photos), 0) > 0 THEN 30 ELSE 0 END) +
+        (CASE WHEN btrim(COALESCE(NEW.bio, '')) <> '' THEN 20 ELSE 0 END) +
+        (CASE WHEN COALESCE(cardinality(NEW.self_described_strengths), 0) > 0
+               AND COALESCE(cardinality(NEW.self_described_flaws), 0) > 0 THEN 20 ELSE 0 END) +
+        (CASE WHEN NEW.birth_date IS NOT NULL THEN 15 ELSE 0 END) +
+        (CASE WHEN NEW.latitude IS NOT NULL AND NEW.longitude IS NOT NULL THEN 15 ELSE 0 END);
+    RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+CREATE TRIGGER trg_profiles_completeness
+    BEFORE INSERT OR UPDATE OF photos, bio, self_described_strengths, self_described_flaws, birth_date, latitude, longitude
+    ON profiles
+    FOR EACH ROW EXECUTE FUNCTION profiles_completeness_update();
+
+-- Backfill existing rows by touching a watched column.
+UPDATE profiles SET bio = bio;
+
+-- +goose Down
+DROP TRIGGER IF EXISTS trg_profiles_completeness ON profiles;
+
+DROP FUNCTION IF EXISTS profiles_completeness_update();
+
+ALTER TABLE
+    profiles DROP COLUMN completeness_score;
@@ -0,0 +1,14 @@
+-- +goose Up
+-- =================================================================
+-- Optimistic concurrency for profile edits.
+-- version is bumped by every application update and exposed to
+-- clients as the ETag of GET /profiles/me.
+-- =================================================================
+ALTER TABLE
+    profiles
+ADD
+    COLUMN version INTEGER NOT NULL DEFAULT 1;
+
+-- +goose Down
+ALTER TABLE
+    profiles DROP COLUMN version;
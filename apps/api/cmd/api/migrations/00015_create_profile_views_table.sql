@@ -0,0 +1,19 @@
+-- +goose Up
+-- =================================================================
+-- Profile Views
+-- A row means viewer_id opened viewed_id's profile on view_date.
+-- Repeat visits on the same day are not recorded again.
+-- =================================================================
+CREATE TABLE IF NOT EXISTS profile_views (
+    viewer_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    viewed_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    view_date DATE NOT NULL DEFAULT CURRENT_DATE,
+    viewed_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (viewer_id, viewed_id, view_date),
+    CHECK (viewer_id <> viewed_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_profile_views_viewed_id ON profile_views(viewed_id, viewed_at DESC);
+
+-- +goose Down
+DROP TABLE IF EXISTS profile_views;
@@ -0,0 +1,23 @@
+-- +goose Up
+-- =================================================================
+-- Travel mode ("passport").
+-- While passport_expires_at is in the future, searches by this user
+-- are centred on the passport location instead of latitude/longitude.
+-- =================================================================
+ALTER TABLE
+    profiles
+ADD
+    COLUMN passport_city VARCHAR(100),
+ADD
+    COLUMN passport_latitude DOUBLE PRECISION,
+ADD
+    COLUMN passport_longitude DOUBLE PRECISION,
+ADD
+    COLUMN passport_expires_at TIMESTAMPTZ;
+
+-- +goose Down
+ALTER TABLE
+    profiles DROP COLUMN passport_city,
+    DROP COLUMN passport_latitude,
+    DROP COLUMN passport_longitude,
+    DROP COLUMN passport_expires_at;
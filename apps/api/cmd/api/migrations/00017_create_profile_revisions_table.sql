@@ -0,0 +1,17 @@
+-- +goose Up
+-- =================================================================
+-- Profile Revisions
+-- Every profile update stores the content it replaced, keyed by the
+-- replaced version, so edits can be audited and rolled back.
+-- =================================================================
+CREATE TABLE IF NOT EXISTS profile_revisions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    version INTEGER NOT NULL,
+    snapshot JSONB NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (user_id, version)
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS profile_revisions;
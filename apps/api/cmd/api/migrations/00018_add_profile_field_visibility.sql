@@ -0,0 +1,15 @@
+-- +goose Up
+-- =================================================================
+-- Per-field privacy.
+-- Maps field names (height, gender, selfDescribedFlaws,
+-- selfDescribedStrengths) to everyone / matches / nobody.
+-- Missing keys mean everyone.
+-- =================================================================
+ALTER TABLE
+    profiles
+ADD
+    COLUMN field_visibility JSONB NOT NULL DEFAULT '{}';
+
+-- +goose Down
+ALTER TABLE
+    profiles DROP COLUMN field_visibility;
@@ -0,0 +1,52 @@
+-- +goose Up
+-- =================================================================
+-- Structured profile attributes.
+-- Languages are ISO 639-1 codes; the other attributes are enums
+-- validated by the service and constrained here.
+-- =================================================================
+ALTER TABLE
+    profiles
+ADD
+    COLUMN languages TEXT [] NOT NULL DEFAULT '{}',
+ADD
+    COLUMN education VARCHAR(20) CHECK (
+        education IN (
+            'high_school',
+            'trade_school',
+            'bachelors',
+            'masters',
+            'doctorate'
+        )
+    ),
+ADD
+    COLUMN occupation VARCHAR(100),
+ADD
+    COLUMN relationship_goal VARCHAR(20) CHECK (
+        relationship_goal IN (
+            'long_term',
+            'long_term_open',
+            'short_term',
+            'friendship',
+            'not_sure'
+        )
+    ),
+ADD
+    COLUMN smoking VARCHAR(20) CHECK (smoking IN ('never', 'socially', 'regularly')),
+ADD
+    COLUMN drinking VARCHAR(20) CHECK (drinking IN ('never', 'socially', 'regularly')),
+ADD
+    COLUMN kids VARCHAR(20) CHECK (kids IN ('have', 'want', 'dont_want', 'open'));
+
+CREATE INDEX idx_profiles_languages ON profiles USING GIN(languages);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_profiles_languages;
+
+ALTER TABLE
+    profiles DROP COLUMN languages,
+    DROP COLUMN education,
+    DROP COLUMN occupation,
+    DROP COLUMN relationship_goal,
+    DROP COLUMN smoking,
+    DROP COLUMN drinking,
+    DROP COLUMN kids;
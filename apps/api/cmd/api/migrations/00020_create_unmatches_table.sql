@@ -0,0 +1,18 @@
+-- +goose Up
+-- =================================================================
+-- Unmatches
+-- A row means user_id ended their match with other_user_id. The
+-- pair can never match again, whoever likes whom afterwards.
+-- =================================================================
+CREATE TABLE IF NOT EXISTS unmatches (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    other_user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, other_user_id),
+    CHECK (user_id <> other_user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_unmatches_other_user_id ON unmatches(other_user_id);
+
+-- +goose Down
+DROP TABLE IF EXISTS unmatches;
@@ -0,0 +1,50 @@
+-- +goose Up
+-- =================================================================
+-- Matches
+-- One row per matched pair, written when a mutual like is
+-- confirmed. user1_id is always the smaller ID so a pair has a
+-- single row. Unmatching deletes the row.
+-- =================================================================
+CREATE TABLE IF NOT EXISTS matches (
+    user1_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    user2_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    matched_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user1_id, user2_id),
+    CHECK (user1_id < user2_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_matches_user2_id ON matches(user2_id);
+
+-- Backfill existing mutual likes that have not been unmatched.
+INSERT INTO
+    matches (user1_id, user2_id, matched_at)
+SELECT
+    l1.from_user_id,
+    l1.to_user_id,
+    GREATEST(l1.created_at, l2.created_at)
+FROM
+    likes l1
+    JOIN likes l2 ON l1.to_user_id = l2.from_user_id
+    AND l1.from_user_id = l2.to_user_id
+WHERE
+    l1.from_user_id < l1.to_user_id
+    AND l1.is_like = TRUE
+    AND l2.is_like = TRUE
+    AND NOT EXISTS (
+        SELECT
+            1
+        FROM
+            unmatches u
+        WHERE
+            (
+                u.user_id = l1.from_user_id
+                AND u.other_user_id = l1.to_user_id
+            )
+            OR (
+                u.user_id = l1.to_user_id
+                AND u.other_user_id = l1.from_user_id
+            )
+    );
+
+-- +goose Down
+DROP TABLE IF EXISTS matches;
@@ -0,0 +1,15 @@
+-- +goose Up
+-- =================================================================
+-- Match list summaries look up the first and latest message of
+-- each conversation and count unread messages per sender.
+-- =================================================================
+CREATE INDEX IF NOT EXISTS idx_messages_conversation ON messages(sender_id, receiver_id, created_at);
+
+CREATE INDEX IF NOT EXISTS idx_messages_unread ON messages(receiver_id, sender_id)
+WHERE
+    is_read = FALSE;
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_messages_unread;
+
+DROP INDEX IF EXISTS idx_messages_conversation;
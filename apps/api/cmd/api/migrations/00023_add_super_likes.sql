@@ -0,0 +1,24 @@
+-- +goose Up
+-- =================================================================
+-- Super likes
+-- likes.kind tells a super like from a plain one. super_likes logs
+-- every super like sent, so the daily quota survives the like being
+-- changed or withdrawn later.
+-- =================================================================
+ALTER TABLE likes
+    ADD COLUMN IF NOT EXISTS kind VARCHAR(20) NOT NULL DEFAULT 'like'
+        CHECK (kind IN ('like', 'superlike'));
+
+CREATE TABLE IF NOT EXISTS super_likes (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    from_user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    to_user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CHECK (from_user_id <> to_user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_super_likes_from_user_created ON super_likes(from_user_id, created_at);
+
+-- +goose Down
+DROP TABLE IF EXISTS super_likes;
+ALTER TABLE likes DROP COLUMN IF EXISTS kind;
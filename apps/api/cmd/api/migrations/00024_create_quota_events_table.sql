@@ -0,0 +1,17 @@
+-- +goose Up
+-- =================================================================
+-- Quota events
+-- One row per use of a rate-limited action, keyed by action and
+-- user (e.g. "likes:<user id>"). Rows older than the limit's window
+-- are pruned when the key is next used.
+-- =================================================================
+CREATE TABLE IF NOT EXISTS quota_events (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    key TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_quota_events_key_created_at ON quota_events(key, created_at);
+
+-- +goose Down
+DROP TABLE IF EXISTS quota_events;
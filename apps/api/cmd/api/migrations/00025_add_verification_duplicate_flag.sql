@@ -0,0 +1,10 @@
+-- +goose Up
+-- =================================================================
+-- Verification duplicates
+-- Set when the selfie is a near copy of the reference profile photo
+-- rather than a new picture, so moderators look twice.
+-- =================================================================
+ALTER TABLE verifications ADD COLUMN IF NOT EXISTS duplicate BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- +goose Down
+ALTER TABLE verifications DROP COLUMN IF EXISTS duplicate;
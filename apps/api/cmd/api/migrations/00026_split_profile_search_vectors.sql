@@ -0,0 +1,51 @@
+-- +goose Up
+-- =================================================================
+-- Per-field search vectors
+-- Strengths and flaws can be hidden from other users, so they get
+-- their own vectors that searches only match where the owner shows
+-- the field. search_vector now covers the bio alone, which is always
+-- public. Weights are kept so the vectors can be concatenated for
+-- ranking.
+-- =================================================================
+ALTER TABLE profiles
+    ADD COLUMN IF NOT EXISTS strengths_vector TSVECTOR,
+    ADD COLUMN IF NOT EXISTS flaws_vector TSVECTOR;
+
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION profiles_search_vector_update() RETURNS TRIGGER AS $$
+BEGIN
+    NEW.search_vector := setweight(to_tsvector('english', COALESCE(NEW.bio, '')), 'A');
+    NEW.strengths_vector := setweight(to_tsvector('english', COALESCE(array_to_string(NEW.self_described_strengths, ' '), '')), 'B');
+    NEW.flaws_vector := setweight(to_tsvector('english', COALESCE(array_to_string(NEW.self_described_flaws, ' '), '')), 'C');
+    RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+-- Rebuild every row's vectors.
+UPDATE profiles SET bio = bio;
+
+CREATE INDEX IF NOT EXISTS idx_profiles_strengths_vector ON profiles USING GIN (strengths_vector);
+CREATE INDEX IF NOT EXISTS idx_profiles_flaws_vector ON profiles USING GIN (flaws_vector);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_profiles_flaws_vector;
+DROP INDEX IF EXISTS idx_profiles_strengths_vector;
+
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION profiles_search_vector_update() RETURNS TRIGGER AS $$
+BEGIN
+    NEW.search_vector :=
+        setweight(to_tsvector('english', COALESCE(NEW.bio, '')), 'A') ||
+        setweight(to_tsvector('english', COALESCE(array_to_string(NEW.self_described_strengths, ' '), '')), 'B') ||
+        setweight(to_tsvector('english', COALESCE(array_to_string(NEW.self_described_flaws, ' '), '')), 'C');
+    RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+UPDATE profiles SET bio = bio;
+
+ALTER TABLE profiles
+    DROP COLUMN IF EXISTS flaws_vector,
+    DROP COLUMN IF EXISTS strengths_vector;
@@ -0,0 +1,18 @@
+-- +goose Up
+-- =================================================================
+-- Previous swipe
+-- Swiping the same profile again overwrites its likes row. The
+-- replaced like or pass is kept alongside, so rewinding the new
+-- swipe brings the earlier one back instead of forgetting both.
+-- =================================================================
+ALTER TABLE likes
+    ADD COLUMN IF NOT EXISTS previous_is_like BOOLEAN,
+    ADD COLUMN IF NOT EXISTS previous_kind VARCHAR(20)
+        CHECK (previous_kind IN ('like', 'superlike')),
+    ADD COLUMN IF NOT EXISTS previous_created_at TIMESTAMPTZ;
+
+-- +goose Down
+ALTER TABLE likes
+    DROP COLUMN IF EXISTS previous_created_at,
+    DROP COLUMN IF EXISTS previous_kind,
+    DROP COLUMN IF EXISTS previous_is_like;
@@ -0,0 +1,11 @@
+package block
+
+import "time"
+
+// Block hides BlockerID from BlockedID: the blocker no longer shows up in the
+// blocked user's search results, profile views or received likes.
+type Block struct {
+	BlockerID string    `json:"blockerId" db:"blocker_id"`
+	BlockedID string    `json:"blockedId" db:"blocked_id"`
+	CreatedAt time.Time `json:"createdAt" db:"created_at"`
+}
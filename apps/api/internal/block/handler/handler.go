@@ -0,0 +1,86 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/kisssonik/hearts/internal/block/service"
+	"github.com/kisssonik/hearts/pkg/auth"
+	"go.uber.org/zap"
+)
+
+type BlockHandler struct {
+	service service.BlockService
+	logger  *zap.Logger
+}
+
+func NewBlockHandler(s service.BlockService, l *zap.Logger) *BlockHandler {
+	return &BlockHandler{service: s, logger: l}
+}
+
+// Block handles blocking a user.
+// @Summary Block a user
+// @Description Hide the authenticated user from another user: they no longer appear in that user's
+// @Description search results, profile views or received likes. Blocking an already blocked user succeeds.
+// @Tags users
+// @Security ApiKeyAuth
+// @Param userID path string true "User to block"
+// @Success 204 "Blocked"
+// @Failure 400 {string} string "Cannot block yourself"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 404 {string} string "User not found"
+// @Failure 500 {string} string "Internal server error"
+// @Router /users/{userID}/block [post]
+func (h *BlockHandler) Block(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(auth.UserIDKey).(string)
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	if err := h.service.BlockUser(r.Context(), userID, r.PathValue("userID")); err != nil {
+		switch {
+		case errors.Is(err, service.ErrSelfBlock):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		case errors.Is(err, service.ErrUserNotFound):
+			http.Error(w, err.Error(), http.StatusNotFound)
+		default:
+			h.logger.Error("Failed to block user", zap.Error(err))
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Unblock handles lifting a block.
+// @Summary Unblock a user
+// @Description Lift a block the authenticated user placed on another user.
+// @Tags users
+// @Security ApiKeyAuth
+// @Param userID path string true "Blocked user"
+// @Success 204 "Unblocked"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 404 {string} string "User is not blocked"
+// @Failure 500 {string} string "Internal server error"
+// @Router /users/{userID}/block [delete]
+func (h *BlockHandler) Unblock(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(auth.UserIDKey).(string)
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	if err := h.service.UnblockUser(r.Context(), userID, r.PathValue("userID")); err != nil {
+		if errors.Is(err, service.ErrNotBlocked) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		h.logger.Error("Failed to unblock user", zap.Error(err))
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,102 @@
+package handler_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/kisssonik/hearts/internal/block/handler"
+	"github.com/kisssonik/hearts/internal/block/service"
+	"github.com/kisssonik/hearts/pkg/auth"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+)
+
+// MockBlockService
+type MockBlockService struct {
+	mock.Mock
+}
+
+func (m *MockBlockService) BlockUser(ctx context.Context, userID, targetID string) error {
+	args := m.Called(ctx, userID, targetID)
+	return args.Error(0)
+}
+
+func (m *MockBlockService) UnblockUser(ctx context.Context, userID, targetID string) error {
+	args := m.Called(ctx, userID, targetID)
+	return args.Error(0)
+}
+
+func TestBlockHandler_Block(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+	}{
+		{"blocked", nil, http.StatusNoContent},
+		{"self block", service.ErrSelfBlock, http.StatusBadRequest},
+		{"unknown user", service.ErrUserNotFound, http.StatusNotFound},
+		{"database error", errors.New("db down"), http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockBlockService)
+			h := handler.NewBlockHandler(mockService, zap.NewNop())
+
+			req := httptest.NewRequest("POST", "/users/u2/block", nil)
+			req.SetPathValue("userID", "u2")
+			req = req.WithContext(context.WithValue(req.Context(), auth.UserIDKey, "u1"))
+			w := httptest.NewRecorder()
+
+			mockService.On("BlockUser", mock.Anything, "u1", "u2").Return(tt.err)
+
+			h.Block(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestBlockHandler_Unblock(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+	}{
+		{"unblocked", nil, http.StatusNoContent},
+		{"not blocked", service.ErrNotBlocked, http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockBlockService)
+			h := handler.NewBlockHandler(mockService, zap.NewNop())
+
+			req := httptest.NewRequest("DELETE", "/users/u2/block", nil)
+			req.SetPathValue("userID", "u2")
+			req = req.WithContext(context.WithValue(req.Context(), auth.UserIDKey, "u1"))
+			w := httptest.NewRecorder()
+
+			mockService.On("UnblockUser", mock.Anything, "u1", "u2").Return(tt.err)
+
+			h.Unblock(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestBlockHandler_Unauthorized(t *testing.T) {
+	h := handler.NewBlockHandler(new(MockBlockService), zap.NewNop())
+
+	w := httptest.NewRecorder()
+	h.Block(w, httptest.NewRequest("POST", "/users/u2/block", nil))
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
@@ -0,0 +1,57 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var (
+	ErrNotFound     = errors.New("block not found")
+	ErrUserNotFound = errors.New("user not found")
+)
+
+type BlockRepository interface {
+	// Block records that blockerID blocks blockedID. Blocking someone who
+	// is already blocked is a no-op.
+	Block(ctx context.Context, blockerID, blockedID string) error
+	// Unblock removes the block, or returns ErrNotFound if there is none.
+	Unblock(ctx context.Context, blockerID, blockedID string) error
+}
+
+type pgxBlockRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewBlockRepository(db *pgxpool.Pool) BlockRepository {
+	return &pgxBlockRepository{db: db}
+}
+
+func (r *pgxBlockRepository) Block(ctx context.Context, blockerID, blockedID string) error {
+	_, err := r.db.Exec(ctx, `
+		INSERT INTO user_blocks (blocker_id, blocked_id)
+		VALUES ($1, $2)
+		ON CONFLICT (blocker_id, blocked_id) DO NOTHING
+	`, blockerID, blockedID)
+	var pgErr *pgconn.PgError
+	// A foreign key violation means the blocked user does not exist.
+	if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+		return ErrUserNotFound
+	}
+	return err
+}
+
+func (r *pgxBlockRepository) Unblock(ctx context.Context, blockerID, blockedID string) error {
+	tag, err := r.db.Exec(ctx, `
+		DELETE FROM user_blocks WHERE blocker_id = $1 AND blocked_id = $2
+	`, blockerID, blockedID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/kisssonik/hearts/internal/block/repository"
+)
+
+var (
+	ErrSelfBlock    = errors.New("cannot block yourself")
+	ErrUserNotFound = errors.New("user not found")
+	ErrNotBlocked   = errors.New("user is not blocked")
+)
+
+type BlockService interface {
+	BlockUser(ctx context.Context, userID, targetID string) error
+	UnblockUser(ctx context.Context, userID, targetID string) error
+}
+
+type blockService struct {
+	repo repository.BlockRepository
+}
+
+func NewBlockService(repo repository.BlockRepository) BlockService {
+	return &blockService{repo: repo}
+}
+
+// BlockUser hides userID from targetID. Blocking twice is not an error.
+func (s *blockService) BlockUser(ctx context.Context, userID, targetID string) error {
+	if userID == targetID {
+		return ErrSelfBlock
+	}
+	if err := s.repo.Block(ctx, userID, targetID); err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			return ErrUserNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+// UnblockUser lifts a block userID placed on targetID.
+func (s *blockService) UnblockUser(ctx context.Context, userID, targetID string) error {
+	if err := s.repo.Unblock(ctx, userID, targetID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrNotBlocked
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/kisssonik/hearts/internal/block/repository"
+	"github.com/kisssonik/hearts/internal/block/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockBlockRepository
+type MockBlockRepository struct {
+	mock.Mock
+}
+
+func (m *MockBlockRepository) Block(ctx context.Context, blockerID, blockedID string) error {
+	args := m.Called(ctx, blockerID, blockedID)
+	return args.Error(0)
+}
+
+func (m *MockBlockRepository) Unblock(ctx context.Context, blockerID, blockedID string) error {
+	args := m.Called(ctx, blockerID, blockedID)
+	return args.Error(0)
+}
+
+func TestBlockService_BlockUser(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Success", func(t *testing.T) {
+		mockRepo := new(MockBlockRepository)
+		s := service.NewBlockService(mockRepo)
+		mockRepo.On("Block", ctx, "u1", "u2").Return(nil)
+
+		assert.NoError(t, s.BlockUser(ctx, "u1", "u2"))
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Self block", func(t *testing.T) {
+		mockRepo := new(MockBlockRepository)
+		s := service.NewBlockService(mockRepo)
+
+		assert.ErrorIs(t, s.BlockUser(ctx, "u1", "u1"), service.ErrSelfBlock)
+		mockRepo.AssertNotCalled(t, "Block", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Unknown user", func(t *testing.T) {
+		mockRepo := new(MockBlockRepository)
+		s := service.NewBlockService(mockRepo)
+		mockRepo.On("Block", ctx, "u1", "ghost").Return(repository.ErrUserNotFound)
+
+		assert.ErrorIs(t, s.BlockUser(ctx, "u1", "ghost"), service.ErrUserNotFound)
+	})
+}
+
+func TestBlockService_UnblockUser(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		name    string
+		repoErr error
+		wantErr error
+	}{
+		{"Success", nil, nil},
+		{"Not blocked", repository.ErrNotFound, service.ErrNotBlocked},
+		{"Database error", errors.New("db down"), errors.New("db down")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockBlockRepository)
+			s := service.NewBlockService(mockRepo)
+			mockRepo.On("Unblock", ctx, "u1", "u2").Return(tt.repoErr)
+
+			err := s.UnblockUser(ctx, "u1", "u2")
+			if tt.wantErr == nil {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, tt.wantErr.Error())
+		})
+	}
+}
@@ -2,7 +2,10 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
+	"io"
 	"net/http"
+	"time"
 
 	"github.com/kisssonik/hearts/internal/chat/service"
 	"github.com/kisssonik/hearts/pkg/auth"
@@ -20,7 +23,8 @@ func NewChatHandler(s service.ChatService, l *zap.Logger) *ChatHandler {
 
 // GetHistory handles retrieving chat history.
 // @Summary Get chat history
-// @Description Get messages between the authenticated user and another user.
+// @Description Get messages between the authenticated user and another user. Reading the history does not mark
+// @Description messages as read; use POST /chats/{otherUserID}/read for that.
 // @Tags chat
 // @Produce json
 // @Security ApiKeyAuth
@@ -58,3 +62,52 @@ func (h *ChatHandler) GetHistory(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(messages)
 }
+
+// MarkRead handles marking a conversation as read.
+// @Summary Mark chat messages as read
+// @Description Mark the messages the other user sent up to readUpTo as read. Without a body every received message is marked read.
+// @Tags chat
+// @Accept json
+// @Security ApiKeyAuth
+// @Param otherUserID path string true "Other User ID"
+// @Param input body service.MarkReadInput false "Read cursor"
+// @Success 204 "Marked as read"
+// @Failure 400 {string} string "Invalid request body"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Not matched"
+// @Failure 500 {string} string "Internal server error"
+// @Router /chats/{otherUserID}/read [post]
+func (h *ChatHandler) MarkRead(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(auth.UserIDKey).(string)
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	otherUserID := r.PathValue("otherUserID")
+	if otherUserID == "" {
+		http.Error(w, "Other User ID required", http.StatusBadRequest)
+		return
+	}
+
+	var input service.MarkReadInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil && !errors.Is(err, io.EOF) {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	var upTo time.Time
+	if input.ReadUpTo != nil {
+		upTo = *input.ReadUpTo
+	}
+
+	if err := h.service.MarkRead(r.Context(), userID, otherUserID, upTo); err != nil {
+		if errors.Is(err, service.ErrNotMatched) {
+			http.Error(w, "Not matched", http.StatusForbidden)
+			return
+		}
+		h.logger.Error("Failed to mark chat as read", zap.Error(err))
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,107 @@
+package handler_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/kisssonik/hearts/internal/chat"
+	"github.com/kisssonik/hearts/internal/chat/handler"
+	"github.com/kisssonik/hearts/internal/chat/service"
+	"github.com/kisssonik/hearts/pkg/auth"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+)
+
+// MockChatService
+type MockChatService struct {
+	mock.Mock
+}
+
+func (m *MockChatService) SendMessage(ctx context.Context, senderID, receiverID, content string) (*chat.Message, error) {
+	args := m.Called(ctx, senderID, receiverID, content)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*chat.Message), args.Error(1)
+}
+
+func (m *MockChatService) GetHistory(ctx context.Context, userID, otherUserID string) ([]*chat.Message, error) {
+	args := m.Called(ctx, userID, otherUserID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*chat.Message), args.Error(1)
+}
+
+func (m *MockChatService) MarkRead(ctx context.Context, userID, otherUserID string, upTo time.Time) error {
+	args := m.Called(ctx, userID, otherUserID, upTo)
+	return args.Error(0)
+}
+
+func TestChatHandler_GetHistory_DoesNotMarkRead(t *testing.T) {
+	mockService := new(MockChatService)
+	h := handler.NewChatHandler(mockService, zap.NewNop())
+
+	req := httptest.NewRequest("GET", "/chats/u2/messages", nil)
+	req.SetPathValue("otherUserID", "u2")
+	req = req.WithContext(context.WithValue(req.Context(), auth.UserIDKey, "u1"))
+	w := httptest.NewRecorder()
+
+	mockService.On("GetHistory", mock.Anything, "u1", "u2").Return([]*chat.Message{{ID: "m1", SenderID: "u2", ReceiverID: "u1"}}, nil)
+
+	h.GetHistory(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockService.AssertExpectations(t)
+	mockService.AssertNotCalled(t, "MarkRead", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestChatHandler_MarkRead(t *testing.T) {
+	cursor := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		body       io.Reader
+		upTo       time.Time // Passed to the service
+		err        error
+		wantStatus int
+	}{
+		{"no body", nil, time.Time{}, nil, http.StatusNoContent},
+		{"read cursor", bytes.NewBufferString(`{"readUpTo": "2026-10-19T12:00:00Z"}`), cursor, nil, http.StatusNoContent},
+		{"invalid body", bytes.NewBufferString(`{"readUpTo": "yesterday"}`), time.Time{}, nil, http.StatusBadRequest},
+		{"not matched", nil, time.Time{}, service.ErrNotMatched, http.StatusForbidden},
+		{"database error", nil, time.Time{}, errors.New("db down"), http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockChatService)
+			h := handler.NewChatHandler(mockService, zap.NewNop())
+
+			req := httptest.NewRequest("POST", "/chats/u2/read", tt.body)
+			req.SetPathValue("otherUserID", "u2")
+			req = req.WithContext(context.WithValue(req.Context(), auth.UserIDKey, "u1"))
+			w := httptest.NewRecorder()
+
+			mockService.On("MarkRead", mock.Anything, "u1", "u2", mock.MatchedBy(func(upTo time.Time) bool {
+				return upTo.Equal(tt.upTo)
+			})).Return(tt.err)
+
+			h.MarkRead(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			if tt.wantStatus == http.StatusBadRequest {
+				mockService.AssertNotCalled(t, "MarkRead", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+			} else {
+				mockService.AssertExpectations(t)
+			}
+		})
+	}
+}
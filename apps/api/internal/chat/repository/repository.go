@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/kisssonik/hearts/internal/chat"
@@ -10,6 +11,7 @@ import (
 type ChatRepository interface {
 	CreateMessage(ctx context.Context, msg *chat.Message) error
 	GetMessages(ctx context.Context, user1ID, user2ID string) ([]*chat.Message, error)
+	MarkRead(ctx context.Context, receiverID, senderID string, upTo time.Time) error
 }
 
 type pgxChatRepository struct {
@@ -56,3 +58,14 @@ func (r *pgxChatRepository) GetMessages(ctx context.Context, user1ID, user2ID st
 	}
 	return messages, nil
 }
+
+// MarkRead marks the messages senderID sent to receiverID up to and
+// including upTo as read.
+func (r *pgxChatRepository) MarkRead(ctx context.Context, receiverID, senderID string, upTo time.Time) error {
+	query := `
+		UPDATE messages SET is_read = TRUE
+		WHERE receiver_id = $1 AND sender_id = $2 AND is_read = FALSE AND created_at <= $3
+	`
+	_, err := r.db.Exec(ctx, query, receiverID, senderID, upTo)
+	return err
+}
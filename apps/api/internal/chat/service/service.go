@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"time"
 
 	"github.com/kisssonik/hearts/internal/chat"
 	"github.com/kisssonik/hearts/internal/chat/repository"
@@ -16,6 +17,14 @@ var ErrNotMatched = errors.New("users are not matched")
 type ChatService interface {
 	SendMessage(ctx context.Context, senderID, receiverID, content string) (*chat.Message, error)
 	GetHistory(ctx context.Context, userID, otherUserID string) ([]*chat.Message, error)
+	MarkRead(ctx context.Context, userID, otherUserID string, upTo time.Time) error
+}
+
+// MarkReadInput is an optional read cursor. Messages sent after ReadUpTo
+// stay unread, so a message that arrives while the conversation is open is
+// not marked read unseen.
+type MarkReadInput struct {
+	ReadUpTo *time.Time `json:"readUpTo,omitempty"`
 }
 
 type chatService struct {
@@ -30,7 +39,7 @@ func NewChatService(repo repository.ChatRepository, likeRepo likeRepo.LikeReposi
 
 func (s *chatService) SendMessage(ctx context.Context, senderID, receiverID, content string) (*chat.Message, error) {
 	// 1. Check if matched
-	isMatch, err := s.likeRepo.HasMutualLike(ctx, senderID, receiverID)
+	isMatch, err := s.likeRepo.IsMatched(ctx, senderID, receiverID)
 	if err != nil {
 		return nil, err
 	}
@@ -66,7 +75,7 @@ func (s *chatService) SendMessage(ctx context.Context, senderID, receiverID, con
 
 func (s *chatService) GetHistory(ctx context.Context, userID, otherUserID string) ([]*chat.Message, error) {
 	// Check match first? Maybe not strictly necessary for history, but good for privacy.
-	isMatch, err := s.likeRepo.HasMutualLike(ctx, userID, otherUserID)
+	isMatch, err := s.likeRepo.IsMatched(ctx, userID, otherUserID)
 	if err != nil {
 		return nil, err
 	}
@@ -76,3 +85,21 @@ func (s *chatService) GetHistory(ctx context.Context, userID, otherUserID string
 
 	return s.repo.GetMessages(ctx, userID, otherUserID)
 }
+
+// MarkRead marks the messages otherUserID sent to userID up to upTo as read.
+// A zero upTo reads everything received so far. Messages that arrive after
+// the client's cursor stay unread.
+func (s *chatService) MarkRead(ctx context.Context, userID, otherUserID string, upTo time.Time) error {
+	isMatch, err := s.likeRepo.IsMatched(ctx, userID, otherUserID)
+	if err != nil {
+		return err
+	}
+	if !isMatch {
+		return ErrNotMatched
+	}
+
+	if upTo.IsZero() {
+		upTo = time.Now()
+	}
+	return s.repo.MarkRead(ctx, userID, otherUserID, upTo)
+}
@@ -1,19 +1,28 @@
 package handler
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/kisssonik/hearts/internal/like/service"
+	"github.com/kisssonik/hearts/internal/photo"
 	"github.com/kisssonik/hearts/internal/profile"
 	"github.com/kisssonik/hearts/pkg/auth"
 	"github.com/kisssonik/hearts/pkg/storage"
 	"go.uber.org/zap"
 )
 
-// LikeResponse represents the response for a like action.
+// LikeResponse represents the response for a like action. Remaining and
+// ResetAt describe the like quota and are left out for passes.
 type LikeResponse struct {
-	IsMatch bool `json:"isMatch"`
+	IsMatch   bool       `json:"isMatch"`
+	Remaining *int       `json:"remaining,omitempty"`
+	ResetAt   *time.Time `json:"resetAt,omitempty"`
 }
 
 type LikeHandler struct {
@@ -29,14 +38,17 @@ func NewLikeHandler(s service.LikeService, storage storage.Provider, l *zap.Logg
 // Like handles liking or passing a user.
 // @Summary Like or pass a user
 // @Description Like or pass a user. Returns isMatch=true if it's a mutual like.
+// @Description Set kind to "superlike" to notify the user straight away; super likes are limited per day.
+// @Description Likes are limited over a rolling window: remaining and resetAt report what is left.
 // @Tags likes
 // @Accept json
 // @Produce json
 // @Security ApiKeyAuth
 // @Param input body service.LikeInput true "Like input"
 // @Success 200 {object} LikeResponse
-// @Failure 400 {string} string "Invalid request body or self-like"
+// @Failure 400 {string} string "Invalid request body, kind or self-like"
 // @Failure 401 {string} string "Unauthorized"
+// @Failure 429 {string} string "Like or daily super like limit reached; Retry-After gives the wait in seconds"
 // @Failure 500 {string} string "Internal server error"
 // @Router /likes [post]
 func (h *LikeHandler) Like(w http.ResponseWriter, r *http.Request) {
@@ -53,12 +65,26 @@ func (h *LikeHandler) Like(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	isMatch, err := h.service.LikeUser(r.Context(), userID, input)
+	result, err := h.service.LikeUser(r.Context(), userID, input)
 	if err != nil {
-		if err == service.ErrSelfLike {
+		if errors.Is(err, service.ErrLikeLimitReached) {
+			if result != nil && result.Quota != nil {
+				setRetryAfter(w, result.Quota.ResetAt)
+			}
+			http.Error(w, err.Error(), http.StatusTooManyRequests)
+			return
+		}
+		if err == service.ErrSelfLike || errors.Is(err, service.ErrInvalidKind) {
 			http.Error(w, err.Error(), http.StatusBadRequest)
 			return
 		}
+		if errors.Is(err, service.ErrSuperLikeLimitReached) {
+			if result != nil && result.SuperLikeResetAt != nil {
+				setRetryAfter(w, *result.SuperLikeResetAt)
+			}
+			http.Error(w, err.Error(), http.StatusTooManyRequests)
+			return
+		}
 		h.logger.Error("Failed to process like", zap.Error(err))
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 		return
@@ -66,18 +92,24 @@ func (h *LikeHandler) Like(w http.ResponseWriter, r *http.Request) {
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(LikeResponse{
-		IsMatch: isMatch,
-	})
+	resp := LikeResponse{IsMatch: result.IsMatch}
+	if result.Quota != nil {
+		resp.Remaining, resp.ResetAt = &result.Quota.Remaining, &result.Quota.ResetAt
+	}
+	json.NewEncoder(w).Encode(resp)
 }
 
 // GetMatches handles retrieving matches.
 // @Summary Get matches
-// @Description Get a list of profiles that the user has matched with.
+// @Description Get the user's matches with a preview of each conversation, most recently active first.
+// @Description Pass nextCursor back as cursor to get the next page.
 // @Tags likes
 // @Produce json
 // @Security ApiKeyAuth
-// @Success 200 {array} profile.Profile
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Param cursor query string false "nextCursor from the previous page"
+// @Success 200 {object} service.MatchPage
+// @Failure 400 {string} string "Invalid cursor"
 // @Failure 401 {string} string "Unauthorized"
 // @Failure 500 {string} string "Internal server error"
 // @Router /matches [get]
@@ -88,29 +120,169 @@ func (h *LikeHandler) GetMatches(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	matches, err := h.service.GetMatches(r.Context(), userID)
+	var limit int
+	query := r.URL.Query()
+	if limitStr := query.Get("limit"); limitStr != "" {
+		fmt.Sscanf(limitStr, "%d", &limit)
+	}
+
+	page, err := h.service.GetMatches(r.Context(), userID, query.Get("cursor"), limit)
 	if err != nil {
+		if errors.Is(err, service.ErrInvalidCursor) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
 		h.logger.Error("Failed to get matches", zap.Error(err))
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 		return
 	}
 
-	// Enrich matches with presigned URLs
-	for _, p := range matches {
-		for i, photoKey := range p.Photos {
-			url, err := h.storage.GetPresignedURL(r.Context(), photoKey)
-			if err != nil {
-				h.logger.Error("Failed to generate presigned URL", zap.String("key", photoKey), zap.Error(err))
-				continue
-			}
-			p.Photos[i] = url
+	for _, m := range page.Matches {
+		h.enrichPhotos(r.Context(), m.Profile)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(page)
+}
+
+// Unmatch handles ending a match.
+// @Summary Unmatch a user
+// @Description End a match. The conversation is removed for both users, open chats are closed
+// @Description with an "unmatch" WebSocket event, and the two users can never match again.
+// @Tags likes
+// @Security ApiKeyAuth
+// @Param userID path string true "Matched user ID"
+// @Success 204 "Unmatched"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 404 {string} string "Not matched"
+// @Failure 500 {string} string "Internal server error"
+// @Router /matches/{userID} [delete]
+func (h *LikeHandler) Unmatch(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(auth.UserIDKey).(string)
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	if err := h.service.Unmatch(r.Context(), userID, r.PathValue("userID")); err != nil {
+		if errors.Is(err, service.ErrNotMatched) {
+			http.Error(w, "Not matched", http.StatusNotFound)
+			return
+		}
+		h.logger.Error("Failed to unmatch", zap.Error(err))
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Rewind handles undoing the latest swipe.
+// @Summary Rewind the last like or pass
+// @Description Undo the authenticated user's most recent like or pass if it was made within the rewind window
+// @Description and has not produced a match. A swipe that replaced an earlier one for the same profile reverts to it.
+// @Description Returns the profile so it can be shown in the feed again.
+// @Tags likes
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} service.RewindResult
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 404 {string} string "No recent like or pass to rewind"
+// @Failure 409 {string} string "The like produced a match"
+// @Failure 500 {string} string "Internal server error"
+// @Router /likes/rewind [post]
+func (h *LikeHandler) Rewind(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(auth.UserIDKey).(string)
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	result, err := h.service.Rewind(r.Context(), userID)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrNothingToRewind):
+			http.Error(w, err.Error(), http.StatusNotFound)
+		case errors.Is(err, service.ErrRewindMatched):
+			http.Error(w, err.Error(), http.StatusConflict)
+		default:
+			h.logger.Error("Failed to rewind", zap.Error(err))
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
 		}
+		return
+	}
+
+	if result.Profile != nil {
+		h.enrichPhotos(r.Context(), result.Profile)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+// ReceivedLikes handles listing likes the user has not answered.
+// @Summary Get received likes
+// @Description List users who liked the authenticated user and whom they have not liked or passed yet, newest first.
+// @Description Users without access get a locked preview: the total and like times, without profiles.
+// @Tags likes
+// @Produce json
+// @Security ApiKeyAuth
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Param offset query int false "Offset"
+// @Success 200 {object} service.ReceivedLikesPage
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 500 {string} string "Internal server error"
+// @Router /likes/received [get]
+func (h *LikeHandler) ReceivedLikes(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(auth.UserIDKey).(string)
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var limit, offset int
+	query := r.URL.Query()
+	if limitStr := query.Get("limit"); limitStr != "" {
+		fmt.Sscanf(limitStr, "%d", &limit)
+	}
+	if offsetStr := query.Get("offset"); offsetStr != "" {
+		fmt.Sscanf(offsetStr, "%d", &offset)
+	}
+
+	page, err := h.service.ListReceivedLikes(r.Context(), userID, limit, offset)
+	if err != nil {
+		h.logger.Error("Failed to list received likes", zap.Error(err))
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
 	}
 
-	if matches == nil {
-		matches = []*profile.Profile{}
+	for _, l := range page.Likes {
+		if l.Profile != nil {
+			h.enrichPhotos(r.Context(), l.Profile)
+		}
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(matches)
+	json.NewEncoder(w).Encode(page)
+}
+
+// setRetryAfter tells the client how many whole seconds to wait until
+// resetAt.
+func setRetryAfter(w http.ResponseWriter, resetAt time.Time) {
+	wait := max(time.Until(resetAt), 0)
+	w.Header().Set("Retry-After", strconv.Itoa(int((wait+time.Second-1)/time.Second)))
+}
+
+// enrichPhotos replaces p's photo keys with presigned URLs.
+func (h *LikeHandler) enrichPhotos(ctx context.Context, p *profile.PublicProfile) {
+	p.PhotoVariants = make([]*photo.Variants, len(p.Photos))
+	for i, photoKey := range p.Photos {
+		variants, err := photo.ResolveVariants(ctx, h.storage, photoKey)
+		if err != nil {
+			h.logger.Error("Failed to generate presigned URL", zap.String("key", photoKey), zap.Error(err))
+			continue
+		}
+		p.Photos[i] = variants.Full
+		p.PhotoVariants[i] = variants
+	}
 }
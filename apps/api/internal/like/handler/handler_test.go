@@ -7,12 +7,16 @@ import (
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
 	"testing"
+	"time"
 
+	"github.com/kisssonik/hearts/internal/like"
 	"github.com/kisssonik/hearts/internal/like/handler"
 	"github.com/kisssonik/hearts/internal/like/service"
 	"github.com/kisssonik/hearts/internal/profile"
 	"github.com/kisssonik/hearts/pkg/auth"
+	"github.com/kisssonik/hearts/pkg/quota"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"go.uber.org/zap"
@@ -23,17 +27,46 @@ type MockLikeService struct {
 	mock.Mock
 }
 
-func (m *MockLikeService) LikeUser(ctx context.Context, fromUserID string, input service.LikeInput) (bool, error) {
+func (m *MockLikeService) LikeUser(ctx context.Context, fromUserID string, input service.LikeInput) (*service.LikeResult, error) {
 	args := m.Called(ctx, fromUserID, input)
-	return args.Bool(0), args.Error(1)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*service.LikeResult), args.Error(1)
+}
+
+func (m *MockLikeService) GetMatches(ctx context.Context, userID, cursor string, limit int) (*service.MatchPage, error) {
+	args := m.Called(ctx, userID, cursor, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*service.MatchPage), args.Error(1)
+}
+
+func (m *MockLikeService) ProcessMatchCheck(ctx context.Context, fromUserID, targetID string) error {
+	args := m.Called(ctx, fromUserID, targetID)
+	return args.Error(0)
+}
+
+func (m *MockLikeService) Unmatch(ctx context.Context, userID, otherUserID string) error {
+	args := m.Called(ctx, userID, otherUserID)
+	return args.Error(0)
+}
+
+func (m *MockLikeService) ListReceivedLikes(ctx context.Context, userID string, limit, offset int) (*service.ReceivedLikesPage, error) {
+	args := m.Called(ctx, userID, limit, offset)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*service.ReceivedLikesPage), args.Error(1)
 }
 
-func (m *MockLikeService) GetMatches(ctx context.Context, userID string) ([]*profile.Profile, error) {
+func (m *MockLikeService) Rewind(ctx context.Context, userID string) (*service.RewindResult, error) {
 	args := m.Called(ctx, userID)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
-	return args.Get(0).([]*profile.Profile), args.Error(1)
+	return args.Get(0).(*service.RewindResult), args.Error(1)
 }
 
 // MockStorageProvider
@@ -51,6 +84,19 @@ func (m *MockStorageProvider) GetPresignedURL(ctx context.Context, key string) (
 	return args.String(0), args.Error(1)
 }
 
+func (m *MockStorageProvider) Download(ctx context.Context, key string) ([]byte, error) {
+	args := m.Called(ctx, key)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]byte), args.Error(1)
+}
+
+func (m *MockStorageProvider) Delete(ctx context.Context, key string) error {
+	args := m.Called(ctx, key)
+	return args.Error(0)
+}
+
 func TestLikeHandler_Like(t *testing.T) {
 	mockService := new(MockLikeService)
 	mockStorage := new(MockStorageProvider)
@@ -64,7 +110,11 @@ func TestLikeHandler_Like(t *testing.T) {
 	req = req.WithContext(context.WithValue(req.Context(), auth.UserIDKey, "u1"))
 	w := httptest.NewRecorder()
 
-	mockService.On("LikeUser", mock.Anything, "u1", input).Return(true, nil)
+	resetAt := time.Date(2024, 5, 2, 12, 0, 0, 0, time.UTC)
+	mockService.On("LikeUser", mock.Anything, "u1", input).Return(&service.LikeResult{
+		IsMatch: true,
+		Quota:   &quota.Usage{Remaining: 41, ResetAt: resetAt},
+	}, nil)
 
 	h.Like(w, req)
 
@@ -72,6 +122,83 @@ func TestLikeHandler_Like(t *testing.T) {
 	var resp handler.LikeResponse
 	json.NewDecoder(w.Body).Decode(&resp)
 	assert.True(t, resp.IsMatch)
+	if assert.NotNil(t, resp.Remaining) && assert.NotNil(t, resp.ResetAt) {
+		assert.Equal(t, 41, *resp.Remaining)
+		assert.True(t, resetAt.Equal(*resp.ResetAt))
+	}
+}
+
+func TestLikeHandler_Like_LimitReached(t *testing.T) {
+	mockService := new(MockLikeService)
+	h := handler.NewLikeHandler(mockService, new(MockStorageProvider), zap.NewNop())
+
+	input := service.LikeInput{TargetID: "u2", IsLike: true}
+	body, _ := json.Marshal(input)
+	req := httptest.NewRequest("POST", "/likes", bytes.NewBuffer(body))
+	req = req.WithContext(context.WithValue(req.Context(), auth.UserIDKey, "u1"))
+	w := httptest.NewRecorder()
+
+	mockService.On("LikeUser", mock.Anything, "u1", input).Return(&service.LikeResult{
+		Quota: &quota.Usage{Remaining: 0, ResetAt: time.Now().Add(90 * time.Second)},
+	}, service.ErrLikeLimitReached)
+
+	h.Like(w, req)
+
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	retryAfter, err := strconv.Atoi(w.Header().Get("Retry-After"))
+	assert.NoError(t, err)
+	assert.InDelta(t, 90, retryAfter, 1)
+}
+
+func TestLikeHandler_Like_SuperLikeLimitReached(t *testing.T) {
+	mockService := new(MockLikeService)
+	h := handler.NewLikeHandler(mockService, new(MockStorageProvider), zap.NewNop())
+
+	input := service.LikeInput{TargetID: "u2", IsLike: true, Kind: like.KindSuperLike}
+	body, _ := json.Marshal(input)
+	req := httptest.NewRequest("POST", "/likes", bytes.NewBuffer(body))
+	req = req.WithContext(context.WithValue(req.Context(), auth.UserIDKey, "u1"))
+	w := httptest.NewRecorder()
+
+	resetAt := time.Now().Add(2 * time.Hour)
+	mockService.On("LikeUser", mock.Anything, "u1", input).Return(&service.LikeResult{SuperLikeResetAt: &resetAt}, service.ErrSuperLikeLimitReached)
+
+	h.Like(w, req)
+
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	retryAfter, err := strconv.Atoi(w.Header().Get("Retry-After"))
+	assert.NoError(t, err)
+	assert.InDelta(t, 7200, retryAfter, 1)
+}
+
+func TestLikeHandler_Like_Errors(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+	}{
+		{"invalid kind", service.ErrInvalidKind, http.StatusBadRequest},
+		{"super like limit", service.ErrSuperLikeLimitReached, http.StatusTooManyRequests},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockLikeService)
+			h := handler.NewLikeHandler(mockService, new(MockStorageProvider), zap.NewNop())
+
+			input := service.LikeInput{TargetID: "u2", IsLike: true, Kind: like.KindSuperLike}
+			body, _ := json.Marshal(input)
+			req := httptest.NewRequest("POST", "/likes", bytes.NewBuffer(body))
+			req = req.WithContext(context.WithValue(req.Context(), auth.UserIDKey, "u1"))
+			w := httptest.NewRecorder()
+
+			mockService.On("LikeUser", mock.Anything, "u1", input).Return(nil, tt.err)
+
+			h.Like(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+		})
+	}
 }
 
 func TestLikeHandler_GetMatches(t *testing.T) {
@@ -80,21 +207,149 @@ func TestLikeHandler_GetMatches(t *testing.T) {
 	logger := zap.NewNop()
 	h := handler.NewLikeHandler(mockService, mockStorage, logger)
 
-	req := httptest.NewRequest("GET", "/matches", nil)
+	req := httptest.NewRequest("GET", "/matches?limit=10&cursor=abc", nil)
 	req = req.WithContext(context.WithValue(req.Context(), auth.UserIDKey, "u1"))
 	w := httptest.NewRecorder()
 
-	matches := []*profile.Profile{
-		{UserID: "u2", FirstName: "User 2", Photos: []string{"p1.jpg"}},
+	lastMessage := "Hi!"
+	page := &service.MatchPage{
+		Matches: []*like.Match{
+			{UserID: "u2", Profile: &profile.PublicProfile{UserID: "u2", FirstName: "User 2", Photos: []string{"p1.jpg"}}, LastMessage: &lastMessage, UnreadCount: 1},
+		},
+		NextCursor: "next",
 	}
-	mockService.On("GetMatches", mock.Anything, "u1").Return(matches, nil)
+	mockService.On("GetMatches", mock.Anything, "u1", "abc", 10).Return(page, nil)
 	mockStorage.On("GetPresignedURL", mock.Anything, "p1.jpg").Return("http://p1.jpg", nil)
 
 	h.GetMatches(w, req)
 
 	assert.Equal(t, http.StatusOK, w.Code)
-	var resp []*profile.Profile
+	var resp service.MatchPage
 	json.NewDecoder(w.Body).Decode(&resp)
-	assert.Len(t, resp, 1)
-	assert.Equal(t, "http://p1.jpg", resp[0].Photos[0])
+	assert.Len(t, resp.Matches, 1)
+	assert.Equal(t, "http://p1.jpg", resp.Matches[0].Profile.Photos[0])
+	assert.Equal(t, "Hi!", *resp.Matches[0].LastMessage)
+	assert.Equal(t, 1, resp.Matches[0].UnreadCount)
+	assert.Equal(t, "next", resp.NextCursor)
+}
+
+func TestLikeHandler_GetMatches_InvalidCursor(t *testing.T) {
+	mockService := new(MockLikeService)
+	h := handler.NewLikeHandler(mockService, new(MockStorageProvider), zap.NewNop())
+
+	req := httptest.NewRequest("GET", "/matches?cursor=bad", nil)
+	req = req.WithContext(context.WithValue(req.Context(), auth.UserIDKey, "u1"))
+	w := httptest.NewRecorder()
+
+	mockService.On("GetMatches", mock.Anything, "u1", "bad", 0).Return(nil, service.ErrInvalidCursor)
+
+	h.GetMatches(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestLikeHandler_Unmatch(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+	}{
+		{"unmatched", nil, http.StatusNoContent},
+		{"not matched", service.ErrNotMatched, http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockLikeService)
+			h := handler.NewLikeHandler(mockService, new(MockStorageProvider), zap.NewNop())
+
+			req := httptest.NewRequest("DELETE", "/matches/u2", nil)
+			req.SetPathValue("userID", "u2")
+			req = req.WithContext(context.WithValue(req.Context(), auth.UserIDKey, "u1"))
+			w := httptest.NewRecorder()
+
+			mockService.On("Unmatch", mock.Anything, "u1", "u2").Return(tt.err)
+
+			h.Unmatch(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestLikeHandler_ReceivedLikes(t *testing.T) {
+	mockService := new(MockLikeService)
+	mockStorage := new(MockStorageProvider)
+	h := handler.NewLikeHandler(mockService, mockStorage, zap.NewNop())
+
+	req := httptest.NewRequest("GET", "/likes/received?limit=5&offset=10", nil)
+	req = req.WithContext(context.WithValue(req.Context(), auth.UserIDKey, "u1"))
+	w := httptest.NewRecorder()
+
+	likedAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	mockService.On("ListReceivedLikes", mock.Anything, "u1", 5, 10).Return(&service.ReceivedLikesPage{
+		Total: 11,
+		Likes: []*like.ReceivedLike{
+			{UserID: "u2", Profile: &profile.PublicProfile{UserID: "u2", Photos: []string{"p1.jpg"}}, LikedAt: likedAt},
+		},
+	}, nil)
+	mockStorage.On("GetPresignedURL", mock.Anything, "p1.jpg").Return("http://p1.jpg", nil)
+
+	h.ReceivedLikes(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var resp service.ReceivedLikesPage
+	json.NewDecoder(w.Body).Decode(&resp)
+	assert.Equal(t, 11, resp.Total)
+	assert.False(t, resp.Locked)
+	if assert.Len(t, resp.Likes, 1) {
+		assert.Equal(t, "http://p1.jpg", resp.Likes[0].Profile.Photos[0])
+		assert.True(t, likedAt.Equal(resp.Likes[0].LikedAt))
+	}
+}
+
+func TestLikeHandler_Rewind(t *testing.T) {
+	tests := []struct {
+		name       string
+		result     *service.RewindResult
+		err        error
+		wantStatus int
+	}{
+		{"rewound", &service.RewindResult{Kind: "pass", Profile: &profile.PublicProfile{UserID: "u2", Photos: []string{"p1.jpg"}}}, nil, http.StatusOK},
+		{"profile gone", &service.RewindResult{Kind: "like"}, nil, http.StatusOK},
+		{"nothing to rewind", nil, service.ErrNothingToRewind, http.StatusNotFound},
+		{"matched", nil, service.ErrRewindMatched, http.StatusConflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockLikeService)
+			mockStorage := new(MockStorageProvider)
+			h := handler.NewLikeHandler(mockService, mockStorage, zap.NewNop())
+
+			req := httptest.NewRequest("POST", "/likes/rewind", nil)
+			req = req.WithContext(context.WithValue(req.Context(), auth.UserIDKey, "u1"))
+			w := httptest.NewRecorder()
+
+			if tt.result != nil {
+				mockService.On("Rewind", mock.Anything, "u1").Return(tt.result, nil)
+			} else {
+				mockService.On("Rewind", mock.Anything, "u1").Return(nil, tt.err)
+			}
+			mockStorage.On("GetPresignedURL", mock.Anything, "p1.jpg").Return("http://p1.jpg", nil)
+
+			h.Rewind(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			if tt.wantStatus == http.StatusOK {
+				var resp service.RewindResult
+				json.NewDecoder(w.Body).Decode(&resp)
+				assert.Equal(t, tt.result.Kind, resp.Kind)
+				if tt.result.Profile != nil && assert.NotNil(t, resp.Profile) {
+					assert.Equal(t, "http://p1.jpg", resp.Profile.Photos[0])
+				}
+			}
+		})
+	}
 }
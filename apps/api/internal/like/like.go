@@ -1,6 +1,17 @@
 package like
 
-import "time"
+import (
+	"time"
+
+	"github.com/kisssonik/hearts/internal/profile"
+)
+
+// Like kinds. A super like is a like the recipient is told about straight
+// away; passes are always plain.
+const (
+	KindLike      = "like"
+	KindSuperLike = "superlike"
+)
 
 // Like represents a user interaction (like or pass).
 type Like struct {
@@ -8,5 +19,27 @@ type Like struct {
 	FromUserID string    `json:"fromUserId" db:"from_user_id"`
 	ToUserID   string    `json:"toUserId" db:"to_user_id"`
 	IsLike     bool      `json:"isLike" db:"is_like"`
+	Kind       string    `json:"kind" db:"kind"`
 	CreatedAt  time.Time `json:"createdAt" db:"created_at"`
 }
+
+// ReceivedLike is a like from someone the recipient has not answered yet.
+// UserID and Profile are left out of a locked preview.
+type ReceivedLike struct {
+	UserID  string                 `json:"userId,omitempty"`
+	Profile *profile.PublicProfile `json:"profile,omitempty"`
+	LikedAt time.Time              `json:"likedAt"`
+}
+
+// Match is one conversation in a user's match list, seen from that user's
+// side.
+type Match struct {
+	UserID           string                 `json:"userId"` // The other user
+	Profile          *profile.PublicProfile `json:"profile"`
+	MatchedAt        time.Time              `json:"matchedAt"`
+	LastMessage      *string                `json:"lastMessage"` // Snippet of the latest message, either direction
+	LastMessageAt    *time.Time             `json:"lastMessageAt"`
+	LastActivityAt   time.Time              `json:"lastActivityAt"` // Latest message, or the match itself
+	UnreadCount      int                    `json:"unreadCount"`
+	SentFirstMessage bool                   `json:"sentFirstMessage"` // Whether this user opened the conversation
+}
@@ -2,15 +2,71 @@ package repository
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"time"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/kisssonik/hearts/internal/like"
+	"github.com/kisssonik/hearts/internal/profile"
 )
 
+// LastMessageSnippetLength is the number of characters of the latest message
+// shown in the match list.
+const LastMessageSnippetLength = 100
+
+var (
+	// ErrSuperLikeLimitReached is returned when a user has used up their
+	// super likes for the period.
+	ErrSuperLikeLimitReached = errors.New("super like limit reached")
+	// ErrNothingToRewind is returned by Rewind when the user has no like or
+	// pass recent enough to undo.
+	ErrNothingToRewind = errors.New("nothing to rewind")
+	// ErrMatched is returned by Rewind when the latest like already
+	// produced a match.
+	ErrMatched = errors.New("like produced a match")
+)
+
+// MatchCursor is the position after which a match list page starts: the
+// last activity and user ID of the previous page's final match.
+type MatchCursor struct {
+	LastActivityAt time.Time
+	UserID         string
+}
+
 type LikeRepository interface {
 	Upsert(ctx context.Context, l *like.Like) error
-	HasMutualLike(ctx context.Context, user1ID, user2ID string) (bool, error)
-	GetMatches(ctx context.Context, userID string) ([]string, error)
+	SuperLike(ctx context.Context, l *like.Like, since time.Time, limit int) (bool, error)
+	CreateMatch(ctx context.Context, user1ID, user2ID string) (bool, error)
+	IsMatched(ctx context.Context, user1ID, user2ID string) (bool, error)
+	ListMatches(ctx context.Context, userID string, after *MatchCursor, limit int) ([]*like.Match, error)
+	Unmatch(ctx context.Context, userID, otherUserID string) (bool, error)
+	ListReceived(ctx context.Context, userID string, limit, offset int) ([]*like.ReceivedLike, error)
+	CountReceived(ctx context.Context, userID string) (int, error)
+	Rewind(ctx context.Context, userID string, since time.Time) (*like.Like, error)
+}
+
+// receivedSQL selects from likes l the unanswered likes to the user bound to
+// $1: the recipient has neither liked nor passed the sender, neither has
+// blocked the other, and the sender's profile is not paused.
+const receivedSQL = `
+	FROM likes l
+	JOIN profiles p ON p.user_id = l.from_user_id
+	WHERE l.to_user_id = $1 AND l.is_like = TRUE
+	  AND p.visibility <> '` + profile.VisibilityPaused + `'
+	  AND NOT EXISTS (SELECT 1 FROM likes a WHERE a.from_user_id = $1 AND a.to_user_id = l.from_user_id)
+	  AND NOT EXISTS (
+		SELECT 1 FROM user_blocks b
+		WHERE (b.blocker_id = l.from_user_id AND b.blocked_id = $1)
+		   OR (b.blocker_id = $1 AND b.blocked_id = l.from_user_id)
+	  )
+`
+
+// pairSQL is a condition on the matches row m for the users bound to a and
+// b, in either order.
+func pairSQL(a, b string) string {
+	return `m.user1_id = LEAST(` + a + `::uuid, ` + b + `::uuid) AND m.user2_id = GREATEST(` + a + `::uuid, ` + b + `::uuid)`
 }
 
 type pgxLikeRepository struct {
@@ -22,58 +78,295 @@ func NewLikeRepository(db *pgxpool.Pool) LikeRepository {
 }
 
 func (r *pgxLikeRepository) Upsert(ctx context.Context, l *like.Like) error {
+	return upsertLike(ctx, r.db, l)
+}
+
+// querier is what upsertLike needs from a pool or a transaction.
+type querier interface {
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+// upsertLike stores l, replacing any earlier like or pass between the same
+// users. The replaced swipe is kept in the previous_* columns for Rewind.
+func upsertLike(ctx context.Context, q querier, l *like.Like) error {
+	if l.Kind == "" {
+		l.Kind = like.KindLike
+	}
 	query := `
-		INSERT INTO likes (from_user_id, to_user_id, is_like)
-		VALUES ($1, $2, $3)
+		INSERT INTO likes (from_user_id, to_user_id, is_like, kind)
+		VALUES ($1, $2, $3, $4)
 		ON CONFLICT (from_user_id, to_user_id) 
-		DO UPDATE SET is_like = EXCLUDED.is_like, created_at = NOW()
+		DO UPDATE SET is_like = EXCLUDED.is_like, kind = EXCLUDED.kind, created_at = NOW(),
+			previous_is_like = likes.is_like, previous_kind = likes.kind, previous_created_at = likes.created_at
 		RETURNING id, created_at
 	`
-	return r.db.QueryRow(ctx, query,
-		l.FromUserID, l.ToUserID, l.IsLike,
+	return q.QueryRow(ctx, query,
+		l.FromUserID, l.ToUserID, l.IsLike, l.Kind,
 	).Scan(&l.ID, &l.CreatedAt)
 }
 
-func (r *pgxLikeRepository) HasMutualLike(ctx context.Context, user1ID, user2ID string) (bool, error) {
-	// Check if user1 liked user2 AND user2 liked user1
+// SuperLike stores l as a super like if its sender has sent fewer than limit
+// super likes since the given time, and returns ErrSuperLikeLimitReached
+// otherwise. It reports false, using up nothing, if the sender had already
+// super liked the recipient. Checking and spending the quota happen in one
+// transaction under a per-sender lock, so concurrent requests cannot
+// overspend it.
+func (r *pgxLikeRepository) SuperLike(ctx context.Context, l *like.Like, since time.Time, limit int) (bool, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock(hashtext('super_likes'), hashtext($1))`, l.FromUserID); err != nil {
+		return false, err
+	}
+
+	err = tx.QueryRow(ctx, `
+		SELECT id, created_at FROM likes
+		WHERE from_user_id = $1 AND to_user_id = $2 AND is_like = TRUE AND kind = $3
+	`, l.FromUserID, l.ToUserID, like.KindSuperLike).Scan(&l.ID, &l.CreatedAt)
+	if err == nil {
+		l.IsLike, l.Kind = true, like.KindSuperLike
+		return false, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return false, err
+	}
+
+	var used int
+	if err := tx.QueryRow(ctx, `
+		SELECT COUNT(*) FROM super_likes WHERE from_user_id = $1 AND created_at >= $2
+	`, l.FromUserID, since).Scan(&used); err != nil {
+		return false, err
+	}
+	if used >= limit {
+		return false, ErrSuperLikeLimitReached
+	}
+
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO super_likes (from_user_id, to_user_id) VALUES ($1, $2)
+	`, l.FromUserID, l.ToUserID); err != nil {
+		return false, err
+	}
+	l.IsLike, l.Kind = true, like.KindSuperLike
+	if err := upsertLike(ctx, tx, l); err != nil {
+		return false, err
+	}
+	return true, tx.Commit(ctx)
+}
+
+// CreateMatch records a match between user1ID and user2ID if they have liked
+// each other and neither has unmatched the other. It reports whether a new
+// match was created, so concurrent checks for the same pair create it once.
+func (r *pgxLikeRepository) CreateMatch(ctx context.Context, user1ID, user2ID string) (bool, error) {
 	query := `
-		SELECT COUNT(*)
-		FROM likes l1
-		JOIN likes l2 ON l1.to_user_id = l2.from_user_id AND l1.from_user_id = l2.to_user_id
-		WHERE l1.from_user_id = $1 AND l1.to_user_id = $2
-		  AND l1.is_like = TRUE AND l2.is_like = TRUE
+		INSERT INTO matches (user1_id, user2_id)
+		SELECT LEAST($1::uuid, $2::uuid), GREATEST($1::uuid, $2::uuid)
+		WHERE EXISTS (
+			SELECT 1
+			FROM likes l1
+			JOIN likes l2 ON l1.to_user_id = l2.from_user_id AND l1.from_user_id = l2.to_user_id
+			WHERE l1.from_user_id = $1 AND l1.to_user_id = $2
+			  AND l1.is_like = TRUE AND l2.is_like = TRUE
+			FOR SHARE OF l1, l2
+		)
+		AND NOT EXISTS (
+			SELECT 1 FROM unmatches u
+			WHERE (u.user_id = $1 AND u.other_user_id = $2)
+			   OR (u.user_id = $2 AND u.other_user_id = $1)
+		)
+		ON CONFLICT DO NOTHING
 	`
-	var count int
-	err := r.db.QueryRow(ctx, query, user1ID, user2ID).Scan(&count)
+	tag, err := r.db.Exec(ctx, query, user1ID, user2ID)
 	if err != nil {
 		return false, err
 	}
-	return count > 0, nil
+	return tag.RowsAffected() > 0, nil
+}
+
+func (r *pgxLikeRepository) IsMatched(ctx context.Context, user1ID, user2ID string) (bool, error) {
+	query := `SELECT EXISTS (SELECT 1 FROM matches m WHERE ` + pairSQL("$1", "$2") + `)`
+	var matched bool
+	if err := r.db.QueryRow(ctx, query, user1ID, user2ID).Scan(&matched); err != nil {
+		return false, err
+	}
+	return matched, nil
 }
 
-func (r *pgxLikeRepository) GetMatches(ctx context.Context, userID string) ([]string, error) {
+// ListMatches returns userID's matches by most recent activity, newest
+// first, starting after the given cursor. Profiles are not loaded.
+func (r *pgxLikeRepository) ListMatches(ctx context.Context, userID string, after *MatchCursor, limit int) ([]*like.Match, error) {
+	var afterAt *time.Time
+	var afterUserID *string
+	if after != nil {
+		afterAt, afterUserID = &after.LastActivityAt, &after.UserID
+	}
+	query := fmt.Sprintf(`
+		WITH summaries AS (
+			SELECT
+				o.other_id,
+				o.matched_at,
+				LEFT(last.content, %d) AS last_message,
+				last.created_at AS last_message_at,
+				COALESCE(last.created_at, o.matched_at) AS last_activity_at,
+				(
+					SELECT COUNT(*) FROM messages u
+					WHERE u.sender_id = o.other_id AND u.receiver_id = $1 AND u.is_read = FALSE
+				) AS unread_count,
+				COALESCE((
+					SELECT f.sender_id = $1 FROM messages f
+					WHERE (f.sender_id = $1 AND f.receiver_id = o.other_id) OR (f.sender_id = o.other_id AND f.receiver_id = $1)
+					ORDER BY f.created_at ASC
+					LIMIT 1
+				), FALSE) AS sent_first_message
+			FROM (
+				SELECT CASE WHEN m.user1_id = $1 THEN m.user2_id ELSE m.user1_id END AS other_id, m.matched_at
+				FROM matches m
+				WHERE m.user1_id = $1 OR m.user2_id = $1
+			) o
+			LEFT JOIN LATERAL (
+				SELECT l.content, l.created_at FROM messages l
+				WHERE (l.sender_id = $1 AND l.receiver_id = o.other_id) OR (l.sender_id = o.other_id AND l.receiver_id = $1)
+				ORDER BY l.created_at DESC
+				LIMIT 1
+			) last ON TRUE
+		)
+		SELECT other_id, matched_at, last_message, last_message_at, last_activity_at, unread_count, sent_first_message
+		FROM summaries
+		WHERE $2::timestamptz IS NULL OR (last_activity_at, other_id) < ($2::timestamptz, $3::uuid)
+		ORDER BY last_activity_at DESC, other_id DESC
+		LIMIT $4
+	`, LastMessageSnippetLength)
+	rows, err := r.db.Query(ctx, query, userID, afterAt, afterUserID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var matches []*like.Match
+	for rows.Next() {
+		m := &like.Match{}
+		if err := rows.Scan(
+			&m.UserID, &m.MatchedAt, &m.LastMessage, &m.LastMessageAt, &m.LastActivityAt, &m.UnreadCount, &m.SentFirstMessage,
+		); err != nil {
+			return nil, err
+		}
+		matches = append(matches, m)
+	}
+	return matches, rows.Err()
+}
+
+// Unmatch records that userID ended their match with otherUserID and removes
+// the match. It reports false, and records nothing, if the two are not
+// currently matched.
+func (r *pgxLikeRepository) Unmatch(ctx context.Context, userID, otherUserID string) (bool, error) {
 	query := `
-		SELECT l1.to_user_id
-		FROM likes l1
-		JOIN likes l2 ON l1.to_user_id = l2.from_user_id
-		WHERE l1.from_user_id = $1 
-		  AND l1.is_like = TRUE 
-		  AND l2.to_user_id = $1 
-		  AND l2.is_like = TRUE
+		WITH ended AS (
+			DELETE FROM matches m
+			WHERE ` + pairSQL("$1", "$2") + `
+			RETURNING 1
+		)
+		INSERT INTO unmatches (user_id, other_user_id)
+		SELECT $1, $2 FROM ended
+		ON CONFLICT DO NOTHING
 	`
-	rows, err := r.db.Query(ctx, query, userID)
+	tag, err := r.db.Exec(ctx, query, userID, otherUserID)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// ListReceived returns the unanswered likes userID has received, newest
+// first. Profiles are not loaded.
+func (r *pgxLikeRepository) ListReceived(ctx context.Context, userID string, limit, offset int) ([]*like.ReceivedLike, error) {
+	query := `
+		SELECT l.from_user_id, l.created_at
+	` + receivedSQL + `
+		ORDER BY l.created_at DESC, l.from_user_id
+		LIMIT $2 OFFSET $3
+	`
+	rows, err := r.db.Query(ctx, query, userID, limit, offset)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var matches []string
+	var likes []*like.ReceivedLike
 	for rows.Next() {
-		var matchID string
-		if err := rows.Scan(&matchID); err != nil {
+		l := &like.ReceivedLike{}
+		if err := rows.Scan(&l.UserID, &l.LikedAt); err != nil {
+			return nil, err
+		}
+		likes = append(likes, l)
+	}
+	return likes, rows.Err()
+}
+
+func (r *pgxLikeRepository) CountReceived(ctx context.Context, userID string) (int, error) {
+	var count int
+	err := r.db.QueryRow(ctx, `SELECT COUNT(*) `+receivedSQL, userID).Scan(&count)
+	return count, err
+}
+
+// Rewind undoes userID's most recent like or pass if it was made at or after
+// since and has not produced a match, and returns it. Otherwise it returns
+// ErrNothingToRewind or ErrMatched. A swipe that replaced an earlier one for
+// the same profile is rolled back to that earlier swipe; any other is
+// deleted. The like row stays locked until then, and CreateMatch share-locks
+// the likes it reads, so a match cannot be created from a like that is being
+// rewound.
+func (r *pgxLikeRepository) Rewind(ctx context.Context, userID string, since time.Time) (*like.Like, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	l := &like.Like{}
+	err = tx.QueryRow(ctx, `
+		SELECT id, from_user_id, to_user_id, is_like, kind, created_at
+		FROM likes
+		WHERE from_user_id = $1
+		ORDER BY created_at DESC, id DESC
+		LIMIT 1
+		FOR UPDATE
+	`, userID).Scan(&l.ID, &l.FromUserID, &l.ToUserID, &l.IsLike, &l.Kind, &l.CreatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNothingToRewind
+		}
+		return nil, err
+	}
+	if l.CreatedAt.Before(since) {
+		return nil, ErrNothingToRewind
+	}
+
+	var matched bool
+	if err := tx.QueryRow(ctx, `
+		SELECT EXISTS (SELECT 1 FROM matches m WHERE `+pairSQL("$1", "$2")+`)
+	`, l.FromUserID, l.ToUserID).Scan(&matched); err != nil {
+		return nil, err
+	}
+	if matched {
+		return nil, ErrMatched
+	}
+
+	// Only one earlier swipe is kept, so the restored row has none of its own.
+	tag, err := tx.Exec(ctx, `
+		UPDATE likes
+		SET is_like = previous_is_like, kind = previous_kind, created_at = previous_created_at,
+			previous_is_like = NULL, previous_kind = NULL, previous_created_at = NULL
+		WHERE id = $1 AND previous_created_at IS NOT NULL
+	`, l.ID)
+	if err != nil {
+		return nil, err
+	}
+	if tag.RowsAffected() == 0 {
+		if _, err := tx.Exec(ctx, `DELETE FROM likes WHERE id = $1`, l.ID); err != nil {
 			return nil, err
 		}
-		matches = append(matches, matchID)
 	}
-	return matches, nil
+	return l, tx.Commit(ctx)
 }
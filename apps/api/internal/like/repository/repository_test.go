@@ -3,6 +3,7 @@ package repository_test
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/kisssonik/hearts/internal/like"
@@ -59,23 +60,288 @@ func TestLike_Integration(t *testing.T) {
 	err := repo.Upsert(ctx, l1)
 	assert.NoError(t, err)
 
-	// Check mutual like (should be false)
-	mutual, err := repo.HasMutualLike(ctx, u1.ID, u2.ID)
+	// A one-sided like is not a match
+	created, err := repo.CreateMatch(ctx, u1.ID, u2.ID)
 	assert.NoError(t, err)
-	assert.False(t, mutual)
+	assert.False(t, created)
 
 	// u2 likes u1
 	l2 := &like.Like{FromUserID: u2.ID, ToUserID: u1.ID, IsLike: true}
 	err = repo.Upsert(ctx, l2)
 	assert.NoError(t, err)
 
-	// Check mutual like (should be true)
-	mutual, err = repo.HasMutualLike(ctx, u1.ID, u2.ID)
+	// The first check creates the match, later ones find it
+	created, err = repo.CreateMatch(ctx, u2.ID, u1.ID)
 	assert.NoError(t, err)
-	assert.True(t, mutual)
+	assert.True(t, created)
+	created, err = repo.CreateMatch(ctx, u1.ID, u2.ID)
+	assert.NoError(t, err)
+	assert.False(t, created)
+
+	matched, err := repo.IsMatched(ctx, u1.ID, u2.ID)
+	assert.NoError(t, err)
+	assert.True(t, matched)
 
-	// Check matches
-	matches, err := repo.GetMatches(ctx, u1.ID)
+	// Check matches from both sides
+	matches, err := repo.ListMatches(ctx, u1.ID, nil, 10)
 	assert.NoError(t, err)
-	assert.Contains(t, matches, u2.ID)
+	require.Len(t, matches, 1)
+	assert.Equal(t, u2.ID, matches[0].UserID)
+	matches, err = repo.ListMatches(ctx, u2.ID, nil, 10)
+	assert.NoError(t, err)
+	require.Len(t, matches, 1)
+	assert.Equal(t, u1.ID, matches[0].UserID)
+}
+
+func TestUnmatch_Integration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	db := setupTestDB(t)
+	defer db.Close()
+
+	repo := repository.NewLikeRepository(db)
+	ctx := context.Background()
+
+	u1 := createTestUser(t, db, "u1@example.com", "u1")
+	u2 := createTestUser(t, db, "u2@example.com", "u2")
+
+	// Not matched yet.
+	ok, err := repo.Unmatch(ctx, u1.ID, u2.ID)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	require.NoError(t, repo.Upsert(ctx, &like.Like{FromUserID: u1.ID, ToUserID: u2.ID, IsLike: true}))
+	require.NoError(t, repo.Upsert(ctx, &like.Like{FromUserID: u2.ID, ToUserID: u1.ID, IsLike: true}))
+	_, err = repo.CreateMatch(ctx, u1.ID, u2.ID)
+	require.NoError(t, err)
+
+	ok, err = repo.Unmatch(ctx, u2.ID, u1.ID)
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	matched, err := repo.IsMatched(ctx, u1.ID, u2.ID)
+	require.NoError(t, err)
+	assert.False(t, matched)
+
+	matches, err := repo.ListMatches(ctx, u1.ID, nil, 10)
+	require.NoError(t, err)
+	assert.Empty(t, matches)
+
+	// Liking again does not bring the match back.
+	require.NoError(t, repo.Upsert(ctx, &like.Like{FromUserID: u1.ID, ToUserID: u2.ID, IsLike: true}))
+	created, err := repo.CreateMatch(ctx, u1.ID, u2.ID)
+	require.NoError(t, err)
+	assert.False(t, created)
+
+	ok, err = repo.Unmatch(ctx, u1.ID, u2.ID)
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestListMatches_Integration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	db := setupTestDB(t)
+	defer db.Close()
+
+	repo := repository.NewLikeRepository(db)
+	ctx := context.Background()
+
+	me := createTestUser(t, db, "me@example.com", "me")
+	quiet := createTestUser(t, db, "quiet@example.com", "quiet")
+	chatty := createTestUser(t, db, "chatty@example.com", "chatty")
+
+	for _, other := range []string{quiet.ID, chatty.ID} {
+		require.NoError(t, repo.Upsert(ctx, &like.Like{FromUserID: me.ID, ToUserID: other, IsLike: true}))
+		require.NoError(t, repo.Upsert(ctx, &like.Like{FromUserID: other, ToUserID: me.ID, IsLike: true}))
+		_, err := repo.CreateMatch(ctx, me.ID, other)
+		require.NoError(t, err)
+	}
+	_, err := db.Exec(ctx, `
+		INSERT INTO messages (sender_id, receiver_id, content, created_at) VALUES
+			($1, $2, 'Hey!', NOW() + INTERVAL '1 minute'),
+			($2, $1, 'Hi there', NOW() + INTERVAL '2 minutes'),
+			($2, $1, 'How are you?', NOW() + INTERVAL '3 minutes')
+	`, me.ID, chatty.ID)
+	require.NoError(t, err)
+
+	page, err := repo.ListMatches(ctx, me.ID, nil, 1)
+	require.NoError(t, err)
+	require.Len(t, page, 1)
+	first := page[0]
+	assert.Equal(t, chatty.ID, first.UserID)
+	assert.Equal(t, "How are you?", *first.LastMessage)
+	assert.Equal(t, 2, first.UnreadCount)
+	assert.True(t, first.SentFirstMessage)
+
+	page, err = repo.ListMatches(ctx, me.ID, &repository.MatchCursor{LastActivityAt: first.LastActivityAt, UserID: first.UserID}, 1)
+	require.NoError(t, err)
+	require.Len(t, page, 1)
+	assert.Equal(t, quiet.ID, page[0].UserID)
+	assert.Nil(t, page[0].LastMessage)
+	assert.Zero(t, page[0].UnreadCount)
+	assert.False(t, page[0].SentFirstMessage)
+}
+
+func TestListReceived_Integration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	db := setupTestDB(t)
+	defer db.Close()
+
+	repo := repository.NewLikeRepository(db)
+	ctx := context.Background()
+
+	me := createTestUser(t, db, "me@example.com", "me")
+	admirer := createTestUser(t, db, "admirer@example.com", "admirer")
+	answered := createTestUser(t, db, "answered@example.com", "answered")
+	paused := createTestUser(t, db, "paused@example.com", "paused")
+	for _, u := range []string{admirer.ID, answered.ID, paused.ID} {
+		_, err := db.Exec(ctx, "INSERT INTO profiles (user_id, first_name) VALUES ($1, 'Someone')", u)
+		require.NoError(t, err)
+		require.NoError(t, repo.Upsert(ctx, &like.Like{FromUserID: u, ToUserID: me.ID, IsLike: true}))
+	}
+	_, err := db.Exec(ctx, "UPDATE profiles SET visibility = 'paused' WHERE user_id = $1", paused.ID)
+	require.NoError(t, err)
+	// A pass is an answer too.
+	require.NoError(t, repo.Upsert(ctx, &like.Like{FromUserID: me.ID, ToUserID: answered.ID, IsLike: false}))
+
+	received, err := repo.ListReceived(ctx, me.ID, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, received, 1)
+	assert.Equal(t, admirer.ID, received[0].UserID)
+
+	count, err := repo.CountReceived(ctx, me.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+}
+
+func TestSuperLike_Integration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	db := setupTestDB(t)
+	defer db.Close()
+
+	repo := repository.NewLikeRepository(db)
+	ctx := context.Background()
+
+	u1 := createTestUser(t, db, "u1@example.com", "u1")
+	u2 := createTestUser(t, db, "u2@example.com", "u2")
+	u3 := createTestUser(t, db, "u3@example.com", "u3")
+	since := time.Now().Add(-time.Hour)
+
+	l := &like.Like{FromUserID: u1.ID, ToUserID: u2.ID}
+	sent, err := repo.SuperLike(ctx, l, since, 1)
+	require.NoError(t, err)
+	assert.True(t, sent)
+	assert.Equal(t, like.KindSuperLike, l.Kind)
+	assert.NotEmpty(t, l.ID)
+
+	// Super liking the same user again uses up nothing.
+	sent, err = repo.SuperLike(ctx, &like.Like{FromUserID: u1.ID, ToUserID: u2.ID}, since, 1)
+	require.NoError(t, err)
+	assert.False(t, sent)
+
+	_, err = repo.SuperLike(ctx, &like.Like{FromUserID: u1.ID, ToUserID: u3.ID}, since, 1)
+	assert.ErrorIs(t, err, repository.ErrSuperLikeLimitReached)
+
+	// Taking the super like back does not refund it.
+	require.NoError(t, repo.Upsert(ctx, &like.Like{FromUserID: u1.ID, ToUserID: u2.ID, IsLike: false}))
+	_, err = repo.SuperLike(ctx, &like.Like{FromUserID: u1.ID, ToUserID: u3.ID}, since, 1)
+	assert.ErrorIs(t, err, repository.ErrSuperLikeLimitReached)
+
+	// A new period starts afresh.
+	sent, err = repo.SuperLike(ctx, &like.Like{FromUserID: u1.ID, ToUserID: u3.ID}, time.Now().Add(time.Hour), 1)
+	require.NoError(t, err)
+	assert.True(t, sent)
+}
+
+func TestRewind_Integration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	db := setupTestDB(t)
+	defer db.Close()
+
+	repo := repository.NewLikeRepository(db)
+	ctx := context.Background()
+
+	u1 := createTestUser(t, db, "u1@example.com", "u1")
+	u2 := createTestUser(t, db, "u2@example.com", "u2")
+	u3 := createTestUser(t, db, "u3@example.com", "u3")
+	since := time.Now().Add(-time.Minute)
+
+	_, err := repo.Rewind(ctx, u1.ID, since)
+	assert.ErrorIs(t, err, repository.ErrNothingToRewind)
+
+	require.NoError(t, repo.Upsert(ctx, &like.Like{FromUserID: u1.ID, ToUserID: u2.ID, IsLike: true}))
+	require.NoError(t, repo.Upsert(ctx, &like.Like{FromUserID: u1.ID, ToUserID: u3.ID, IsLike: false}))
+
+	// Only the latest swipe is undone.
+	l, err := repo.Rewind(ctx, u1.ID, since)
+	require.NoError(t, err)
+	assert.Equal(t, u3.ID, l.ToUserID)
+	assert.False(t, l.IsLike)
+
+	// Too old.
+	_, err = repo.Rewind(ctx, u1.ID, time.Now().Add(time.Minute))
+	assert.ErrorIs(t, err, repository.ErrNothingToRewind)
+
+	// A like that produced a match stays.
+	require.NoError(t, repo.Upsert(ctx, &like.Like{FromUserID: u2.ID, ToUserID: u1.ID, IsLike: true}))
+	_, err = repo.CreateMatch(ctx, u2.ID, u1.ID)
+	require.NoError(t, err)
+	_, err = repo.Rewind(ctx, u1.ID, since)
+	assert.ErrorIs(t, err, repository.ErrMatched)
+}
+
+func TestRewind_RestoresEarlierSwipe_Integration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	db := setupTestDB(t)
+	defer db.Close()
+
+	repo := repository.NewLikeRepository(db)
+	ctx := context.Background()
+
+	u1 := createTestUser(t, db, "u1@example.com", "u1")
+	u2 := createTestUser(t, db, "u2@example.com", "u2")
+	since := time.Now().Add(-time.Minute)
+
+	pass := &like.Like{FromUserID: u1.ID, ToUserID: u2.ID, IsLike: false}
+	require.NoError(t, repo.Upsert(ctx, pass))
+	require.NoError(t, repo.Upsert(ctx, &like.Like{FromUserID: u1.ID, ToUserID: u2.ID, IsLike: true}))
+
+	// Undoing the like brings the pass back rather than forgetting u2.
+	l, err := repo.Rewind(ctx, u1.ID, since)
+	require.NoError(t, err)
+	assert.True(t, l.IsLike)
+
+	var isLike bool
+	var createdAt time.Time
+	err = db.QueryRow(ctx, `
+		SELECT is_like, created_at FROM likes WHERE from_user_id = $1 AND to_user_id = $2
+	`, u1.ID, u2.ID).Scan(&isLike, &createdAt)
+	require.NoError(t, err)
+	assert.False(t, isLike)
+	assert.True(t, createdAt.Equal(pass.CreatedAt))
+
+	// Only one earlier swipe is kept, so the pass itself is then deleted.
+	l, err = repo.Rewind(ctx, u1.ID, since)
+	require.NoError(t, err)
+	assert.False(t, l.IsLike)
+	var count int
+	require.NoError(t, db.QueryRow(ctx, `SELECT COUNT(*) FROM likes WHERE from_user_id = $1`, u1.ID).Scan(&count))
+	assert.Zero(t, count)
 }
@@ -2,7 +2,12 @@ package service
 
 import (
 	"context"
+	"encoding/base64"
+	"encoding/json"
 	"errors"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/kisssonik/hearts/internal/like"
 	"github.com/kisssonik/hearts/internal/like/repository"
@@ -10,19 +15,114 @@ import (
 	"github.com/kisssonik/hearts/internal/profile"
 	profileRepo "github.com/kisssonik/hearts/internal/profile/repository"
 	"github.com/kisssonik/hearts/pkg/queue"
+	"github.com/kisssonik/hearts/pkg/quota"
+	"github.com/kisssonik/hearts/pkg/websocket"
 )
 
-var ErrSelfLike = errors.New("cannot like yourself")
+var (
+	ErrSelfLike      = errors.New("cannot like yourself")
+	ErrNotMatched    = errors.New("users are not matched")
+	ErrInvalidCursor = errors.New("invalid cursor")
+	ErrInvalidKind   = errors.New("kind must be like or superlike, and a pass cannot be a super like")
+
+	ErrSuperLikeLimitReached = errors.New("daily super like limit reached")
+	ErrLikeLimitReached      = errors.New("like limit reached")
+	ErrNothingToRewind       = errors.New("no recent like or pass to rewind")
+	ErrRewindMatched         = errors.New("cannot rewind a like that produced a match")
+)
+
+// List page size bounds.
+const (
+	DefaultMatchesLimit       = 20
+	MaxMatchesLimit           = 100
+	DefaultReceivedLikesLimit = 20
+	MaxReceivedLikesLimit     = 100
+)
+
+// DefaultSuperLikesPerDay is the daily super like quota when none is
+// configured. Quotas reset at midnight UTC.
+const DefaultSuperLikesPerDay = 1
+
+// DefaultLikeLimit caps likes, super likes included, when no limit is
+// configured. Passes are not limited.
+var DefaultLikeLimit = quota.Limit{Max: 100, Window: 24 * time.Hour}
+
+// DefaultRewindWindow is how long a like or pass can be undone when no
+// window is configured.
+const DefaultRewindWindow = 5 * time.Minute
+
+// Entitlements decides which gated features a user has.
+type Entitlements interface {
+	// CanSeeReceivedLikes reports whether userID may see who liked them,
+	// rather than a preview.
+	CanSeeReceivedLikes(ctx context.Context, userID string) (bool, error)
+}
+
+// StaticEntitlements grants the same features to every user.
+type StaticEntitlements struct {
+	ReceivedLikes bool
+}
+
+func (e StaticEntitlements) CanSeeReceivedLikes(ctx context.Context, userID string) (bool, error) {
+	return e.ReceivedLikes, nil
+}
+
+// Config holds like service settings. A nil Entitlements grants nothing, a
+// nil Limiter keeps like quotas in memory and zero limits mean the defaults.
+type Config struct {
+	Entitlements     Entitlements
+	SuperLikesPerDay int
+	Limiter          quota.Limiter
+	LikeLimit        quota.Limit
+	RewindWindow     time.Duration
+}
 
 type LikeService interface {
-	LikeUser(ctx context.Context, fromUserID string, input LikeInput) (bool, error)
-	GetMatches(ctx context.Context, userID string) ([]*profile.Profile, error)
+	LikeUser(ctx context.Context, fromUserID string, input LikeInput) (*LikeResult, error)
+	GetMatches(ctx context.Context, userID, cursor string, limit int) (*MatchPage, error)
 	ProcessMatchCheck(ctx context.Context, fromUserID, targetID string) error
+	Unmatch(ctx context.Context, userID, otherUserID string) error
+	ListReceivedLikes(ctx context.Context, userID string, limit, offset int) (*ReceivedLikesPage, error)
+	Rewind(ctx context.Context, userID string) (*RewindResult, error)
 }
 
 type LikeInput struct {
 	TargetID string `json:"targetId"`
 	IsLike   bool   `json:"isLike"`
+	Kind     string `json:"kind,omitempty"` // "like" (default) or "superlike"
+}
+
+// LikeResult is the outcome of a like or pass. Quota is the sender's like
+// quota after a like and is nil after a pass. When the like is refused with
+// ErrLikeLimitReached or ErrSuperLikeLimitReached, the result is still
+// returned to say when quota frees up.
+type LikeResult struct {
+	IsMatch          bool
+	Quota            *quota.Usage
+	SuperLikeResetAt *time.Time // Set when the daily super like quota is spent
+}
+
+// MatchPage is one page of a match list. NextCursor is empty on the last
+// page.
+type MatchPage struct {
+	Matches    []*like.Match `json:"matches"`
+	NextCursor string        `json:"nextCursor,omitempty"`
+}
+
+// ReceivedLikesPage lists unanswered likes. When Locked is set the user is not
+// entitled to see who sent them: each like only carries its time.
+type ReceivedLikesPage struct {
+	Total  int                  `json:"total"`
+	Locked bool                 `json:"locked"`
+	Likes  []*like.ReceivedLike `json:"likes"`
+}
+
+// RewindResult describes an undone like or pass. Kind is "like",
+// "superlike" or "pass". Profile is nil if the other user can no longer be
+// seen, e.g. because they have since paused or blocked.
+type RewindResult struct {
+	Kind    string                 `json:"kind"`
+	Profile *profile.PublicProfile `json:"profile,omitempty"`
 }
 
 type MatchCheckMessage struct {
@@ -35,35 +135,123 @@ type likeService struct {
 	profileRepo         profileRepo.ProfileRepository
 	notificationService service.NotificationService
 	producer            queue.Producer
+	hub                 *websocket.Hub
+	entitlements        Entitlements
+	superLikesPerDay    int
+	limiter             quota.Limiter
+	likeLimit           quota.Limit
+	rewindWindow        time.Duration
+}
+
+// UnmatchEvent is pushed over the WebSocket to both users when a match ends.
+// UserID is the other side of the match, from the recipient's point of view.
+type UnmatchEvent struct {
+	Type   string `json:"type"`
+	UserID string `json:"userId"`
 }
 
-func NewLikeService(repo repository.LikeRepository, profileRepo profileRepo.ProfileRepository, notificationService service.NotificationService, producer queue.Producer) LikeService {
+func NewLikeService(repo repository.LikeRepository, profileRepo profileRepo.ProfileRepository, notificationService service.NotificationService, producer queue.Producer, hub *websocket.Hub, cfg Config) LikeService {
+	entitlements := cfg.Entitlements
+	if entitlements == nil {
+		entitlements = StaticEntitlements{}
+	}
+	superLikesPerDay := cfg.SuperLikesPerDay
+	if superLikesPerDay <= 0 {
+		superLikesPerDay = DefaultSuperLikesPerDay
+	}
+	limiter := cfg.Limiter
+	if limiter == nil {
+		limiter = quota.NewMemoryLimiter()
+	}
+	likeLimit := cfg.LikeLimit
+	if likeLimit.Max <= 0 || likeLimit.Window <= 0 {
+		likeLimit = DefaultLikeLimit
+	}
+	rewindWindow := cfg.RewindWindow
+	if rewindWindow <= 0 {
+		rewindWindow = DefaultRewindWindow
+	}
 	return &likeService{
 		repo:                repo,
 		profileRepo:         profileRepo,
 		notificationService: notificationService,
 		producer:            producer,
+		hub:                 hub,
+		entitlements:        entitlements,
+		superLikesPerDay:    superLikesPerDay,
+		limiter:             limiter,
+		likeLimit:           likeLimit,
+		rewindWindow:        rewindWindow,
 	}
 }
 
-func (s *likeService) LikeUser(ctx context.Context, fromUserID string, input LikeInput) (bool, error) {
+func (s *likeService) LikeUser(ctx context.Context, fromUserID string, input LikeInput) (*LikeResult, error) {
 	if fromUserID == input.TargetID {
-		return false, ErrSelfLike
+		return nil, ErrSelfLike
+	}
+
+	kind := input.Kind
+	if kind == "" {
+		kind = like.KindLike
+	}
+	if (kind != like.KindLike && kind != like.KindSuperLike) || (kind == like.KindSuperLike && !input.IsLike) {
+		return nil, ErrInvalidKind
+	}
+
+	result := &LikeResult{}
+	quotaKey := "likes:" + fromUserID
+	if input.IsLike {
+		usage, err := s.limiter.Take(ctx, quotaKey, s.likeLimit)
+		if err != nil {
+			if errors.Is(err, quota.ErrExceeded) {
+				return &LikeResult{Quota: &usage}, ErrLikeLimitReached
+			}
+			return nil, err
+		}
+		result.Quota = &usage
+	}
+	// The like is paid for up front so racing requests cannot overspend,
+	// and the unit is given back if nothing new is written.
+	refund := func() {
+		if result.Quota != nil {
+			_ = s.limiter.Refund(ctx, quotaKey)
+			result.Quota.Remaining++
+		}
 	}
 
 	l := &like.Like{
 		FromUserID: fromUserID,
 		ToUserID:   input.TargetID,
 		IsLike:     input.IsLike,
+		Kind:       kind,
 	}
 
-	if err := s.repo.Upsert(ctx, l); err != nil {
-		return false, err
+	if kind == like.KindSuperLike {
+		now := time.Now()
+		sent, err := s.repo.SuperLike(ctx, l, startOfDay(now), s.superLikesPerDay)
+		if err != nil {
+			refund()
+			if errors.Is(err, repository.ErrSuperLikeLimitReached) {
+				resetAt := startOfDay(now).Add(24 * time.Hour)
+				return &LikeResult{SuperLikeResetAt: &resetAt}, ErrSuperLikeLimitReached
+			}
+			return nil, err
+		}
+		// The recipient hears about a super like straight away rather than
+		// only on a match. A failed notification does not undo the like.
+		if sent {
+			_ = s.notificationService.NotifySuperLike(ctx, input.TargetID)
+		} else {
+			refund()
+		}
+	} else if err := s.repo.Upsert(ctx, l); err != nil {
+		refund()
+		return nil, err
 	}
 
 	// If it's a pass (IsLike = false), it can't be a match.
 	if !input.IsLike {
-		return false, nil
+		return result, nil
 	}
 
 	// Publish match check to Kafka
@@ -74,32 +262,237 @@ func (s *likeService) LikeUser(ctx context.Context, fromUserID string, input Lik
 	if err := s.producer.Publish(ctx, msg); err != nil {
 		// Log error but don't fail the request
 		// In production, you might want to fallback to sync check or retry
-		return false, nil
+		return result, nil
 	}
 
-	return false, nil
+	return result, nil
+}
+
+// Rewind undoes userID's most recent like or pass if it is at most the
+// rewind window old and has not produced a match, and returns the profile so
+// it can go back into the feed. Spent like and super like quota is not
+// refunded.
+func (s *likeService) Rewind(ctx context.Context, userID string) (*RewindResult, error) {
+	l, err := s.repo.Rewind(ctx, userID, time.Now().Add(-s.rewindWindow))
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrNothingToRewind):
+			return nil, ErrNothingToRewind
+		case errors.Is(err, repository.ErrMatched):
+			return nil, ErrRewindMatched
+		}
+		return nil, err
+	}
+
+	result := &RewindResult{Kind: l.Kind}
+	if !l.IsLike {
+		result.Kind = "pass"
+	}
+
+	p, err := s.profileRepo.GetVisibleByUserID(ctx, userID, l.ToUserID)
+	if err != nil {
+		if errors.Is(err, profileRepo.ErrNotFound) {
+			return result, nil
+		}
+		return nil, err
+	}
+	viewer, err := s.profileRepo.GetByUserID(ctx, userID)
+	if err != nil && !errors.Is(err, profileRepo.ErrNotFound) {
+		return nil, err
+	}
+	p.ProjectFor(viewer)
+	result.Profile = p.Public()
+	return result, nil
 }
 
 func (s *likeService) ProcessMatchCheck(ctx context.Context, fromUserID, targetID string) error {
-	isMatch, err := s.repo.HasMutualLike(ctx, fromUserID, targetID)
+	// Only the check that creates the match notifies, so a pair liking each
+	// other at the same time is notified once.
+	created, err := s.repo.CreateMatch(ctx, fromUserID, targetID)
 	if err != nil {
 		return err
 	}
-	if isMatch {
+	if created {
 		return s.notificationService.NotifyMatch(ctx, fromUserID, targetID)
 	}
 	return nil
 }
 
-func (s *likeService) GetMatches(ctx context.Context, userID string) ([]*profile.Profile, error) {
-	matchIDs, err := s.repo.GetMatches(ctx, userID)
+// GetMatches returns a page of userID's matches, most recently active first.
+// cursor is empty for the first page, or a previous page's NextCursor.
+func (s *likeService) GetMatches(ctx context.Context, userID, cursor string, limit int) (*MatchPage, error) {
+	if limit <= 0 {
+		limit = DefaultMatchesLimit
+	}
+	limit = min(limit, MaxMatchesLimit)
+
+	var after *repository.MatchCursor
+	if cursor != "" {
+		c, err := decodeMatchCursor(cursor)
+		if err != nil {
+			return nil, err
+		}
+		after = c
+	}
+
+	// One extra row tells whether there is a next page.
+	matches, err := s.repo.ListMatches(ctx, userID, after, limit+1)
+	if err != nil {
+		return nil, err
+	}
+	page := &MatchPage{Matches: []*like.Match{}}
+	if len(matches) > limit {
+		matches = matches[:limit]
+		last := matches[limit-1]
+		page.NextCursor = encodeMatchCursor(&repository.MatchCursor{LastActivityAt: last.LastActivityAt, UserID: last.UserID})
+	}
+	if len(matches) == 0 {
+		return page, nil
+	}
+
+	matchIDs := make([]string, len(matches))
+	for i, m := range matches {
+		matchIDs[i] = m.UserID
+	}
+	profiles, err := s.profileRepo.GetByUserIDs(ctx, matchIDs)
+	if err != nil {
+		return nil, err
+	}
+	byUserID := make(map[string]*profile.Profile, len(profiles))
+	for _, p := range profiles {
+		byUserID[p.UserID] = p
+	}
+
+	// The viewer's own location is only needed for distance buckets; a
+	// missing profile just means no buckets are shown.
+	viewer, err := s.profileRepo.GetByUserID(ctx, userID)
+	if err != nil && !errors.Is(err, profileRepo.ErrNotFound) {
+		return nil, err
+	}
+	for _, m := range matches {
+		p, ok := byUserID[m.UserID]
+		if !ok {
+			// The other user has no profile any more.
+			continue
+		}
+		p.MatchedWithViewer = true
+		p.ProjectFor(viewer)
+		m.Profile = p.Public()
+		page.Matches = append(page.Matches, m)
+	}
+	return page, nil
+}
+
+// ListReceivedLikes returns a page of the likes userID has not answered yet,
+// newest first. Users without the entitlement get a locked preview.
+func (s *likeService) ListReceivedLikes(ctx context.Context, userID string, limit, offset int) (*ReceivedLikesPage, error) {
+	if limit <= 0 {
+		limit = DefaultReceivedLikesLimit
+	}
+	limit = min(limit, MaxReceivedLikesLimit)
+	offset = max(offset, 0)
+
+	entitled, err := s.entitlements.CanSeeReceivedLikes(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	total, err := s.repo.CountReceived(ctx, userID)
 	if err != nil {
 		return nil, err
 	}
+	likes, err := s.repo.ListReceived(ctx, userID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	page := &ReceivedLikesPage{Total: total, Locked: !entitled, Likes: []*like.ReceivedLike{}}
 
-	if len(matchIDs) == 0 {
-		return []*profile.Profile{}, nil
+	if !entitled {
+		for _, l := range likes {
+			page.Likes = append(page.Likes, &like.ReceivedLike{LikedAt: l.LikedAt})
+		}
+		return page, nil
+	}
+	if len(likes) == 0 {
+		return page, nil
 	}
 
-	return s.profileRepo.GetByUserIDs(ctx, matchIDs)
+	likerIDs := make([]string, len(likes))
+	for i, l := range likes {
+		likerIDs[i] = l.UserID
+	}
+	profiles, err := s.profileRepo.GetByUserIDs(ctx, likerIDs)
+	if err != nil {
+		return nil, err
+	}
+	byUserID := make(map[string]*profile.Profile, len(profiles))
+	for _, p := range profiles {
+		byUserID[p.UserID] = p
+	}
+	viewer, err := s.profileRepo.GetByUserID(ctx, userID)
+	if err != nil && !errors.Is(err, profileRepo.ErrNotFound) {
+		return nil, err
+	}
+	for _, l := range likes {
+		p, ok := byUserID[l.UserID]
+		if !ok {
+			continue
+		}
+		p.ProjectFor(viewer)
+		l.Profile = p.Public()
+		page.Likes = append(page.Likes, l)
+	}
+	return page, nil
+}
+
+// startOfDay returns midnight UTC at the start of t's day, when daily quotas
+// reset.
+func startOfDay(t time.Time) time.Time {
+	return t.UTC().Truncate(24 * time.Hour)
+}
+
+func encodeMatchCursor(c *repository.MatchCursor) string {
+	raw := strconv.FormatInt(c.LastActivityAt.UnixNano(), 10) + ":" + c.UserID
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeMatchCursor(cursor string) (*repository.MatchCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	nanos, userID, ok := strings.Cut(string(raw), ":")
+	if !ok || userID == "" {
+		return nil, ErrInvalidCursor
+	}
+	n, err := strconv.ParseInt(nanos, 10, 64)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	return &repository.MatchCursor{LastActivityAt: time.Unix(0, n).UTC(), UserID: userID}, nil
+}
+
+// Unmatch ends the match between userID and otherUserID for good: the pair
+// drops out of both match lists, their chat is closed and liking each other
+// again never produces a new match.
+func (s *likeService) Unmatch(ctx context.Context, userID, otherUserID string) error {
+	ok, err := s.repo.Unmatch(ctx, userID, otherUserID)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrNotMatched
+	}
+
+	if s.hub != nil {
+		// Close open chat windows on both sides, including the
+		// unmatching user's other sessions.
+		s.pushUnmatch(otherUserID, userID)
+		s.pushUnmatch(userID, otherUserID)
+	}
+	return nil
+}
+
+func (s *likeService) pushUnmatch(recipientID, otherUserID string) {
+	bytes, _ := json.Marshal(UnmatchEvent{Type: "unmatch", UserID: otherUserID})
+	s.hub.SendToUser(recipientID, bytes)
 }
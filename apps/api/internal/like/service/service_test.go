@@ -2,15 +2,22 @@ package service_test
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"testing"
 	"time"
 
 	"github.com/kisssonik/hearts/internal/like"
+	"github.com/kisssonik/hearts/internal/like/repository"
 	"github.com/kisssonik/hearts/internal/like/service"
 	"github.com/kisssonik/hearts/internal/notification"
 	"github.com/kisssonik/hearts/internal/profile"
+	profileRepo "github.com/kisssonik/hearts/internal/profile/repository"
+	"github.com/kisssonik/hearts/pkg/quota"
+	"github.com/kisssonik/hearts/pkg/websocket"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 // MockLikeRepository
@@ -23,14 +30,53 @@ func (m *MockLikeRepository) Upsert(ctx context.Context, l *like.Like) error {
 	return args.Error(0)
 }
 
-func (m *MockLikeRepository) HasMutualLike(ctx context.Context, user1ID, user2ID string) (bool, error) {
+func (m *MockLikeRepository) SuperLike(ctx context.Context, l *like.Like, since time.Time, limit int) (bool, error) {
+	args := m.Called(ctx, l, since, limit)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockLikeRepository) CreateMatch(ctx context.Context, user1ID, user2ID string) (bool, error) {
+	args := m.Called(ctx, user1ID, user2ID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockLikeRepository) IsMatched(ctx context.Context, user1ID, user2ID string) (bool, error) {
 	args := m.Called(ctx, user1ID, user2ID)
 	return args.Bool(0), args.Error(1)
 }
 
-func (m *MockLikeRepository) GetMatches(ctx context.Context, userID string) ([]string, error) {
+func (m *MockLikeRepository) ListMatches(ctx context.Context, userID string, after *repository.MatchCursor, limit int) ([]*like.Match, error) {
+	args := m.Called(ctx, userID, after, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*like.Match), args.Error(1)
+}
+
+func (m *MockLikeRepository) Unmatch(ctx context.Context, userID, otherUserID string) (bool, error) {
+	args := m.Called(ctx, userID, otherUserID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockLikeRepository) ListReceived(ctx context.Context, userID string, limit, offset int) ([]*like.ReceivedLike, error) {
+	args := m.Called(ctx, userID, limit, offset)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*like.ReceivedLike), args.Error(1)
+}
+
+func (m *MockLikeRepository) CountReceived(ctx context.Context, userID string) (int, error) {
 	args := m.Called(ctx, userID)
-	return args.Get(0).([]string), args.Error(1)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockLikeRepository) Rewind(ctx context.Context, userID string, since time.Time) (*like.Like, error) {
+	args := m.Called(ctx, userID, since)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*like.Like), args.Error(1)
 }
 
 // MockProfileRepository
@@ -59,11 +105,61 @@ func (m *MockProfileRepository) GetByUserIDs(ctx context.Context, userIDs []stri
 	return args.Get(0).([]*profile.Profile), args.Error(1)
 }
 
+func (m *MockProfileRepository) GetVisibleByUserID(ctx context.Context, viewerID, userID string) (*profile.Profile, error) {
+	args := m.Called(ctx, viewerID, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*profile.Profile), args.Error(1)
+}
+
 func (m *MockProfileRepository) Update(ctx context.Context, p *profile.Profile) error {
 	args := m.Called(ctx, p)
 	return args.Error(0)
 }
 
+func (m *MockProfileRepository) Search(ctx context.Context, currentUser *profile.Profile, params profileRepo.SearchParams) ([]*profile.Profile, error) {
+	args := m.Called(ctx, currentUser, params)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*profile.Profile), args.Error(1)
+}
+
+func (m *MockProfileRepository) SetPassport(ctx context.Context, userID string, passport *profile.Passport) error {
+	args := m.Called(ctx, userID, passport)
+	return args.Error(0)
+}
+
+func (m *MockProfileRepository) ListRevisions(ctx context.Context, userID string, limit, offset int) ([]*profile.Revision, error) {
+	args := m.Called(ctx, userID, limit, offset)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*profile.Revision), args.Error(1)
+}
+
+func (m *MockProfileRepository) GetRevision(ctx context.Context, userID string, version int) (*profile.Revision, error) {
+	args := m.Called(ctx, userID, version)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*profile.Revision), args.Error(1)
+}
+
+func (m *MockProfileRepository) RecordView(ctx context.Context, viewerID, viewedID string) (bool, error) {
+	args := m.Called(ctx, viewerID, viewedID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockProfileRepository) ListViewers(ctx context.Context, userID string, limit, offset int) ([]*profile.View, error) {
+	args := m.Called(ctx, userID, limit, offset)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*profile.View), args.Error(1)
+}
+
 // MockNotificationService
 type MockNotificationService struct {
 	mock.Mock
@@ -74,6 +170,16 @@ func (m *MockNotificationService) NotifyMatch(ctx context.Context, user1ID, user
 	return args.Error(0)
 }
 
+func (m *MockNotificationService) NotifyProfileView(ctx context.Context, userID string) error {
+	args := m.Called(ctx, userID)
+	return args.Error(0)
+}
+
+func (m *MockNotificationService) NotifySuperLike(ctx context.Context, userID string) error {
+	args := m.Called(ctx, userID)
+	return args.Error(0)
+}
+
 func (m *MockNotificationService) GetNotifications(ctx context.Context, userID string) ([]*notification.Notification, error) {
 	args := m.Called(ctx, userID)
 	if args.Get(0) == nil {
@@ -87,68 +193,233 @@ func (m *MockNotificationService) MarkAsRead(ctx context.Context, notificationID
 	return args.Error(0)
 }
 
+// MockProducer
+type MockProducer struct {
+	mock.Mock
+}
+
+func (m *MockProducer) Publish(ctx context.Context, message interface{}) error {
+	args := m.Called(ctx, message)
+	return args.Error(0)
+}
+
+func (m *MockProducer) Close() error {
+	args := m.Called()
+	return args.Error(0)
+}
+
 func TestLikeService_LikeUser(t *testing.T) {
 	ctx := context.Background()
 	fromID := "u1"
 	toID := "u2"
 
-	t.Run("Like, no mutual", func(t *testing.T) {
+	t.Run("Like publishes match check", func(t *testing.T) {
 		mockRepo := new(MockLikeRepository)
 		mockProfileRepo := new(MockProfileRepository)
 		mockNotifService := new(MockNotificationService)
-		s := service.NewLikeService(mockRepo, mockProfileRepo, mockNotifService)
+		mockProducer := new(MockProducer)
+		s := service.NewLikeService(mockRepo, mockProfileRepo, mockNotifService, mockProducer, nil, service.Config{})
 
 		mockRepo.On("Upsert", ctx, mock.MatchedBy(func(l *like.Like) bool {
 			return l.FromUserID == fromID && l.ToUserID == toID && l.IsLike == true
 		})).Return(nil)
-		// HasMutualLike is called in goroutine, so we use mock.Anything for context
-		mockRepo.On("HasMutualLike", mock.Anything, fromID, toID).Return(false, nil)
+		mockProducer.On("Publish", ctx, service.MatchCheckMessage{FromUserID: fromID, TargetID: toID}).Return(nil)
 
-		isMatch, err := s.LikeUser(ctx, fromID, service.LikeInput{TargetID: toID, IsLike: true})
+		result, err := s.LikeUser(ctx, fromID, service.LikeInput{TargetID: toID, IsLike: true})
 		assert.NoError(t, err)
-		assert.False(t, isMatch) // Always false now
+		assert.False(t, result.IsMatch) // Matches are resolved asynchronously
+		if assert.NotNil(t, result.Quota) {
+			assert.Equal(t, service.DefaultLikeLimit.Max-1, result.Quota.Remaining)
+		}
 
-		// Wait for goroutine
-		time.Sleep(100 * time.Millisecond)
 		mockRepo.AssertExpectations(t)
+		mockProducer.AssertExpectations(t)
 	})
 
-	t.Run("Like, mutual", func(t *testing.T) {
+	t.Run("Pass", func(t *testing.T) {
 		mockRepo := new(MockLikeRepository)
 		mockProfileRepo := new(MockProfileRepository)
 		mockNotifService := new(MockNotificationService)
-		s := service.NewLikeService(mockRepo, mockProfileRepo, mockNotifService)
+		mockProducer := new(MockProducer)
+		s := service.NewLikeService(mockRepo, mockProfileRepo, mockNotifService, mockProducer, nil, service.Config{})
 
 		mockRepo.On("Upsert", ctx, mock.MatchedBy(func(l *like.Like) bool {
-			return l.FromUserID == fromID && l.ToUserID == toID && l.IsLike == true
+			return l.FromUserID == fromID && l.ToUserID == toID && l.IsLike == false
 		})).Return(nil)
-		mockRepo.On("HasMutualLike", mock.Anything, fromID, toID).Return(true, nil)
-		mockNotifService.On("NotifyMatch", mock.Anything, fromID, toID).Return(nil)
 
-		isMatch, err := s.LikeUser(ctx, fromID, service.LikeInput{TargetID: toID, IsLike: true})
+		result, err := s.LikeUser(ctx, fromID, service.LikeInput{TargetID: toID, IsLike: false})
 		assert.NoError(t, err)
-		assert.False(t, isMatch) // Always false now
+		assert.False(t, result.IsMatch)
+		assert.Nil(t, result.Quota) // Passes are not limited
+		mockRepo.AssertExpectations(t)
+		mockProducer.AssertNotCalled(t, "Publish")
+	})
+
+	t.Run("Self like", func(t *testing.T) {
+		s := service.NewLikeService(new(MockLikeRepository), new(MockProfileRepository), new(MockNotificationService), new(MockProducer), nil, service.Config{})
+
+		_, err := s.LikeUser(ctx, fromID, service.LikeInput{TargetID: fromID, IsLike: true})
+		assert.ErrorIs(t, err, service.ErrSelfLike)
+	})
+}
+
+func TestLikeService_LikeUser_Limit(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(MockLikeRepository)
+	mockProducer := new(MockProducer)
+	s := service.NewLikeService(mockRepo, new(MockProfileRepository), new(MockNotificationService), mockProducer, nil, service.Config{
+		Limiter:   quota.NewMemoryLimiter(),
+		LikeLimit: quota.Limit{Max: 2, Window: time.Hour},
+	})
+
+	mockRepo.On("Upsert", ctx, mock.Anything).Return(nil)
+	mockProducer.On("Publish", ctx, mock.Anything).Return(nil)
+
+	result, err := s.LikeUser(ctx, "u1", service.LikeInput{TargetID: "u2", IsLike: true})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, result.Quota.Remaining)
+	result, err = s.LikeUser(ctx, "u1", service.LikeInput{TargetID: "u3", IsLike: true})
+	assert.NoError(t, err)
+	assert.Equal(t, 0, result.Quota.Remaining)
+
+	result, err = s.LikeUser(ctx, "u1", service.LikeInput{TargetID: "u4", IsLike: true})
+	assert.ErrorIs(t, err, service.ErrLikeLimitReached)
+	if assert.NotNil(t, result) && assert.NotNil(t, result.Quota) {
+		assert.WithinDuration(t, time.Now().Add(time.Hour), result.Quota.ResetAt, time.Minute)
+	}
+	mockRepo.AssertNumberOfCalls(t, "Upsert", 2)
+
+	// Passes still go through.
+	_, err = s.LikeUser(ctx, "u1", service.LikeInput{TargetID: "u4", IsLike: false})
+	assert.NoError(t, err)
+
+	// Other users have their own quota.
+	_, err = s.LikeUser(ctx, "u9", service.LikeInput{TargetID: "u4", IsLike: true})
+	assert.NoError(t, err)
+}
+
+func TestLikeService_LikeUser_RefusedLikesKeepQuota(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(MockLikeRepository)
+	mockProducer := new(MockProducer)
+	s := service.NewLikeService(mockRepo, new(MockProfileRepository), new(MockNotificationService), mockProducer, nil, service.Config{
+		Limiter:   quota.NewMemoryLimiter(),
+		LikeLimit: quota.Limit{Max: 2, Window: time.Hour},
+	})
+	mockRepo.On("SuperLike", ctx, mock.MatchedBy(func(l *like.Like) bool { return l.ToUserID == "limited" }), mock.Anything, mock.Anything).Return(false, repository.ErrSuperLikeLimitReached)
+	mockRepo.On("SuperLike", ctx, mock.MatchedBy(func(l *like.Like) bool { return l.ToUserID == "repeat" }), mock.Anything, mock.Anything).Return(false, nil)
+	mockRepo.On("Upsert", ctx, mock.MatchedBy(func(l *like.Like) bool { return l.ToUserID == "missing" })).Return(errors.New("foreign key violation"))
+	mockRepo.On("Upsert", ctx, mock.Anything).Return(nil)
+	mockProducer.On("Publish", ctx, mock.Anything).Return(nil)
+
+	result, err := s.LikeUser(ctx, "u1", service.LikeInput{TargetID: "limited", IsLike: true, Kind: like.KindSuperLike})
+	assert.ErrorIs(t, err, service.ErrSuperLikeLimitReached)
+	if assert.NotNil(t, result) && assert.NotNil(t, result.SuperLikeResetAt) {
+		assert.Equal(t, time.Now().UTC().Truncate(24*time.Hour).Add(24*time.Hour), *result.SuperLikeResetAt)
+	}
+
+	result, err = s.LikeUser(ctx, "u1", service.LikeInput{TargetID: "repeat", IsLike: true, Kind: like.KindSuperLike})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, result.Quota.Remaining)
+
+	_, err = s.LikeUser(ctx, "u1", service.LikeInput{TargetID: "missing", IsLike: true})
+	assert.Error(t, err)
 
-		// Wait for goroutine
-		time.Sleep(100 * time.Millisecond)
+	// None of the above spent quota.
+	result, err = s.LikeUser(ctx, "u1", service.LikeInput{TargetID: "u2", IsLike: true})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, result.Quota.Remaining)
+}
+
+func TestLikeService_LikeUser_SuperLike(t *testing.T) {
+	ctx := context.Background()
+	input := service.LikeInput{TargetID: "u2", IsLike: true, Kind: like.KindSuperLike}
+	isSuperLike := mock.MatchedBy(func(l *like.Like) bool {
+		return l.FromUserID == "u1" && l.ToUserID == "u2" && l.IsLike && l.Kind == like.KindSuperLike
+	})
+	startOfDay := mock.MatchedBy(func(since time.Time) bool {
+		return since.Equal(time.Now().UTC().Truncate(24 * time.Hour))
+	})
+
+	t.Run("Notifies the recipient and checks for a match", func(t *testing.T) {
+		mockRepo := new(MockLikeRepository)
+		mockNotifService := new(MockNotificationService)
+		mockProducer := new(MockProducer)
+		s := service.NewLikeService(mockRepo, new(MockProfileRepository), mockNotifService, mockProducer, nil, service.Config{SuperLikesPerDay: 3})
+
+		mockRepo.On("SuperLike", ctx, isSuperLike, startOfDay, 3).Return(true, nil)
+		mockNotifService.On("NotifySuperLike", ctx, "u2").Return(nil)
+		mockProducer.On("Publish", ctx, service.MatchCheckMessage{FromUserID: "u1", TargetID: "u2"}).Return(nil)
+
+		_, err := s.LikeUser(ctx, "u1", input)
+		assert.NoError(t, err)
 		mockRepo.AssertExpectations(t)
 		mockNotifService.AssertExpectations(t)
+		mockProducer.AssertExpectations(t)
 	})
 
-	t.Run("Pass", func(t *testing.T) {
+	t.Run("Repeat super like does not notify again", func(t *testing.T) {
 		mockRepo := new(MockLikeRepository)
-		mockProfileRepo := new(MockProfileRepository)
 		mockNotifService := new(MockNotificationService)
-		s := service.NewLikeService(mockRepo, mockProfileRepo, mockNotifService)
+		mockProducer := new(MockProducer)
+		s := service.NewLikeService(mockRepo, new(MockProfileRepository), mockNotifService, mockProducer, nil, service.Config{})
 
-		mockRepo.On("Upsert", ctx, mock.MatchedBy(func(l *like.Like) bool {
-			return l.FromUserID == fromID && l.ToUserID == toID && l.IsLike == false
-		})).Return(nil)
+		mockRepo.On("SuperLike", ctx, isSuperLike, startOfDay, service.DefaultSuperLikesPerDay).Return(false, nil)
+		mockProducer.On("Publish", ctx, mock.Anything).Return(nil)
 
-		isMatch, err := s.LikeUser(ctx, fromID, service.LikeInput{TargetID: toID, IsLike: false})
+		_, err := s.LikeUser(ctx, "u1", input)
 		assert.NoError(t, err)
-		assert.False(t, isMatch)
-		mockRepo.AssertExpectations(t)
+		mockNotifService.AssertNotCalled(t, "NotifySuperLike", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Quota used up", func(t *testing.T) {
+		mockRepo := new(MockLikeRepository)
+		mockProducer := new(MockProducer)
+		s := service.NewLikeService(mockRepo, new(MockProfileRepository), new(MockNotificationService), mockProducer, nil, service.Config{})
+
+		mockRepo.On("SuperLike", ctx, isSuperLike, startOfDay, service.DefaultSuperLikesPerDay).Return(false, repository.ErrSuperLikeLimitReached)
+
+		_, err := s.LikeUser(ctx, "u1", input)
+		assert.ErrorIs(t, err, service.ErrSuperLikeLimitReached)
+		mockProducer.AssertNotCalled(t, "Publish")
+	})
+
+	t.Run("Invalid kind", func(t *testing.T) {
+		s := service.NewLikeService(new(MockLikeRepository), new(MockProfileRepository), new(MockNotificationService), new(MockProducer), nil, service.Config{})
+
+		_, err := s.LikeUser(ctx, "u1", service.LikeInput{TargetID: "u2", IsLike: true, Kind: "megalike"})
+		assert.ErrorIs(t, err, service.ErrInvalidKind)
+		_, err = s.LikeUser(ctx, "u1", service.LikeInput{TargetID: "u2", IsLike: false, Kind: like.KindSuperLike})
+		assert.ErrorIs(t, err, service.ErrInvalidKind)
+	})
+}
+
+func TestLikeService_ProcessMatchCheck(t *testing.T) {
+	ctx := context.Background()
+	fromID := "u1"
+	toID := "u2"
+
+	t.Run("No new match", func(t *testing.T) {
+		mockRepo := new(MockLikeRepository)
+		mockNotifService := new(MockNotificationService)
+		s := service.NewLikeService(mockRepo, new(MockProfileRepository), mockNotifService, new(MockProducer), nil, service.Config{})
+
+		mockRepo.On("CreateMatch", ctx, fromID, toID).Return(false, nil)
+
+		assert.NoError(t, s.ProcessMatchCheck(ctx, fromID, toID))
+		mockNotifService.AssertNotCalled(t, "NotifyMatch")
+	})
+
+	t.Run("Mutual like", func(t *testing.T) {
+		mockRepo := new(MockLikeRepository)
+		mockNotifService := new(MockNotificationService)
+		s := service.NewLikeService(mockRepo, new(MockProfileRepository), mockNotifService, new(MockProducer), nil, service.Config{})
+
+		mockRepo.On("CreateMatch", ctx, fromID, toID).Return(true, nil)
+		mockNotifService.On("NotifyMatch", ctx, fromID, toID).Return(nil)
+
+		assert.NoError(t, s.ProcessMatchCheck(ctx, fromID, toID))
+		mockNotifService.AssertExpectations(t)
 	})
 }
 
@@ -156,21 +427,198 @@ func TestLikeService_GetMatches(t *testing.T) {
 	mockRepo := new(MockLikeRepository)
 	mockProfileRepo := new(MockProfileRepository)
 	mockNotifService := new(MockNotificationService)
-	s := service.NewLikeService(mockRepo, mockProfileRepo, mockNotifService)
+	s := service.NewLikeService(mockRepo, mockProfileRepo, mockNotifService, new(MockProducer), nil, service.Config{})
 
 	ctx := context.Background()
 	userID := "u1"
-	matchIDs := []string{"u2", "u3"}
+	lat, lon := 52.52, 13.40
+	fuzzedLat, fuzzedLon := 52.53, 13.41
+	lastMessage := "See you there!"
+	activity := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	matches := []*like.Match{
+		{UserID: "u2", LastMessage: &lastMessage, LastActivityAt: activity, UnreadCount: 2},
+		{UserID: "u3", LastActivityAt: activity.Add(-time.Hour)},
+		{UserID: "u4", LastActivityAt: activity.Add(-2 * time.Hour)},
+	}
 	profiles := []*profile.Profile{
-		{UserID: "u2", FirstName: "User 2"},
 		{UserID: "u3", FirstName: "User 3"},
+		{UserID: "u2", FirstName: "User 2", Latitude: &lat, Longitude: &lon, FuzzedLatitude: &fuzzedLat, FuzzedLongitude: &fuzzedLon},
 	}
+	viewer := &profile.Profile{UserID: userID, Latitude: &lat, Longitude: &lon}
 
-	mockRepo.On("GetMatches", ctx, userID).Return(matchIDs, nil)
-	mockProfileRepo.On("GetByUserIDs", ctx, matchIDs).Return(profiles, nil)
+	// Asks for one more than the page size to detect a next page.
+	mockRepo.On("ListMatches", ctx, userID, (*repository.MatchCursor)(nil), 3).Return(matches, nil)
+	mockProfileRepo.On("GetByUserIDs", ctx, []string{"u2", "u3"}).Return(profiles, nil)
+	mockProfileRepo.On("GetByUserID", ctx, userID).Return(viewer, nil)
 
-	result, err := s.GetMatches(ctx, userID)
+	page, err := s.GetMatches(ctx, userID, "", 2)
 	assert.NoError(t, err)
-	assert.Len(t, result, 2)
-	assert.Equal(t, "User 2", result[0].FirstName)
+	assert.Len(t, page.Matches, 2)
+	assert.Equal(t, "User 2", page.Matches[0].Profile.FirstName)
+	assert.Equal(t, 2, page.Matches[0].UnreadCount)
+	// Only the public projection is returned.
+	encoded, err := json.Marshal(page.Matches[0].Profile)
+	require.NoError(t, err)
+	for _, field := range []string{"latitude", "longitude", "birthDate", "visibility", "createdAt", "updatedAt"} {
+		assert.NotContains(t, string(encoded), `"`+field+`"`)
+	}
+	if assert.NotNil(t, page.Matches[0].Profile.DistanceBucket) {
+		assert.Equal(t, "< 5 km", *page.Matches[0].Profile.DistanceBucket)
+	}
+	assert.Equal(t, "User 3", page.Matches[1].Profile.FirstName)
+	assert.NotEmpty(t, page.NextCursor)
+
+	// The cursor resumes after the last match of the page.
+	mockRepo.On("ListMatches", ctx, userID, &repository.MatchCursor{LastActivityAt: matches[1].LastActivityAt, UserID: "u3"}, 3).
+		Return([]*like.Match{}, nil)
+
+	page, err = s.GetMatches(ctx, userID, page.NextCursor, 2)
+	assert.NoError(t, err)
+	assert.Empty(t, page.Matches)
+	assert.Empty(t, page.NextCursor)
+}
+
+func TestLikeService_GetMatches_InvalidCursor(t *testing.T) {
+	s := service.NewLikeService(new(MockLikeRepository), new(MockProfileRepository), new(MockNotificationService), new(MockProducer), nil, service.Config{})
+
+	_, err := s.GetMatches(context.Background(), "u1", "not a cursor", 20)
+	assert.ErrorIs(t, err, service.ErrInvalidCursor)
+}
+
+func TestLikeService_Unmatch(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Matched", func(t *testing.T) {
+		mockRepo := new(MockLikeRepository)
+		s := service.NewLikeService(mockRepo, new(MockProfileRepository), new(MockNotificationService), new(MockProducer), websocket.NewHub(), service.Config{})
+
+		mockRepo.On("Unmatch", ctx, "u1", "u2").Return(true, nil)
+
+		assert.NoError(t, s.Unmatch(ctx, "u1", "u2"))
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Not matched", func(t *testing.T) {
+		mockRepo := new(MockLikeRepository)
+		s := service.NewLikeService(mockRepo, new(MockProfileRepository), new(MockNotificationService), new(MockProducer), nil, service.Config{})
+
+		mockRepo.On("Unmatch", ctx, "u1", "u2").Return(false, nil)
+
+		assert.ErrorIs(t, s.Unmatch(ctx, "u1", "u2"), service.ErrNotMatched)
+	})
+}
+
+func TestLikeService_ListReceivedLikes(t *testing.T) {
+	ctx := context.Background()
+	userID := "u1"
+	likedAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	received := func() []*like.ReceivedLike {
+		return []*like.ReceivedLike{
+			{UserID: "u2", LikedAt: likedAt},
+			{UserID: "u3", LikedAt: likedAt.Add(-time.Hour)},
+		}
+	}
+
+	t.Run("Full list", func(t *testing.T) {
+		mockRepo := new(MockLikeRepository)
+		mockProfileRepo := new(MockProfileRepository)
+		s := service.NewLikeService(mockRepo, mockProfileRepo, new(MockNotificationService), new(MockProducer), nil, service.Config{
+			Entitlements: service.StaticEntitlements{ReceivedLikes: true},
+		})
+
+		height := 180
+		mockRepo.On("CountReceived", ctx, userID).Return(2, nil)
+		mockRepo.On("ListReceived", ctx, userID, service.DefaultReceivedLikesLimit, 0).Return(received(), nil)
+		mockProfileRepo.On("GetByUserIDs", ctx, []string{"u2", "u3"}).Return([]*profile.Profile{
+			{UserID: "u2", FirstName: "User 2", Height: &height, FieldVisibility: map[string]string{profile.FieldHeight: profile.PrivacyMatches}},
+			{UserID: "u3", FirstName: "User 3"},
+		}, nil)
+		mockProfileRepo.On("GetByUserID", ctx, userID).Return(&profile.Profile{UserID: userID}, nil)
+
+		page, err := s.ListReceivedLikes(ctx, userID, 0, -5)
+		assert.NoError(t, err)
+		assert.False(t, page.Locked)
+		assert.Equal(t, 2, page.Total)
+		assert.Len(t, page.Likes, 2)
+		assert.Equal(t, "User 2", page.Likes[0].Profile.FirstName)
+		// Not a match yet, so "matches" fields stay hidden.
+		assert.Nil(t, page.Likes[0].Profile.Height)
+	})
+
+	t.Run("Preview", func(t *testing.T) {
+		mockRepo := new(MockLikeRepository)
+		mockProfileRepo := new(MockProfileRepository)
+		s := service.NewLikeService(mockRepo, mockProfileRepo, new(MockNotificationService), new(MockProducer), nil, service.Config{})
+
+		mockRepo.On("CountReceived", ctx, userID).Return(7, nil)
+		mockRepo.On("ListReceived", ctx, userID, 2, 0).Return(received(), nil)
+
+		page, err := s.ListReceivedLikes(ctx, userID, 2, 0)
+		assert.NoError(t, err)
+		assert.True(t, page.Locked)
+		assert.Equal(t, 7, page.Total)
+		if assert.Len(t, page.Likes, 2) {
+			assert.Empty(t, page.Likes[0].UserID)
+			assert.Nil(t, page.Likes[0].Profile)
+			assert.Equal(t, likedAt, page.Likes[0].LikedAt)
+		}
+		mockProfileRepo.AssertNotCalled(t, "GetByUserIDs", mock.Anything, mock.Anything)
+	})
+}
+
+func TestLikeService_Rewind(t *testing.T) {
+	ctx := context.Background()
+	withinWindow := mock.MatchedBy(func(since time.Time) bool {
+		return time.Since(since) > 9*time.Minute && time.Since(since) < 11*time.Minute
+	})
+
+	t.Run("Returns the profile", func(t *testing.T) {
+		mockRepo := new(MockLikeRepository)
+		mockProfileRepo := new(MockProfileRepository)
+		s := service.NewLikeService(mockRepo, mockProfileRepo, new(MockNotificationService), new(MockProducer), nil, service.Config{RewindWindow: 10 * time.Minute})
+
+		mockRepo.On("Rewind", ctx, "u1", withinWindow).Return(&like.Like{FromUserID: "u1", ToUserID: "u2", IsLike: false, Kind: like.KindLike}, nil)
+		mockProfileRepo.On("GetVisibleByUserID", ctx, "u1", "u2").Return(&profile.Profile{UserID: "u2", FirstName: "User 2"}, nil)
+		mockProfileRepo.On("GetByUserID", ctx, "u1").Return(&profile.Profile{UserID: "u1"}, nil)
+
+		result, err := s.Rewind(ctx, "u1")
+		assert.NoError(t, err)
+		assert.Equal(t, "pass", result.Kind)
+		if assert.NotNil(t, result.Profile) {
+			assert.Equal(t, "u2", result.Profile.UserID)
+		}
+	})
+
+	t.Run("Profile no longer visible", func(t *testing.T) {
+		mockRepo := new(MockLikeRepository)
+		mockProfileRepo := new(MockProfileRepository)
+		s := service.NewLikeService(mockRepo, mockProfileRepo, new(MockNotificationService), new(MockProducer), nil, service.Config{})
+
+		mockRepo.On("Rewind", ctx, "u1", mock.Anything).Return(&like.Like{FromUserID: "u1", ToUserID: "u2", IsLike: true, Kind: like.KindSuperLike}, nil)
+		mockProfileRepo.On("GetVisibleByUserID", ctx, "u1", "u2").Return(nil, profileRepo.ErrNotFound)
+
+		result, err := s.Rewind(ctx, "u1")
+		assert.NoError(t, err)
+		assert.Equal(t, like.KindSuperLike, result.Kind)
+		assert.Nil(t, result.Profile)
+	})
+
+	for _, tt := range []struct {
+		name    string
+		repoErr error
+		wantErr error
+	}{
+		{"Nothing to rewind", repository.ErrNothingToRewind, service.ErrNothingToRewind},
+		{"Matched", repository.ErrMatched, service.ErrRewindMatched},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockLikeRepository)
+			s := service.NewLikeService(mockRepo, new(MockProfileRepository), new(MockNotificationService), new(MockProducer), nil, service.Config{})
+
+			mockRepo.On("Rewind", ctx, "u1", mock.Anything).Return(nil, tt.repoErr)
+
+			_, err := s.Rewind(ctx, "u1")
+			assert.ErrorIs(t, err, tt.wantErr)
+		})
+	}
 }
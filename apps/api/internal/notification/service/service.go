@@ -12,6 +12,8 @@ import (
 
 type NotificationService interface {
 	NotifyMatch(ctx context.Context, user1ID, user2ID string) error
+	NotifyProfileView(ctx context.Context, userID string) error
+	NotifySuperLike(ctx context.Context, userID string) error
 	GetNotifications(ctx context.Context, userID string) ([]*notification.Notification, error)
 	MarkAsRead(ctx context.Context, notificationID string) error
 }
@@ -54,6 +56,38 @@ func (s *notificationService) NotifyMatch(ctx context.Context, user1ID, user2ID
 	return nil
 }
 
+// NotifyProfileView tells userID that someone looked at their profile. The
+// viewer is not named; the viewers list shows who it was.
+func (s *notificationService) NotifyProfileView(ctx context.Context, userID string) error {
+	n := &notification.Notification{
+		UserID:  userID,
+		Type:    "profile_view",
+		Message: "Someone viewed your profile",
+	}
+	if err := s.repo.Create(ctx, n); err != nil {
+		return err
+	}
+	s.sendToWS(userID, n)
+	s.sendToKafka(userID, n)
+	return nil
+}
+
+// NotifySuperLike tells userID that someone super liked them. The sender
+// shows up first in userID's recommendations.
+func (s *notificationService) NotifySuperLike(ctx context.Context, userID string) error {
+	n := &notification.Notification{
+		UserID:  userID,
+		Type:    "superlike",
+		Message: "Someone super liked you!",
+	}
+	if err := s.repo.Create(ctx, n); err != nil {
+		return err
+	}
+	s.sendToWS(userID, n)
+	s.sendToKafka(userID, n)
+	return nil
+}
+
 func (s *notificationService) sendToWS(userID string, n *notification.Notification) {
 	if s.hub == nil {
 		return
@@ -0,0 +1,420 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/kisssonik/hearts/internal/photo"
+	"github.com/kisssonik/hearts/internal/photo/repository"
+	"github.com/kisssonik/hearts/internal/photo/service"
+	"github.com/kisssonik/hearts/pkg/auth"
+	"go.uber.org/zap"
+)
+
+// ReorderInput lists every photo ID in the desired order.
+type ReorderInput struct {
+	PhotoIDs []string `json:"photoIds"`
+}
+
+// UploadPhotoResponse is the legacy upload response of POST /profiles/upload.
+type UploadPhotoResponse struct {
+	Key string `json:"key"`
+	URL string `json:"url"`
+}
+
+// maxRequestBytes bounds the whole multipart request body.
+const maxRequestBytes = 32 << 20
+
+type PhotoHandler struct {
+	service service.PhotoService
+	logger  *zap.Logger
+}
+
+func NewPhotoHandler(s service.PhotoService, l *zap.Logger) *PhotoHandler {
+	return &PhotoHandler{service: s, logger: l}
+}
+
+// Upload handles photo upload.
+// @Summary Upload a photo
+// @Description Upload a photo and append it to the authenticated user's profile.
+// @Description The image is re-encoded as JPEG in thumb, medium and full sizes with all metadata removed.
+// @Description The first photo uploaded becomes the primary photo. Photos are shown to others once approved by moderation.
+// @Tags photos
+// @Accept multipart/form-data
+// @Produce json
+// @Security ApiKeyAuth
+// @Param photo formData file true "Photo file"
+// @Success 201 {object} photo.Photo
+// @Failure 400 {string} string "Invalid file or dimensions"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 409 {string} string "Photo limit reached"
+// @Failure 413 {string} string "File too large"
+// @Failure 415 {string} string "Unsupported image type"
+// @Failure 500 {string} string "Internal server error"
+// @Router /profiles/me/photos [post]
+func (h *PhotoHandler) Upload(w http.ResponseWriter, r *http.Request) {
+	p, ok := h.upload(w, r)
+	if !ok {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(p)
+}
+
+// UploadLegacy handles photo upload for older clients.
+// @Summary Upload a photo (deprecated)
+// @Description Same as POST /profiles/me/photos but responds with the storage key and URL.
+// @Description The photo is attached to the profile on upload; sending keys in the profile body is no longer needed.
+// @Tags photos
+// @Accept multipart/form-data
+// @Produce json
+// @Security ApiKeyAuth
+// @Param photo formData file true "Photo file"
+// @Success 200 {object} UploadPhotoResponse
+// @Failure 400 {string} string "Invalid file or dimensions"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 409 {string} string "Photo limit reached"
+// @Failure 413 {string} string "File too large"
+// @Failure 415 {string} string "Unsupported image type"
+// @Failure 500 {string} string "Internal server error"
+// @Deprecated
+// @Router /profiles/upload [post]
+func (h *PhotoHandler) UploadLegacy(w http.ResponseWriter, r *http.Request) {
+	p, ok := h.upload(w, r)
+	if !ok {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(UploadPhotoResponse{
+		Key: p.StorageKey,
+		URL: p.URL,
+	})
+}
+
+// upload reads the multipart file and stores it. On failure it writes the
+// error response and returns false.
+func (h *PhotoHandler) upload(w http.ResponseWriter, r *http.Request) (*photo.Photo, bool) {
+	userID, ok := r.Context().Value(auth.UserIDKey).(string)
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return nil, false
+	}
+
+	// Hard cap on the request body; the per-file limit is enforced by the
+	// service.
+	r.Body = http.MaxBytesReader(w, r.Body, maxRequestBytes)
+	if err := r.ParseMultipartForm(10 << 20); err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			http.Error(w, service.ErrFileTooLarge.Error(), http.StatusRequestEntityTooLarge)
+			return nil, false
+		}
+		h.logger.Warn("Failed to parse multipart form", zap.Error(err))
+		http.Error(w, "Invalid multipart form", http.StatusBadRequest)
+		return nil, false
+	}
+
+	file, _, err := r.FormFile("photo")
+	if err != nil {
+		h.logger.Error("Failed to get file from form", zap.Error(err))
+		http.Error(w, "Invalid file", http.StatusBadRequest)
+		return nil, false
+	}
+	defer file.Close()
+
+	p, err := h.service.Upload(r.Context(), userID, file)
+	if err != nil {
+		if status, ok := uploadStatus(err); ok {
+			http.Error(w, err.Error(), status)
+			return nil, false
+		}
+		h.logger.Error("Failed to upload photo", zap.Error(err))
+		http.Error(w, "Failed to upload file", http.StatusInternalServerError)
+		return nil, false
+	}
+	return p, true
+}
+
+// uploadStatus maps upload validation errors to an HTTP status.
+func uploadStatus(err error) (int, bool) {
+	switch {
+	case errors.Is(err, service.ErrFileTooLarge):
+		return http.StatusRequestEntityTooLarge, true
+	case errors.Is(err, service.ErrUnsupportedType):
+		return http.StatusUnsupportedMediaType, true
+	case errors.Is(err, service.ErrInvalidImage),
+		errors.Is(err, service.ErrImageTooSmall),
+		errors.Is(err, service.ErrImageTooLarge):
+		return http.StatusBadRequest, true
+	case errors.Is(err, repository.ErrLimitReached):
+		return http.StatusConflict, true
+	}
+	return 0, false
+}
+
+// List handles listing the authenticated user's photos.
+// @Summary List my photos
+// @Description List the authenticated user's photos in display order, including upload status.
+// @Tags photos
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {array} photo.Photo
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 500 {string} string "Internal server error"
+// @Router /profiles/me/photos [get]
+func (h *PhotoHandler) List(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(auth.UserIDKey).(string)
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	photos, err := h.service.List(r.Context(), userID)
+	if err != nil {
+		h.logger.Error("Failed to list photos", zap.Error(err))
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	h.writePhotos(w, photos)
+}
+
+// Reorder handles changing the order of the authenticated user's photos.
+// @Summary Reorder photos
+// @Description Set the display order of the authenticated user's photos. Every photo must be listed exactly once.
+// @Tags photos
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param input body ReorderInput true "Photo IDs in the desired order"
+// @Success 200 {array} photo.Photo
+// @Failure 400 {string} string "Invalid order"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 500 {string} string "Internal server error"
+// @Router /profiles/me/photos/order [put]
+func (h *PhotoHandler) Reorder(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(auth.UserIDKey).(string)
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var input ReorderInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		h.logger.Error("Failed to decode request body", zap.Error(err))
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	photos, err := h.service.Reorder(r.Context(), userID, input.PhotoIDs)
+	if err != nil {
+		if errors.Is(err, repository.ErrInvalidOrder) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		h.logger.Error("Failed to reorder photos", zap.Error(err))
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	h.writePhotos(w, photos)
+}
+
+// SetPrimary handles choosing the primary photo.
+// @Summary Set primary photo
+// @Description Make a ready photo the authenticated user's primary photo.
+// @Tags photos
+// @Produce json
+// @Security ApiKeyAuth
+// @Param photoID path string true "Photo ID"
+// @Success 200 {array} photo.Photo
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 404 {string} string "Photo not found"
+// @Failure 500 {string} string "Internal server error"
+// @Router /profiles/me/photos/{photoID}/primary [put]
+func (h *PhotoHandler) SetPrimary(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(auth.UserIDKey).(string)
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	photos, err := h.service.SetPrimary(r.Context(), userID, r.PathValue("photoID"))
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			http.Error(w, "Photo not found", http.StatusNotFound)
+			return
+		}
+		h.logger.Error("Failed to set primary photo", zap.Error(err))
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	h.writePhotos(w, photos)
+}
+
+// Delete handles removing a photo.
+// @Summary Delete a photo
+// @Description Delete one of the authenticated user's photos and remove it from storage.
+// @Tags photos
+// @Security ApiKeyAuth
+// @Param photoID path string true "Photo ID"
+// @Success 204
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 404 {string} string "Photo not found"
+// @Failure 500 {string} string "Internal server error"
+// @Router /profiles/me/photos/{photoID} [delete]
+func (h *PhotoHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(auth.UserIDKey).(string)
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	if err := h.service.Delete(r.Context(), userID, r.PathValue("photoID")); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			http.Error(w, "Photo not found", http.StatusNotFound)
+			return
+		}
+		h.logger.Error("Failed to delete photo", zap.Error(err))
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// RejectInput is the body accepted by Reject.
+type RejectInput struct {
+	Reason string `json:"reason"`
+}
+
+// ModerationQueue lists photos awaiting review.
+// @Summary Photo moderation queue
+// @Description Moderator-only list of stored photos that are still pending moderation, oldest first.
+// @Tags admin
+// @Produce json
+// @Security ApiKeyAuth
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Param offset query int false "Number of photos to skip"
+// @Success 200 {array} photo.Photo
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Forbidden"
+// @Failure 500 {string} string "Internal server error"
+// @Router /admin/photos/moderation [get]
+func (h *PhotoHandler) ModerationQueue(w http.ResponseWriter, r *http.Request) {
+	var limit, offset int
+	query := r.URL.Query()
+	if limitStr := query.Get("limit"); limitStr != "" {
+		fmt.Sscanf(limitStr, "%d", &limit)
+	}
+	if offsetStr := query.Get("offset"); offsetStr != "" {
+		fmt.Sscanf(offsetStr, "%d", &offset)
+	}
+
+	photos, err := h.service.ListModerationQueue(r.Context(), limit, offset)
+	if err != nil {
+		h.logger.Error("Failed to list moderation queue", zap.Error(err))
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	h.writePhotos(w, photos)
+}
+
+// Approve publishes a pending photo.
+// @Summary Approve a photo
+// @Description Moderator-only. Approves a photo so it is shown to other users.
+// @Tags admin
+// @Produce json
+// @Security ApiKeyAuth
+// @Param photoID path string true "Photo ID"
+// @Success 200 {object} photo.Photo
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Forbidden"
+// @Failure 404 {string} string "Photo not found"
+// @Failure 500 {string} string "Internal server error"
+// @Router /admin/photos/{photoID}/approve [post]
+func (h *PhotoHandler) Approve(w http.ResponseWriter, r *http.Request) {
+	moderatorID, ok := r.Context().Value(auth.UserIDKey).(string)
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	p, err := h.service.Approve(r.Context(), moderatorID, r.PathValue("photoID"))
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			http.Error(w, "Photo not found", http.StatusNotFound)
+			return
+		}
+		h.logger.Error("Failed to approve photo", zap.Error(err))
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(p)
+}
+
+// Reject hides a photo from other users.
+// @Summary Reject a photo
+// @Description Moderator-only. Rejects a photo; the owner sees the reason in their photo list.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param photoID path string true "Photo ID"
+// @Param input body RejectInput true "Rejection reason"
+// @Success 200 {object} photo.Photo
+// @Failure 400 {string} string "Reason required"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Forbidden"
+// @Failure 404 {string} string "Photo not found"
+// @Failure 500 {string} string "Internal server error"
+// @Router /admin/photos/{photoID}/reject [post]
+func (h *PhotoHandler) Reject(w http.ResponseWriter, r *http.Request) {
+	moderatorID, ok := r.Context().Value(auth.UserIDKey).(string)
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var input RejectInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	p, err := h.service.Reject(r.Context(), moderatorID, r.PathValue("photoID"), strings.TrimSpace(input.Reason))
+	if err != nil {
+		if errors.Is(err, service.ErrReasonRequired) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if errors.Is(err, repository.ErrNotFound) {
+			http.Error(w, "Photo not found", http.StatusNotFound)
+			return
+		}
+		h.logger.Error("Failed to reject photo", zap.Error(err))
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(p)
+}
+
+func (h *PhotoHandler) writePhotos(w http.ResponseWriter, photos []*photo.Photo) {
+	if photos == nil {
+		photos = []*photo.Photo{}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(photos)
+}
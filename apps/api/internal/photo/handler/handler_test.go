@@ -0,0 +1,346 @@
+package handler_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/kisssonik/hearts/internal/photo"
+	"github.com/kisssonik/hearts/internal/photo/handler"
+	"github.com/kisssonik/hearts/internal/photo/repository"
+	"github.com/kisssonik/hearts/internal/photo/service"
+	"github.com/kisssonik/hearts/pkg/auth"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+)
+
+// MockPhotoService
+type MockPhotoService struct {
+	mock.Mock
+}
+
+func (m *MockPhotoService) Upload(ctx context.Context, userID string, file io.Reader) (*photo.Photo, error) {
+	args := m.Called(ctx, userID, file)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*photo.Photo), args.Error(1)
+}
+
+func (m *MockPhotoService) List(ctx context.Context, userID string) ([]*photo.Photo, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*photo.Photo), args.Error(1)
+}
+
+func (m *MockPhotoService) Reorder(ctx context.Context, userID string, photoIDs []string) ([]*photo.Photo, error) {
+	args := m.Called(ctx, userID, photoIDs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*photo.Photo), args.Error(1)
+}
+
+func (m *MockPhotoService) SetPrimary(ctx context.Context, userID, photoID string) ([]*photo.Photo, error) {
+	args := m.Called(ctx, userID, photoID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*photo.Photo), args.Error(1)
+}
+
+func (m *MockPhotoService) Delete(ctx context.Context, userID, photoID string) error {
+	args := m.Called(ctx, userID, photoID)
+	return args.Error(0)
+}
+
+func (m *MockPhotoService) ProcessModeration(ctx context.Context, photoID string) error {
+	args := m.Called(ctx, photoID)
+	return args.Error(0)
+}
+
+func (m *MockPhotoService) ListModerationQueue(ctx context.Context, limit, offset int) ([]*photo.Photo, error) {
+	args := m.Called(ctx, limit, offset)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*photo.Photo), args.Error(1)
+}
+
+func (m *MockPhotoService) Approve(ctx context.Context, moderatorID, photoID string) (*photo.Photo, error) {
+	args := m.Called(ctx, moderatorID, photoID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*photo.Photo), args.Error(1)
+}
+
+func (m *MockPhotoService) Reject(ctx context.Context, moderatorID, photoID, reason string) (*photo.Photo, error) {
+	args := m.Called(ctx, moderatorID, photoID, reason)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*photo.Photo), args.Error(1)
+}
+
+func withUser(r *http.Request, userID string) *http.Request {
+	return r.WithContext(context.WithValue(r.Context(), auth.UserIDKey, userID))
+}
+
+func TestPhotoHandler_Upload(t *testing.T) {
+	mockService := new(MockPhotoService)
+	h := handler.NewPhotoHandler(mockService, zap.NewNop())
+
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	part, _ := mw.CreateFormFile("photo", "me.png")
+	part.Write([]byte("data"))
+	mw.Close()
+
+	req := withUser(httptest.NewRequest("POST", "/profiles/me/photos", &body), "user1")
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	w := httptest.NewRecorder()
+
+	mockService.On("Upload", mock.Anything, "user1", mock.Anything).
+		Return(&photo.Photo{ID: "photo1", StorageKey: "user1/x.png", URL: "http://url", Status: photo.StatusReady}, nil)
+
+	h.Upload(w, req)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var resp map[string]interface{}
+	json.NewDecoder(w.Body).Decode(&resp)
+	assert.Equal(t, "photo1", resp["id"])
+	assert.Equal(t, "http://url", resp["url"])
+	assert.NotContains(t, resp, "storageKey")
+	mockService.AssertExpectations(t)
+}
+
+func TestPhotoHandler_UploadLegacy(t *testing.T) {
+	mockService := new(MockPhotoService)
+	h := handler.NewPhotoHandler(mockService, zap.NewNop())
+
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	part, _ := mw.CreateFormFile("photo", "me.jpg")
+	part.Write([]byte("data"))
+	mw.Close()
+
+	req := withUser(httptest.NewRequest("POST", "/profiles/upload", &body), "user1")
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	w := httptest.NewRecorder()
+
+	mockService.On("Upload", mock.Anything, "user1", mock.Anything).
+		Return(&photo.Photo{ID: "photo1", StorageKey: "user1/x.jpg", URL: "http://url"}, nil)
+
+	h.UploadLegacy(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var resp handler.UploadPhotoResponse
+	json.NewDecoder(w.Body).Decode(&resp)
+	assert.Equal(t, "user1/x.jpg", resp.Key)
+	assert.Equal(t, "http://url", resp.URL)
+}
+
+func TestPhotoHandler_Upload_Errors(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+	}{
+		{"invalid image", service.ErrInvalidImage, http.StatusBadRequest},
+		{"too small", service.ErrImageTooSmall, http.StatusBadRequest},
+		{"too large", service.ErrFileTooLarge, http.StatusRequestEntityTooLarge},
+		{"unsupported type", service.ErrUnsupportedType, http.StatusUnsupportedMediaType},
+		{"limit reached", repository.ErrLimitReached, http.StatusConflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockPhotoService)
+			h := handler.NewPhotoHandler(mockService, zap.NewNop())
+
+			var body bytes.Buffer
+			mw := multipart.NewWriter(&body)
+			part, _ := mw.CreateFormFile("photo", "notes.txt")
+			part.Write([]byte("hello"))
+			mw.Close()
+
+			req := withUser(httptest.NewRequest("POST", "/profiles/me/photos", &body), "user1")
+			req.Header.Set("Content-Type", mw.FormDataContentType())
+			w := httptest.NewRecorder()
+
+			mockService.On("Upload", mock.Anything, "user1", mock.Anything).Return(nil, tt.err)
+
+			h.Upload(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+		})
+	}
+}
+
+func TestPhotoHandler_Upload_MalformedForm(t *testing.T) {
+	mockService := new(MockPhotoService)
+	h := handler.NewPhotoHandler(mockService, zap.NewNop())
+
+	req := withUser(httptest.NewRequest("POST", "/profiles/me/photos", bytes.NewReader([]byte("not multipart"))), "user1")
+	req.Header.Set("Content-Type", "multipart/form-data; boundary=xyz")
+	w := httptest.NewRecorder()
+
+	h.Upload(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockService.AssertNotCalled(t, "Upload", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestPhotoHandler_Reorder(t *testing.T) {
+	mockService := new(MockPhotoService)
+	h := handler.NewPhotoHandler(mockService, zap.NewNop())
+
+	body, _ := json.Marshal(handler.ReorderInput{PhotoIDs: []string{"b", "a"}})
+	req := withUser(httptest.NewRequest("PUT", "/profiles/me/photos/order", bytes.NewReader(body)), "user1")
+	w := httptest.NewRecorder()
+
+	mockService.On("Reorder", mock.Anything, "user1", []string{"b", "a"}).Return([]*photo.Photo{{ID: "b"}, {ID: "a"}}, nil)
+
+	h.Reorder(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var resp []map[string]interface{}
+	json.NewDecoder(w.Body).Decode(&resp)
+	assert.Len(t, resp, 2)
+	assert.Equal(t, "b", resp[0]["id"])
+}
+
+func TestPhotoHandler_Reorder_Invalid(t *testing.T) {
+	mockService := new(MockPhotoService)
+	h := handler.NewPhotoHandler(mockService, zap.NewNop())
+
+	body, _ := json.Marshal(handler.ReorderInput{PhotoIDs: []string{"a"}})
+	req := withUser(httptest.NewRequest("PUT", "/profiles/me/photos/order", bytes.NewReader(body)), "user1")
+	w := httptest.NewRecorder()
+
+	mockService.On("Reorder", mock.Anything, "user1", []string{"a"}).Return(nil, repository.ErrInvalidOrder)
+
+	h.Reorder(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestPhotoHandler_SetPrimary_NotFound(t *testing.T) {
+	mockService := new(MockPhotoService)
+	h := handler.NewPhotoHandler(mockService, zap.NewNop())
+
+	req := withUser(httptest.NewRequest("PUT", "/profiles/me/photos/missing/primary", nil), "user1")
+	req.SetPathValue("photoID", "missing")
+	w := httptest.NewRecorder()
+
+	mockService.On("SetPrimary", mock.Anything, "user1", "missing").Return(nil, repository.ErrNotFound)
+
+	h.SetPrimary(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestPhotoHandler_Delete(t *testing.T) {
+	mockService := new(MockPhotoService)
+	h := handler.NewPhotoHandler(mockService, zap.NewNop())
+
+	req := withUser(httptest.NewRequest("DELETE", "/profiles/me/photos/photo1", nil), "user1")
+	req.SetPathValue("photoID", "photo1")
+	w := httptest.NewRecorder()
+
+	mockService.On("Delete", mock.Anything, "user1", "photo1").Return(nil)
+
+	h.Delete(w, req)
+
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestPhotoHandler_Delete_Unauthorized(t *testing.T) {
+	mockService := new(MockPhotoService)
+	h := handler.NewPhotoHandler(mockService, zap.NewNop())
+
+	req := httptest.NewRequest("DELETE", "/profiles/me/photos/photo1", nil)
+	req.SetPathValue("photoID", "photo1")
+	w := httptest.NewRecorder()
+
+	h.Delete(w, req)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	mockService.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestPhotoHandler_ModerationQueue(t *testing.T) {
+	mockService := new(MockPhotoService)
+	h := handler.NewPhotoHandler(mockService, zap.NewNop())
+
+	req := withUser(httptest.NewRequest("GET", "/admin/photos/moderation?limit=10&offset=20", nil), "admin")
+	w := httptest.NewRecorder()
+
+	mockService.On("ListModerationQueue", mock.Anything, 10, 20).Return([]*photo.Photo{{ID: "photo1", UserID: "user1"}}, nil)
+
+	h.ModerationQueue(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var resp []map[string]interface{}
+	json.NewDecoder(w.Body).Decode(&resp)
+	assert.Len(t, resp, 1)
+	assert.Equal(t, "user1", resp[0]["userId"])
+}
+
+func TestPhotoHandler_Approve_NotFound(t *testing.T) {
+	mockService := new(MockPhotoService)
+	h := handler.NewPhotoHandler(mockService, zap.NewNop())
+
+	req := withUser(httptest.NewRequest("POST", "/admin/photos/missing/approve", nil), "admin")
+	req.SetPathValue("photoID", "missing")
+	w := httptest.NewRecorder()
+
+	mockService.On("Approve", mock.Anything, "admin", "missing").Return(nil, repository.ErrNotFound)
+
+	h.Approve(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestPhotoHandler_Reject(t *testing.T) {
+	mockService := new(MockPhotoService)
+	h := handler.NewPhotoHandler(mockService, zap.NewNop())
+
+	body, _ := json.Marshal(handler.RejectInput{Reason: "  spam  "})
+	req := withUser(httptest.NewRequest("POST", "/admin/photos/photo1/reject", bytes.NewReader(body)), "admin")
+	req.SetPathValue("photoID", "photo1")
+	w := httptest.NewRecorder()
+
+	mockService.On("Reject", mock.Anything, "admin", "photo1", "spam").
+		Return(&photo.Photo{ID: "photo1", ModerationStatus: photo.ModerationRejected}, nil)
+
+	h.Reject(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestPhotoHandler_Reject_ReasonRequired(t *testing.T) {
+	mockService := new(MockPhotoService)
+	h := handler.NewPhotoHandler(mockService, zap.NewNop())
+
+	req := withUser(httptest.NewRequest("POST", "/admin/photos/photo1/reject", bytes.NewReader([]byte(`{}`))), "admin")
+	req.SetPathValue("photoID", "photo1")
+	w := httptest.NewRecorder()
+
+	mockService.On("Reject", mock.Anything, "admin", "photo1", "").Return(nil, service.ErrReasonRequired)
+
+	h.Reject(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
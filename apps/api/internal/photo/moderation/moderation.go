@@ -0,0 +1,81 @@
+// Package moderation decides whether uploaded photos may be shown to other
+// users.
+package moderation
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/jpeg"
+)
+
+// Decisions a Classifier can return.
+const (
+	DecisionApprove = "approve" // Publish immediately
+	DecisionReject  = "reject"  // Never publish
+	DecisionReview  = "review"  // Leave pending for a human moderator
+)
+
+// Verdict is the outcome of classifying a photo.
+type Verdict struct {
+	Decision string
+	Reason   string
+	Score    float64 // Classifier specific confidence, 0..1
+}
+
+// Classifier inspects an encoded image. Implementations may call out to an
+// external service; errors leave the photo pending for manual review.
+type Classifier interface {
+	Classify(ctx context.Context, image []byte) (Verdict, error)
+}
+
+// ReviewThreshold is the share of skin-toned pixels above which the stub
+// classifier asks for human review.
+const ReviewThreshold = 0.5
+
+type stubClassifier struct{}
+
+// NewStubClassifier returns a deterministic, dependency free classifier for
+// local development. It never rejects: images dominated by skin tones go to
+// manual review, everything else is approved.
+func NewStubClassifier() Classifier {
+	return stubClassifier{}
+}
+
+func (stubClassifier) Classify(_ context.Context, data []byte) (Verdict, error) {
+	img, err := jpeg.Decode(bytes.NewReader(data))
+	if err != nil {
+		return Verdict{}, err
+	}
+
+	score := skinRatio(img)
+	if score > ReviewThreshold {
+		return Verdict{Decision: DecisionReview, Reason: "high proportion of skin tones", Score: score}, nil
+	}
+	return Verdict{Decision: DecisionApprove, Score: score}, nil
+}
+
+// skinRatio returns the share of sampled pixels matching a simple RGB skin
+// tone rule.
+func skinRatio(img image.Image) float64 {
+	b := img.Bounds()
+	step := max(1, max(b.Dx(), b.Dy())/100)
+
+	var skin, total int
+	for y := b.Min.Y; y < b.Max.Y; y += step {
+		for x := b.Min.X; x < b.Max.X; x += step {
+			r32, g32, b32, _ := img.At(x, y).RGBA()
+			r, g, bl := int(r32>>8), int(g32>>8), int(b32>>8)
+			if r > 95 && g > 40 && bl > 20 &&
+				max(r, g, bl)-min(r, g, bl) > 15 &&
+				r-g > 15 && r > bl {
+				skin++
+			}
+			total++
+		}
+	}
+	if total == 0 {
+		return 0
+	}
+	return float64(skin) / float64(total)
+}
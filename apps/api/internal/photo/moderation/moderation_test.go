@@ -0,0 +1,42 @@
+package moderation_test
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"testing"
+
+	"github.com/kisssonik/hearts/internal/photo/moderation"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func solidJPEG(t *testing.T, c color.Color) []byte {
+	img := image.NewRGBA(image.Rect(0, 0, 64, 64))
+	for y := 0; y < 64; y++ {
+		for x := 0; x < 64; x++ {
+			img.Set(x, y, c)
+		}
+	}
+	var buf bytes.Buffer
+	require.NoError(t, jpeg.Encode(&buf, img, nil))
+	return buf.Bytes()
+}
+
+func TestStubClassifier(t *testing.T) {
+	c := moderation.NewStubClassifier()
+
+	v, err := c.Classify(context.Background(), solidJPEG(t, color.RGBA{R: 30, G: 90, B: 200, A: 255}))
+	require.NoError(t, err)
+	assert.Equal(t, moderation.DecisionApprove, v.Decision)
+
+	v, err = c.Classify(context.Background(), solidJPEG(t, color.RGBA{R: 220, G: 170, B: 140, A: 255}))
+	require.NoError(t, err)
+	assert.Equal(t, moderation.DecisionReview, v.Decision)
+	assert.Greater(t, v.Score, moderation.ReviewThreshold)
+
+	_, err = c.Classify(context.Background(), []byte("not a jpeg"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,100 @@
+package photo
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/kisssonik/hearts/pkg/storage"
+)
+
+// Upload statuses of a photo.
+const (
+	StatusPending = "pending" // Row created, object not stored yet
+	StatusReady   = "ready"   // Stored and visible on the profile
+	StatusFailed  = "failed"  // Storage upload failed
+)
+
+// Moderation states of a photo. Only approved photos are shown to others.
+const (
+	ModerationPending  = "pending"
+	ModerationApproved = "approved"
+	ModerationRejected = "rejected"
+)
+
+// Sized variants produced for every processed upload.
+const (
+	VariantThumb  = "thumb"
+	VariantMedium = "medium"
+	VariantFull   = "full"
+)
+
+// VariantNames lists the variants in ascending size.
+var VariantNames = []string{VariantThumb, VariantMedium, VariantFull}
+
+// Photo is a single image attached to a user's profile.
+type Photo struct {
+	ID               string    `json:"id" db:"id"`
+	UserID           string    `json:"userId" db:"user_id"`
+	StorageKey       string    `json:"-" db:"storage_key"` // Key of the full variant
+	URL              string    `json:"url"`
+	Variants         *Variants `json:"variants,omitempty"`
+	Position         int       `json:"position" db:"position"`
+	IsPrimary        bool      `json:"isPrimary" db:"is_primary"`
+	Status           string    `json:"status" db:"status"`
+	ModerationStatus string    `json:"moderationStatus" db:"moderation_status"`
+	ModerationReason *string   `json:"moderationReason,omitempty" db:"moderation_reason"` // Shown to the owner on rejection
+	Width            *int      `json:"width,omitempty" db:"width"`
+	Height           *int      `json:"height,omitempty" db:"height"`
+	CreatedAt        time.Time `json:"createdAt" db:"created_at"`
+}
+
+// Variants holds a URL per sized variant of a photo.
+type Variants struct {
+	Thumb  string `json:"thumb"`
+	Medium string `json:"medium"`
+	Full   string `json:"full"`
+}
+
+// KeyPrefix returns the storage prefix under which the variants of a new
+// photo are stored.
+func KeyPrefix(userID, id string) string {
+	return userID + "/" + id + "/"
+}
+
+// VariantKey derives the storage key of a variant from the key of the full
+// variant. Photos uploaded before processing existed have a single original
+// object, which is returned for every variant.
+func VariantKey(fullKey, variant string) string {
+	suffix := "/" + VariantFull + ".jpg"
+	if !strings.HasSuffix(fullKey, suffix) {
+		return fullKey
+	}
+	return strings.TrimSuffix(fullKey, suffix) + "/" + variant + ".jpg"
+}
+
+// VariantKeys returns the distinct storage keys belonging to a photo.
+func VariantKeys(fullKey string) []string {
+	if VariantKey(fullKey, VariantThumb) == fullKey {
+		return []string{fullKey}
+	}
+	keys := make([]string, 0, len(VariantNames))
+	for _, v := range VariantNames {
+		keys = append(keys, VariantKey(fullKey, v))
+	}
+	return keys
+}
+
+// ResolveVariants presigns a URL for every variant of the photo stored at
+// fullKey.
+func ResolveVariants(ctx context.Context, s storage.Provider, fullKey string) (*Variants, error) {
+	urls := make(map[string]string, len(VariantNames))
+	for _, v := range VariantNames {
+		url, err := s.GetPresignedURL(ctx, VariantKey(fullKey, v))
+		if err != nil {
+			return nil, err
+		}
+		urls[v] = url
+	}
+	return &Variants{Thumb: urls[VariantThumb], Medium: urls[VariantMedium], Full: urls[VariantFull]}, nil
+}
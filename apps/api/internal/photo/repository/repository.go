@@ -0,0 +1,378 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/kisssonik/hearts/internal/photo"
+)
+
+var (
+	ErrNotFound     = errors.New("photo not found")
+	ErrInvalidOrder = errors.New("photo order must list every photo exactly once")
+	ErrLimitReached = errors.New("photo limit reached")
+)
+
+type PhotoRepository interface {
+	Create(ctx context.Context, p *photo.Photo, limit int) error
+	MarkReady(ctx context.Context, p *photo.Photo) error
+	MarkFailed(ctx context.Context, photoID string) error
+	GetByID(ctx context.Context, userID, photoID string) (*photo.Photo, error)
+	FindByID(ctx context.Context, photoID string) (*photo.Photo, error)
+	ListByUserID(ctx context.Context, userID string) ([]*photo.Photo, error)
+	Reorder(ctx context.Context, userID string, photoIDs []string) error
+	SetPrimary(ctx context.Context, userID, photoID string) error
+	Delete(ctx context.Context, userID, photoID string) error
+	SetModeration(ctx context.Context, photoID, status string, reason, moderatorID *string) (*photo.Photo, error)
+	ListModerationQueue(ctx context.Context, limit, offset int) ([]*photo.Photo, error)
+}
+
+type pgxPhotoRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewPhotoRepository(db *pgxpool.Pool) PhotoRepository {
+	return &pgxPhotoRepository{db: db}
+}
+
+// execer is satisfied by both the pool and a transaction.
+type execer interface {
+	Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error)
+}
+
+// syncProfilePhotos rewrites the denormalised profiles.photos column from
+// profile_photos: ready, approved photos only, primary first, then by
+// position.
+func syncProfilePhotos(ctx context.Context, db execer, userID string) error {
+	query := `
+		UPDATE profiles
+		SET photos = ARRAY(
+			SELECT storage_key FROM profile_photos
+			WHERE user_id = $1 AND status = 'ready' AND moderation_status = 'approved'
+			ORDER BY is_primary DESC, position ASC
+		)
+		WHERE user_id = $1
+	`
+	_, err := db.Exec(ctx, query, userID)
+	return err
+}
+
+const photoColumns = `id, user_id, storage_key, position, is_primary, status, moderation_status, moderation_reason, width, height, created_at`
+
+func scanPhoto(row pgx.Row, p *photo.Photo) error {
+	return row.Scan(&p.ID, &p.UserID, &p.StorageKey, &p.Position, &p.IsPrimary, &p.Status, &p.ModerationStatus, &p.ModerationReason, &p.Width, &p.Height, &p.CreatedAt)
+}
+
+// Create appends a pending photo after the user's existing photos. The first
+// photo a user uploads becomes their primary photo. It fails with
+// ErrLimitReached if the user already has limit photos that did not fail.
+func (r *pgxPhotoRepository) Create(ctx context.Context, p *photo.Photo, limit int) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	// Serialise concurrent uploads by the same user so the count below holds.
+	if _, err := tx.Exec(ctx, `SELECT 1 FROM users WHERE id = $1 FOR UPDATE`, p.UserID); err != nil {
+		return err
+	}
+
+	var count int
+	countQuery := `SELECT COUNT(*) FROM profile_photos WHERE user_id = $1 AND status <> 'failed'`
+	if err := tx.QueryRow(ctx, countQuery, p.UserID).Scan(&count); err != nil {
+		return err
+	}
+	if count >= limit {
+		return ErrLimitReached
+	}
+
+	query := `
+		INSERT INTO profile_photos (user_id, storage_key, position, is_primary, status, width, height)
+		SELECT $1, $2,
+			COALESCE((SELECT MAX(position) + 1 FROM profile_photos WHERE user_id = $1), 0),
+			NOT EXISTS (SELECT 1 FROM profile_photos WHERE user_id = $1 AND is_primary),
+			$3, $4, $5
+		RETURNING id, position, is_primary, status, moderation_status, created_at
+	`
+	if err := tx.QueryRow(ctx, query, p.UserID, p.StorageKey, photo.StatusPending, p.Width, p.Height).Scan(
+		&p.ID, &p.Position, &p.IsPrimary, &p.Status, &p.ModerationStatus, &p.CreatedAt,
+	); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// MarkReady records the stored object's dimensions and publishes the photo
+// on the profile.
+func (r *pgxPhotoRepository) MarkReady(ctx context.Context, p *photo.Photo) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+		UPDATE profile_photos
+		SET status = $1, width = $2, height = $3, updated_at = NOW()
+		WHERE id = $4
+	`
+	tag, err := tx.Exec(ctx, query, photo.StatusReady, p.Width, p.Height, p.ID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	if err := syncProfilePhotos(ctx, tx, p.UserID); err != nil {
+		return err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return err
+	}
+	p.Status = photo.StatusReady
+	return nil
+}
+
+func (r *pgxPhotoRepository) MarkFailed(ctx context.Context, photoID string) error {
+	query := `UPDATE profile_photos SET status = $1, is_primary = FALSE, updated_at = NOW() WHERE id = $2`
+	_, err := r.db.Exec(ctx, query, photo.StatusFailed, photoID)
+	return err
+}
+
+func (r *pgxPhotoRepository) GetByID(ctx context.Context, userID, photoID string) (*photo.Photo, error) {
+	query := `SELECT ` + photoColumns + ` FROM profile_photos WHERE id = $1 AND user_id = $2`
+	p := &photo.Photo{}
+	if err := scanPhoto(r.db.QueryRow(ctx, query, photoID, userID), p); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return p, nil
+}
+
+func (r *pgxPhotoRepository) FindByID(ctx context.Context, photoID string) (*photo.Photo, error) {
+	query := `SELECT ` + photoColumns + ` FROM profile_photos WHERE id = $1`
+	p := &photo.Photo{}
+	if err := scanPhoto(r.db.QueryRow(ctx, query, photoID), p); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return p, nil
+}
+
+func (r *pgxPhotoRepository) ListByUserID(ctx context.Context, userID string) ([]*photo.Photo, error) {
+	query := `
+		SELECT ` + photoColumns + `
+		FROM profile_photos
+		WHERE user_id = $1 AND status <> 'failed'
+		ORDER BY position ASC, created_at ASC
+	`
+	rows, err := r.db.Query(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var photos []*photo.Photo
+	for rows.Next() {
+		p := &photo.Photo{}
+		if err := scanPhoto(rows, p); err != nil {
+			return nil, err
+		}
+		photos = append(photos, p)
+	}
+	return photos, rows.Err()
+}
+
+// Reorder assigns positions following photoIDs, which must contain every
+// non-failed photo of the user exactly once.
+func (r *pgxPhotoRepository) Reorder(ctx context.Context, userID string, photoIDs []string) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	var count int
+	var matched int
+	query := `
+		SELECT COUNT(*), COUNT(*) FILTER (WHERE id = ANY($2))
+		FROM profile_photos
+		WHERE user_id = $1 AND status <> 'failed'
+	`
+	if err := tx.QueryRow(ctx, query, userID, photoIDs).Scan(&count, &matched); err != nil {
+		return err
+	}
+	if count != len(photoIDs) || matched != len(photoIDs) || hasDuplicates(photoIDs) {
+		return ErrInvalidOrder
+	}
+
+	update := `
+		UPDATE profile_photos pp
+		SET position = o.ord - 1, updated_at = NOW()
+		FROM unnest($2::uuid[]) WITH ORDINALITY AS o(id, ord)
+		WHERE pp.id = o.id AND pp.user_id = $1
+	`
+	if _, err := tx.Exec(ctx, update, userID, photoIDs); err != nil {
+		return err
+	}
+	if err := syncProfilePhotos(ctx, tx, userID); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+func (r *pgxPhotoRepository) SetPrimary(ctx context.Context, userID, photoID string) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	var exists bool
+	check := `SELECT EXISTS (SELECT 1 FROM profile_photos WHERE id = $1 AND user_id = $2 AND status = 'ready')`
+	if err := tx.QueryRow(ctx, check, photoID, userID).Scan(&exists); err != nil {
+		return err
+	}
+	if !exists {
+		return ErrNotFound
+	}
+
+	// Clear first so the partial unique index never sees two primaries.
+	if _, err := tx.Exec(ctx, `UPDATE profile_photos SET is_primary = FALSE WHERE user_id = $1 AND is_primary`, userID); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(ctx, `UPDATE profile_photos SET is_primary = TRUE, updated_at = NOW() WHERE id = $1`, photoID); err != nil {
+		return err
+	}
+	if err := syncProfilePhotos(ctx, tx, userID); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// Delete removes a photo, closes the gap in positions and promotes the next
+// ready photo to primary if the deleted one was primary.
+func (r *pgxPhotoRepository) Delete(ctx context.Context, userID, photoID string) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	var wasPrimary bool
+	query := `DELETE FROM profile_photos WHERE id = $1 AND user_id = $2 RETURNING is_primary`
+	if err := tx.QueryRow(ctx, query, photoID, userID).Scan(&wasPrimary); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrNotFound
+		}
+		return err
+	}
+
+	compact := `
+		UPDATE profile_photos pp
+		SET position = o.new_position
+		FROM (
+			SELECT id, ROW_NUMBER() OVER (ORDER BY position, created_at) - 1 AS new_position
+			FROM profile_photos WHERE user_id = $1
+		) o
+		WHERE pp.id = o.id AND pp.position <> o.new_position
+	`
+	if _, err := tx.Exec(ctx, compact, userID); err != nil {
+		return err
+	}
+
+	if wasPrimary {
+		promote := `
+			UPDATE profile_photos SET is_primary = TRUE
+			WHERE id = (
+				SELECT id FROM profile_photos
+				WHERE user_id = $1 AND status = 'ready'
+				ORDER BY position LIMIT 1
+			)
+		`
+		if _, err := tx.Exec(ctx, promote, userID); err != nil {
+			return err
+		}
+	}
+
+	if err := syncProfilePhotos(ctx, tx, userID); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// SetModeration records a moderation decision and republishes the owner's
+// profile photos. moderatorID is nil for automated decisions.
+func (r *pgxPhotoRepository) SetModeration(ctx context.Context, photoID, status string, reason, moderatorID *string) (*photo.Photo, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+		UPDATE profile_photos
+		SET moderation_status = $1, moderation_reason = $2, moderated_by = $3, moderated_at = NOW(), updated_at = NOW()
+		WHERE id = $4
+		RETURNING ` + photoColumns
+	p := &photo.Photo{}
+	if err := scanPhoto(tx.QueryRow(ctx, query, status, reason, moderatorID, photoID), p); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	if err := syncProfilePhotos(ctx, tx, p.UserID); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// ListModerationQueue returns stored photos awaiting a decision, oldest
+// first.
+func (r *pgxPhotoRepository) ListModerationQueue(ctx context.Context, limit, offset int) ([]*photo.Photo, error) {
+	query := `
+		SELECT ` + photoColumns + `
+		FROM profile_photos
+		WHERE status = 'ready' AND moderation_status = 'pending'
+		ORDER BY created_at ASC
+		LIMIT $1 OFFSET $2
+	`
+	rows, err := r.db.Query(ctx, query, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var photos []*photo.Photo
+	for rows.Next() {
+		p := &photo.Photo{}
+		if err := scanPhoto(rows, p); err != nil {
+			return nil, err
+		}
+		photos = append(photos, p)
+	}
+	return photos, rows.Err()
+}
+
+func hasDuplicates(ids []string) bool {
+	seen := make(map[string]struct{}, len(ids))
+	for _, id := range ids {
+		if _, ok := seen[id]; ok {
+			return true
+		}
+		seen[id] = struct{}{}
+	}
+	return false
+}
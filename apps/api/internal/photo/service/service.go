@@ -0,0 +1,372 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image"
+	"io"
+
+	// Register decoders used by image.DecodeConfig.
+	_ "image/jpeg"
+	_ "image/png"
+
+	_ "golang.org/x/image/webp"
+
+	"github.com/google/uuid"
+	"github.com/kisssonik/hearts/internal/photo"
+	"github.com/kisssonik/hearts/internal/photo/moderation"
+	"github.com/kisssonik/hearts/internal/photo/repository"
+	"github.com/kisssonik/hearts/pkg/imaging"
+	"github.com/kisssonik/hearts/pkg/queue"
+	"github.com/kisssonik/hearts/pkg/storage"
+)
+
+var (
+	ErrInvalidImage    = errors.New("file is not a valid image")
+	ErrUnsupportedType = errors.New("unsupported image type; use JPEG, PNG or WebP")
+	ErrFileTooLarge    = errors.New("file is too large")
+	ErrImageTooSmall   = errors.New("image is too small")
+	ErrImageTooLarge   = errors.New("image dimensions are too large")
+	ErrReasonRequired  = errors.New("a reason is required to reject a photo")
+)
+
+// Defaults used when the corresponding Config field is not set.
+const (
+	DefaultMaxBytes     = 10 << 20
+	DefaultMaxCount     = 6
+	DefaultMinDimension = 320
+	DefaultMaxDimension = 8000
+	DefaultMaxPixels    = imaging.DefaultMaxPixels
+	DefaultMaxInFlight  = 4
+)
+
+// Config holds upload limits.
+type Config struct {
+	MaxBytes     int64 // Largest accepted file
+	MaxCount     int   // Photos per profile, excluding failed uploads
+	MinDimension int   // Minimum length of the shortest side
+	MaxDimension int   // Maximum length of the longest side
+	MaxPixels    int   // Maximum width × height, bounding decoded memory
+	MaxInFlight  int   // Uploads decoded and stored at the same time
+
+	// Slots, when set, is shared with other services that decode uploads
+	// and replaces MaxInFlight.
+	Slots imaging.Slots
+}
+
+// allowedFormats are the sniffed formats accepted for upload.
+var allowedFormats = map[string]bool{
+	imaging.FormatJPEG: true,
+	imaging.FormatPNG:  true,
+	imaging.FormatWebP: true,
+}
+
+// variants are the sizes stored for every upload. Names must match the
+// photo.Variant* constants.
+var variants = []imaging.Variant{
+	{Name: photo.VariantThumb, MaxSide: 200},
+	{Name: photo.VariantMedium, MaxSide: 800},
+	{Name: photo.VariantFull, MaxSide: 1600},
+}
+
+const jpegQuality = 85
+
+// Moderation queue page size bounds.
+const (
+	DefaultQueueLimit = 20
+	MaxQueueLimit     = 100
+)
+
+type PhotoService interface {
+	Upload(ctx context.Context, userID string, file io.Reader) (*photo.Photo, error)
+	List(ctx context.Context, userID string) ([]*photo.Photo, error)
+	Reorder(ctx context.Context, userID string, photoIDs []string) ([]*photo.Photo, error)
+	SetPrimary(ctx context.Context, userID, photoID string) ([]*photo.Photo, error)
+	Delete(ctx context.Context, userID, photoID string) error
+	ProcessModeration(ctx context.Context, photoID string) error
+	ListModerationQueue(ctx context.Context, limit, offset int) ([]*photo.Photo, error)
+	Approve(ctx context.Context, moderatorID, photoID string) (*photo.Photo, error)
+	Reject(ctx context.Context, moderatorID, photoID, reason string) (*photo.Photo, error)
+}
+
+// ModerationMessage asks the moderation worker to classify a photo.
+type ModerationMessage struct {
+	PhotoID string `json:"photoId"`
+}
+
+type photoService struct {
+	repo       repository.PhotoRepository
+	storage    storage.Provider
+	producer   queue.Producer
+	classifier moderation.Classifier
+	processor  *imaging.Processor
+	cfg        Config
+}
+
+func NewPhotoService(repo repository.PhotoRepository, storage storage.Provider, producer queue.Producer, classifier moderation.Classifier, cfg Config) PhotoService {
+	if cfg.MaxBytes <= 0 {
+		cfg.MaxBytes = DefaultMaxBytes
+	}
+	if cfg.MaxCount <= 0 {
+		cfg.MaxCount = DefaultMaxCount
+	}
+	if cfg.MinDimension <= 0 {
+		cfg.MinDimension = DefaultMinDimension
+	}
+	if cfg.MaxDimension <= 0 {
+		cfg.MaxDimension = DefaultMaxDimension
+	}
+	if cfg.MaxPixels <= 0 {
+		cfg.MaxPixels = DefaultMaxPixels
+	}
+	if cfg.MaxInFlight <= 0 {
+		cfg.MaxInFlight = DefaultMaxInFlight
+	}
+	if cfg.Slots == nil {
+		cfg.Slots = imaging.NewSlots(cfg.MaxInFlight)
+	}
+	return &photoService{
+		repo:       repo,
+		storage:    storage,
+		producer:   producer,
+		classifier: classifier,
+		processor:  imaging.NewProcessor(variants, jpegQuality, cfg.MaxPixels),
+		cfg:        cfg,
+	}
+}
+
+// Upload re-encodes the image into sized variants, which strips EXIF and
+// other metadata, and queues it for moderation. The row is created as pending
+// first so a failed upload never leaves an orphaned key on the profile. The
+// photo is shown to others once approved. At most MaxInFlight uploads are
+// decoded and stored at once; the rest wait for a slot.
+func (s *photoService) Upload(ctx context.Context, userID string, file io.Reader) (*photo.Photo, error) {
+	data, err := s.validate(file)
+	if err != nil {
+		return nil, err
+	}
+
+	// Decoded images and their variants are held until stored, so the slot
+	// is kept until the upload is done.
+	if err := s.cfg.Slots.Acquire(ctx); err != nil {
+		return nil, err
+	}
+	defer s.cfg.Slots.Release()
+
+	outputs, err := s.processor.Process(data)
+	if err != nil {
+		switch {
+		case errors.Is(err, imaging.ErrUnsupportedImage):
+			return nil, ErrInvalidImage
+		case errors.Is(err, imaging.ErrTooManyPixels):
+			return nil, ErrImageTooLarge
+		}
+		return nil, err
+	}
+
+	prefix := photo.KeyPrefix(userID, uuid.New().String())
+	full := outputs[len(outputs)-1]
+	p := &photo.Photo{
+		UserID:     userID,
+		StorageKey: prefix + photo.VariantFull + ".jpg",
+		Width:      &full.Width,
+		Height:     &full.Height,
+	}
+	if err := s.repo.Create(ctx, p, s.cfg.MaxCount); err != nil {
+		return nil, err
+	}
+
+	var uploaded []string
+	for _, out := range outputs {
+		key := prefix + out.Name + ".jpg"
+		if _, err := s.storage.Upload(ctx, bytes.NewReader(out.Data), int64(len(out.Data)), imaging.ContentType, key); err != nil {
+			// A failed photo is never served, so the variants already
+			// stored would only be orphans.
+			s.removeObjects(ctx, uploaded)
+			if markErr := s.repo.MarkFailed(ctx, p.ID); markErr != nil {
+				return nil, errors.Join(err, markErr)
+			}
+			return nil, err
+		}
+		uploaded = append(uploaded, key)
+	}
+
+	if err := s.repo.MarkReady(ctx, p); err != nil {
+		return nil, err
+	}
+
+	// A photo that is never classified stays pending and shows up in the
+	// admin queue, so a publish failure does not fail the upload.
+	_ = s.producer.Publish(ctx, ModerationMessage{PhotoID: p.ID})
+
+	s.resolveURLs(ctx, p)
+	return p, nil
+}
+
+// validate reads the upload and checks its size, real format and dimensions.
+// Dimensions are read from the header before anything is fully decoded; the
+// processor enforces MaxPixels the same way.
+func (s *photoService) validate(file io.Reader) ([]byte, error) {
+	data, err := io.ReadAll(io.LimitReader(file, s.cfg.MaxBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > s.cfg.MaxBytes {
+		return nil, ErrFileTooLarge
+	}
+
+	if !allowedFormats[imaging.Sniff(data)] {
+		return nil, ErrUnsupportedType
+	}
+
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrInvalidImage
+	}
+	if min(cfg.Width, cfg.Height) < s.cfg.MinDimension {
+		return nil, ErrImageTooSmall
+	}
+	if max(cfg.Width, cfg.Height) > s.cfg.MaxDimension {
+		return nil, ErrImageTooLarge
+	}
+	return data, nil
+}
+
+func (s *photoService) List(ctx context.Context, userID string) ([]*photo.Photo, error) {
+	photos, err := s.repo.ListByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range photos {
+		s.resolveURLs(ctx, p)
+	}
+	if photos == nil {
+		photos = []*photo.Photo{}
+	}
+	return photos, nil
+}
+
+func (s *photoService) Reorder(ctx context.Context, userID string, photoIDs []string) ([]*photo.Photo, error) {
+	if err := s.repo.Reorder(ctx, userID, photoIDs); err != nil {
+		return nil, err
+	}
+	return s.List(ctx, userID)
+}
+
+func (s *photoService) SetPrimary(ctx context.Context, userID, photoID string) ([]*photo.Photo, error) {
+	if err := s.repo.SetPrimary(ctx, userID, photoID); err != nil {
+		return nil, err
+	}
+	return s.List(ctx, userID)
+}
+
+// Delete removes the stored objects before the row. Object deletion is
+// idempotent, so if the row delete fails the client can simply retry.
+func (s *photoService) Delete(ctx context.Context, userID, photoID string) error {
+	p, err := s.repo.GetByID(ctx, userID, photoID)
+	if err != nil {
+		return err
+	}
+	// The row goes first: a leftover object is never served, but a row
+	// whose objects are gone would be.
+	if err := s.repo.Delete(ctx, userID, photoID); err != nil {
+		return err
+	}
+	s.removeObjects(ctx, photo.VariantKeys(p.StorageKey))
+	return nil
+}
+
+// removeObjects deletes stored objects no row refers to any more. It is
+// best effort: an object that cannot be removed is only wasted space.
+func (s *photoService) removeObjects(ctx context.Context, keys []string) {
+	for _, key := range keys {
+		_ = s.storage.Delete(ctx, key)
+	}
+}
+
+// ProcessModeration runs the classifier on a newly uploaded photo. Photos
+// that were already decided, or that the classifier defers, are left alone.
+func (s *photoService) ProcessModeration(ctx context.Context, photoID string) error {
+	p, err := s.repo.FindByID(ctx, photoID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			// Deleted before it was processed.
+			return nil
+		}
+		return err
+	}
+	if p.Status != photo.StatusReady || p.ModerationStatus != photo.ModerationPending {
+		return nil
+	}
+
+	data, err := s.storage.Download(ctx, photo.VariantKey(p.StorageKey, photo.VariantMedium))
+	if err != nil {
+		return err
+	}
+	verdict, err := s.classifier.Classify(ctx, data)
+	if err != nil {
+		return err
+	}
+
+	switch verdict.Decision {
+	case moderation.DecisionApprove:
+		_, err = s.repo.SetModeration(ctx, p.ID, photo.ModerationApproved, nil, nil)
+	case moderation.DecisionReject:
+		reason := verdict.Reason
+		_, err = s.repo.SetModeration(ctx, p.ID, photo.ModerationRejected, &reason, nil)
+	}
+	return err
+}
+
+func (s *photoService) ListModerationQueue(ctx context.Context, limit, offset int) ([]*photo.Photo, error) {
+	if limit <= 0 {
+		limit = DefaultQueueLimit
+	}
+	limit = min(limit, MaxQueueLimit)
+	offset = max(offset, 0)
+
+	photos, err := s.repo.ListModerationQueue(ctx, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range photos {
+		s.resolveURLs(ctx, p)
+	}
+	if photos == nil {
+		photos = []*photo.Photo{}
+	}
+	return photos, nil
+}
+
+func (s *photoService) Approve(ctx context.Context, moderatorID, photoID string) (*photo.Photo, error) {
+	p, err := s.repo.SetModeration(ctx, photoID, photo.ModerationApproved, nil, &moderatorID)
+	if err != nil {
+		return nil, err
+	}
+	s.resolveURLs(ctx, p)
+	return p, nil
+}
+
+func (s *photoService) Reject(ctx context.Context, moderatorID, photoID, reason string) (*photo.Photo, error) {
+	if reason == "" {
+		return nil, ErrReasonRequired
+	}
+	p, err := s.repo.SetModeration(ctx, photoID, photo.ModerationRejected, &reason, &moderatorID)
+	if err != nil {
+		return nil, err
+	}
+	s.resolveURLs(ctx, p)
+	return p, nil
+}
+
+// resolveURLs fills URL and Variants from the storage key. A failure leaves
+// them empty; the photo metadata is still useful to the caller.
+func (s *photoService) resolveURLs(ctx context.Context, p *photo.Photo) {
+	v, err := photo.ResolveVariants(ctx, s.storage, p.StorageKey)
+	if err != nil {
+		return
+	}
+	p.URL = v.Full
+	p.Variants = v
+}
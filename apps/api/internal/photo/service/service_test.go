@@ -0,0 +1,507 @@
+package service_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image"
+	"image/color/palette"
+	"image/gif"
+	"image/png"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kisssonik/hearts/internal/photo"
+	"github.com/kisssonik/hearts/internal/photo/moderation"
+	"github.com/kisssonik/hearts/internal/photo/repository"
+	"github.com/kisssonik/hearts/internal/photo/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockPhotoRepository is a mock implementation of repository.PhotoRepository
+type MockPhotoRepository struct {
+	mock.Mock
+}
+
+func (m *MockPhotoRepository) Create(ctx context.Context, p *photo.Photo, limit int) error {
+	args := m.Called(ctx, p, limit)
+	return args.Error(0)
+}
+
+func (m *MockPhotoRepository) MarkReady(ctx context.Context, p *photo.Photo) error {
+	args := m.Called(ctx, p)
+	return args.Error(0)
+}
+
+func (m *MockPhotoRepository) MarkFailed(ctx context.Context, photoID string) error {
+	args := m.Called(ctx, photoID)
+	return args.Error(0)
+}
+
+func (m *MockPhotoRepository) GetByID(ctx context.Context, userID, photoID string) (*photo.Photo, error) {
+	args := m.Called(ctx, userID, photoID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*photo.Photo), args.Error(1)
+}
+
+func (m *MockPhotoRepository) ListByUserID(ctx context.Context, userID string) ([]*photo.Photo, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*photo.Photo), args.Error(1)
+}
+
+func (m *MockPhotoRepository) Reorder(ctx context.Context, userID string, photoIDs []string) error {
+	args := m.Called(ctx, userID, photoIDs)
+	return args.Error(0)
+}
+
+func (m *MockPhotoRepository) SetPrimary(ctx context.Context, userID, photoID string) error {
+	args := m.Called(ctx, userID, photoID)
+	return args.Error(0)
+}
+
+func (m *MockPhotoRepository) Delete(ctx context.Context, userID, photoID string) error {
+	args := m.Called(ctx, userID, photoID)
+	return args.Error(0)
+}
+
+func (m *MockPhotoRepository) FindByID(ctx context.Context, photoID string) (*photo.Photo, error) {
+	args := m.Called(ctx, photoID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*photo.Photo), args.Error(1)
+}
+
+func (m *MockPhotoRepository) SetModeration(ctx context.Context, photoID, status string, reason, moderatorID *string) (*photo.Photo, error) {
+	args := m.Called(ctx, photoID, status, reason, moderatorID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*photo.Photo), args.Error(1)
+}
+
+func (m *MockPhotoRepository) ListModerationQueue(ctx context.Context, limit, offset int) ([]*photo.Photo, error) {
+	args := m.Called(ctx, limit, offset)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*photo.Photo), args.Error(1)
+}
+
+// MockProducer
+type MockProducer struct {
+	mock.Mock
+}
+
+func (m *MockProducer) Publish(ctx context.Context, message interface{}) error {
+	args := m.Called(ctx, message)
+	return args.Error(0)
+}
+
+func (m *MockProducer) Close() error {
+	args := m.Called()
+	return args.Error(0)
+}
+
+// MockClassifier
+type MockClassifier struct {
+	mock.Mock
+}
+
+func (m *MockClassifier) Classify(ctx context.Context, image []byte) (moderation.Verdict, error) {
+	args := m.Called(ctx, image)
+	return args.Get(0).(moderation.Verdict), args.Error(1)
+}
+
+// MockStorageProvider
+type MockStorageProvider struct {
+	mock.Mock
+}
+
+func (m *MockStorageProvider) Upload(ctx context.Context, file io.Reader, size int64, contentType, key string) (string, error) {
+	args := m.Called(ctx, file, size, contentType, key)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockStorageProvider) GetPresignedURL(ctx context.Context, key string) (string, error) {
+	args := m.Called(ctx, key)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockStorageProvider) Download(ctx context.Context, key string) ([]byte, error) {
+	args := m.Called(ctx, key)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]byte), args.Error(1)
+}
+
+func (m *MockStorageProvider) Delete(ctx context.Context, key string) error {
+	args := m.Called(ctx, key)
+	return args.Error(0)
+}
+
+func testPNG(t *testing.T, w, h int) []byte {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, w, h))); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestPhotoService_Upload(t *testing.T) {
+	mockRepo := new(MockPhotoRepository)
+	mockStorage := new(MockStorageProvider)
+	mockProducer := new(MockProducer)
+	s := service.NewPhotoService(mockRepo, mockStorage, mockProducer, new(MockClassifier), service.Config{})
+
+	ctx := context.Background()
+	data := testPNG(t, 2000, 1000)
+
+	mockRepo.On("Create", ctx, mock.MatchedBy(func(p *photo.Photo) bool {
+		return p.UserID == "user1" && strings.HasPrefix(p.StorageKey, "user1/") && strings.HasSuffix(p.StorageKey, "/full.jpg") &&
+			*p.Width == 1600 && *p.Height == 800
+	}), service.DefaultMaxCount).Run(func(args mock.Arguments) {
+		args.Get(1).(*photo.Photo).ID = "photo1"
+	}).Return(nil)
+
+	var uploaded []string
+	mockStorage.On("Upload", ctx, mock.Anything, mock.Anything, "image/jpeg", mock.Anything).Run(func(args mock.Arguments) {
+		uploaded = append(uploaded, args.String(4))
+	}).Return("key", nil)
+	mockRepo.On("MarkReady", ctx, mock.Anything).Return(nil)
+	mockProducer.On("Publish", ctx, service.ModerationMessage{PhotoID: "photo1"}).Return(nil)
+	mockStorage.On("GetPresignedURL", ctx, mock.Anything).Return("http://url", nil)
+
+	p, err := s.Upload(ctx, "user1", bytes.NewReader(data))
+
+	assert.NoError(t, err)
+	assert.Equal(t, "photo1", p.ID)
+	assert.Equal(t, "http://url", p.URL)
+	assert.NotNil(t, p.Variants)
+	assert.Len(t, uploaded, 3)
+	assert.ElementsMatch(t, photo.VariantKeys(p.StorageKey), uploaded)
+	mockRepo.AssertExpectations(t)
+	mockStorage.AssertExpectations(t)
+	mockProducer.AssertExpectations(t)
+}
+
+func TestPhotoService_Upload_Validation(t *testing.T) {
+	var gifData bytes.Buffer
+	if err := gif.Encode(&gifData, image.NewPaletted(image.Rect(0, 0, 400, 400), palette.Plan9), nil); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		cfg     service.Config
+		data    []byte
+		wantErr error
+	}{
+		{"too many bytes", service.Config{MaxBytes: 100}, testPNG(t, 400, 400), service.ErrFileTooLarge},
+		{"gif not allowed", service.Config{}, gifData.Bytes(), service.ErrUnsupportedType},
+		{"unknown format", service.Config{}, []byte("<svg></svg>"), service.ErrUnsupportedType},
+		{"truncated png", service.Config{}, []byte("\x89PNG\r\n\x1a\n"), service.ErrInvalidImage},
+		{"too small", service.Config{}, testPNG(t, 1000, 200), service.ErrImageTooSmall},
+		{"too large", service.Config{MaxDimension: 500}, testPNG(t, 600, 400), service.ErrImageTooLarge},
+		{"too many pixels", service.Config{MaxPixels: 200_000}, testPNG(t, 600, 400), service.ErrImageTooLarge},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockPhotoRepository)
+			s := service.NewPhotoService(mockRepo, new(MockStorageProvider), new(MockProducer), new(MockClassifier), tt.cfg)
+
+			_, err := s.Upload(context.Background(), "user1", bytes.NewReader(tt.data))
+
+			assert.ErrorIs(t, err, tt.wantErr)
+			mockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestPhotoService_Upload_WaitsForSlot(t *testing.T) {
+	mockRepo := new(MockPhotoRepository)
+	mockStorage := new(MockStorageProvider)
+	mockProducer := new(MockProducer)
+	s := service.NewPhotoService(mockRepo, mockStorage, mockProducer, new(MockClassifier), service.Config{MaxInFlight: 1})
+
+	data := testPNG(t, 400, 400)
+	storing, release := make(chan struct{}), make(chan struct{})
+	var once sync.Once
+	mockRepo.On("Create", mock.Anything, mock.Anything, service.DefaultMaxCount).Return(nil)
+	mockStorage.On("Upload", mock.Anything, mock.Anything, mock.Anything, "image/jpeg", mock.Anything).Run(func(args mock.Arguments) {
+		once.Do(func() { close(storing) })
+		<-release
+	}).Return("key", nil)
+	mockRepo.On("MarkReady", mock.Anything, mock.Anything).Return(nil)
+	mockProducer.On("Publish", mock.Anything, mock.Anything).Return(nil)
+	mockStorage.On("GetPresignedURL", mock.Anything, mock.Anything).Return("http://url", nil)
+
+	done := make(chan error)
+	go func() {
+		_, err := s.Upload(context.Background(), "user1", bytes.NewReader(data))
+		done <- err
+	}()
+	<-storing
+
+	// The only slot is taken, so a second upload waits until it gives up.
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err := s.Upload(ctx, "user1", bytes.NewReader(data))
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	mockRepo.AssertNumberOfCalls(t, "Create", 1)
+
+	close(release)
+	assert.NoError(t, <-done)
+
+	// The slot is free again.
+	_, err = s.Upload(context.Background(), "user1", bytes.NewReader(data))
+	assert.NoError(t, err)
+}
+
+func TestPhotoService_Upload_LimitReached(t *testing.T) {
+	mockRepo := new(MockPhotoRepository)
+	mockStorage := new(MockStorageProvider)
+	s := service.NewPhotoService(mockRepo, mockStorage, new(MockProducer), new(MockClassifier), service.Config{MaxCount: 3})
+
+	ctx := context.Background()
+	mockRepo.On("Create", ctx, mock.Anything, 3).Return(repository.ErrLimitReached)
+
+	_, err := s.Upload(ctx, "user1", bytes.NewReader(testPNG(t, 400, 400)))
+
+	assert.ErrorIs(t, err, repository.ErrLimitReached)
+	mockStorage.AssertNotCalled(t, "Upload", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestPhotoService_Upload_StorageFailure(t *testing.T) {
+	mockRepo := new(MockPhotoRepository)
+	mockStorage := new(MockStorageProvider)
+	s := service.NewPhotoService(mockRepo, mockStorage, new(MockProducer), new(MockClassifier), service.Config{})
+
+	ctx := context.Background()
+	uploadErr := errors.New("storage down")
+
+	mockRepo.On("Create", ctx, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		args.Get(1).(*photo.Photo).ID = "photo1"
+	}).Return(nil)
+	// The first variant is stored before the storage goes down.
+	var stored string
+	mockStorage.On("Upload", ctx, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		stored = args.String(4)
+	}).Return("", nil).Once()
+	mockStorage.On("Upload", ctx, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return("", uploadErr)
+	mockStorage.On("Delete", ctx, mock.Anything).Return(nil)
+	mockRepo.On("MarkFailed", ctx, "photo1").Return(nil)
+
+	_, err := s.Upload(ctx, "user1", bytes.NewReader(testPNG(t, 400, 400)))
+
+	assert.ErrorIs(t, err, uploadErr)
+	mockRepo.AssertNotCalled(t, "MarkReady", mock.Anything, mock.Anything)
+	mockRepo.AssertExpectations(t)
+	mockStorage.AssertCalled(t, "Delete", ctx, stored)
+	mockStorage.AssertNumberOfCalls(t, "Delete", 1)
+}
+
+func TestPhotoService_Reorder(t *testing.T) {
+	mockRepo := new(MockPhotoRepository)
+	mockStorage := new(MockStorageProvider)
+	s := service.NewPhotoService(mockRepo, mockStorage, new(MockProducer), new(MockClassifier), service.Config{})
+
+	ctx := context.Background()
+	ids := []string{"b", "a"}
+	mockRepo.On("Reorder", ctx, "user1", ids).Return(nil)
+	mockRepo.On("ListByUserID", ctx, "user1").Return([]*photo.Photo{
+		{ID: "b", StorageKey: "kb", Position: 0},
+		{ID: "a", StorageKey: "ka", Position: 1},
+	}, nil)
+	mockStorage.On("GetPresignedURL", ctx, "kb").Return("http://b", nil)
+	mockStorage.On("GetPresignedURL", ctx, "ka").Return("http://a", nil)
+
+	photos, err := s.Reorder(ctx, "user1", ids)
+
+	assert.NoError(t, err)
+	assert.Len(t, photos, 2)
+	assert.Equal(t, "http://b", photos[0].URL)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestPhotoService_Reorder_Invalid(t *testing.T) {
+	mockRepo := new(MockPhotoRepository)
+	mockStorage := new(MockStorageProvider)
+	s := service.NewPhotoService(mockRepo, mockStorage, new(MockProducer), new(MockClassifier), service.Config{})
+
+	ctx := context.Background()
+	mockRepo.On("Reorder", ctx, "user1", []string{"a"}).Return(repository.ErrInvalidOrder)
+
+	_, err := s.Reorder(ctx, "user1", []string{"a"})
+
+	assert.ErrorIs(t, err, repository.ErrInvalidOrder)
+	mockRepo.AssertNotCalled(t, "ListByUserID", mock.Anything, mock.Anything)
+}
+
+func TestPhotoService_Delete(t *testing.T) {
+	mockRepo := new(MockPhotoRepository)
+	mockStorage := new(MockStorageProvider)
+	s := service.NewPhotoService(mockRepo, mockStorage, new(MockProducer), new(MockClassifier), service.Config{})
+
+	ctx := context.Background()
+	mockRepo.On("GetByID", ctx, "user1", "photo1").Return(&photo.Photo{ID: "photo1", StorageKey: "user1/abc/full.jpg"}, nil)
+	mockStorage.On("Delete", ctx, "user1/abc/thumb.jpg").Return(nil)
+	mockStorage.On("Delete", ctx, "user1/abc/medium.jpg").Return(nil)
+	mockStorage.On("Delete", ctx, "user1/abc/full.jpg").Return(nil)
+	mockRepo.On("Delete", ctx, "user1", "photo1").Return(nil)
+
+	err := s.Delete(ctx, "user1", "photo1")
+
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+	mockStorage.AssertExpectations(t)
+}
+
+func TestPhotoService_Delete_NotFound(t *testing.T) {
+	mockRepo := new(MockPhotoRepository)
+	mockStorage := new(MockStorageProvider)
+	s := service.NewPhotoService(mockRepo, mockStorage, new(MockProducer), new(MockClassifier), service.Config{})
+
+	ctx := context.Background()
+	mockRepo.On("GetByID", ctx, "user1", "missing").Return(nil, repository.ErrNotFound)
+
+	err := s.Delete(ctx, "user1", "missing")
+
+	assert.ErrorIs(t, err, repository.ErrNotFound)
+	mockStorage.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+}
+
+func TestPhotoService_Delete_RowFirst(t *testing.T) {
+	t.Run("row delete fails", func(t *testing.T) {
+		mockRepo := new(MockPhotoRepository)
+		mockStorage := new(MockStorageProvider)
+		s := service.NewPhotoService(mockRepo, mockStorage, new(MockProducer), new(MockClassifier), service.Config{})
+
+		ctx := context.Background()
+		dbErr := errors.New("db down")
+		mockRepo.On("GetByID", ctx, "user1", "photo1").Return(&photo.Photo{ID: "photo1", StorageKey: "user1/abc/full.jpg"}, nil)
+		mockRepo.On("Delete", ctx, "user1", "photo1").Return(dbErr)
+
+		err := s.Delete(ctx, "user1", "photo1")
+
+		assert.ErrorIs(t, err, dbErr)
+		mockStorage.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+	})
+
+	t.Run("object delete fails", func(t *testing.T) {
+		mockRepo := new(MockPhotoRepository)
+		mockStorage := new(MockStorageProvider)
+		s := service.NewPhotoService(mockRepo, mockStorage, new(MockProducer), new(MockClassifier), service.Config{})
+
+		ctx := context.Background()
+		mockRepo.On("GetByID", ctx, "user1", "photo1").Return(&photo.Photo{ID: "photo1", StorageKey: "user1/abc/full.jpg"}, nil)
+		mockRepo.On("Delete", ctx, "user1", "photo1").Return(nil)
+		mockStorage.On("Delete", ctx, mock.Anything).Return(errors.New("storage down"))
+
+		err := s.Delete(ctx, "user1", "photo1")
+
+		// The photo is gone for the user; the objects are only leftovers.
+		assert.NoError(t, err)
+		mockStorage.AssertNumberOfCalls(t, "Delete", 3)
+	})
+}
+
+func TestPhotoService_ProcessModeration(t *testing.T) {
+	tests := []struct {
+		name       string
+		verdict    moderation.Verdict
+		wantStatus string
+	}{
+		{"approve", moderation.Verdict{Decision: moderation.DecisionApprove}, photo.ModerationApproved},
+		{"reject", moderation.Verdict{Decision: moderation.DecisionReject, Reason: "nudity"}, photo.ModerationRejected},
+		{"review", moderation.Verdict{Decision: moderation.DecisionReview}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockPhotoRepository)
+			mockStorage := new(MockStorageProvider)
+			mockClassifier := new(MockClassifier)
+			s := service.NewPhotoService(mockRepo, mockStorage, new(MockProducer), mockClassifier, service.Config{})
+
+			ctx := context.Background()
+			pending := &photo.Photo{ID: "photo1", StorageKey: "user1/abc/full.jpg", Status: photo.StatusReady, ModerationStatus: photo.ModerationPending}
+			mockRepo.On("FindByID", ctx, "photo1").Return(pending, nil)
+			mockStorage.On("Download", ctx, "user1/abc/medium.jpg").Return([]byte("jpeg"), nil)
+			mockClassifier.On("Classify", ctx, []byte("jpeg")).Return(tt.verdict, nil)
+			if tt.wantStatus != "" {
+				mockRepo.On("SetModeration", ctx, "photo1", tt.wantStatus, mock.Anything, (*string)(nil)).Return(pending, nil)
+			}
+
+			err := s.ProcessModeration(ctx, "photo1")
+
+			assert.NoError(t, err)
+			mockRepo.AssertExpectations(t)
+			if tt.wantStatus == "" {
+				mockRepo.AssertNotCalled(t, "SetModeration", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func TestPhotoService_ProcessModeration_AlreadyDecided(t *testing.T) {
+	mockRepo := new(MockPhotoRepository)
+	mockStorage := new(MockStorageProvider)
+	s := service.NewPhotoService(mockRepo, mockStorage, new(MockProducer), new(MockClassifier), service.Config{})
+
+	ctx := context.Background()
+	mockRepo.On("FindByID", ctx, "photo1").Return(&photo.Photo{ID: "photo1", Status: photo.StatusReady, ModerationStatus: photo.ModerationApproved}, nil)
+
+	err := s.ProcessModeration(ctx, "photo1")
+
+	assert.NoError(t, err)
+	mockStorage.AssertNotCalled(t, "Download", mock.Anything, mock.Anything)
+}
+
+func TestPhotoService_Reject(t *testing.T) {
+	mockRepo := new(MockPhotoRepository)
+	mockStorage := new(MockStorageProvider)
+	s := service.NewPhotoService(mockRepo, mockStorage, new(MockProducer), new(MockClassifier), service.Config{})
+
+	ctx := context.Background()
+	_, err := s.Reject(ctx, "admin", "photo1", "")
+	assert.ErrorIs(t, err, service.ErrReasonRequired)
+
+	reason := "not a photo of you"
+	moderator := "admin"
+	mockRepo.On("SetModeration", ctx, "photo1", photo.ModerationRejected, &reason, &moderator).
+		Return(&photo.Photo{ID: "photo1", StorageKey: "k", ModerationStatus: photo.ModerationRejected, ModerationReason: &reason}, nil)
+	mockStorage.On("GetPresignedURL", ctx, "k").Return("http://url", nil)
+
+	p, err := s.Reject(ctx, "admin", "photo1", reason)
+
+	assert.NoError(t, err)
+	assert.Equal(t, photo.ModerationRejected, p.ModerationStatus)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestPhotoService_ListModerationQueue_ClampsLimit(t *testing.T) {
+	mockRepo := new(MockPhotoRepository)
+	mockStorage := new(MockStorageProvider)
+	s := service.NewPhotoService(mockRepo, mockStorage, new(MockProducer), new(MockClassifier), service.Config{})
+
+	ctx := context.Background()
+	mockRepo.On("ListModerationQueue", ctx, service.MaxQueueLimit, 0).Return(nil, nil)
+
+	photos, err := s.ListModerationQueue(ctx, 1000, -5)
+
+	assert.NoError(t, err)
+	assert.Empty(t, photos)
+	mockRepo.AssertExpectations(t)
+}
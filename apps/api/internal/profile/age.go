@@ -0,0 +1,28 @@
+package profile
+
+import "time"
+
+// MaxAge is the oldest age accepted for a birth date; anything older is
+// treated as a typo.
+const MaxAge = 120
+
+// AgeAt returns the age in whole years of someone born on birthDate at the
+// given moment.
+func AgeAt(birthDate, now time.Time) int {
+	age := now.Year() - birthDate.Year()
+	// Compare month/day rather than YearDay so leap years don't shift birthdays.
+	if now.Month() < birthDate.Month() || (now.Month() == birthDate.Month() && now.Day() < birthDate.Day()) {
+		age--
+	}
+	return age
+}
+
+// ComputeAge fills Age from BirthDate.
+func (p *Profile) ComputeAge(now time.Time) {
+	if p.BirthDate == nil {
+		p.Age = nil
+		return
+	}
+	age := AgeAt(*p.BirthDate, now)
+	p.Age = &age
+}
@@ -0,0 +1,21 @@
+package profile_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kisssonik/hearts/internal/profile"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAgeAt(t *testing.T) {
+	birth := time.Date(2000, 6, 15, 0, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, 23, profile.AgeAt(birth, time.Date(2024, 6, 14, 12, 0, 0, 0, time.UTC)))
+	assert.Equal(t, 24, profile.AgeAt(birth, time.Date(2024, 6, 15, 0, 0, 0, 0, time.UTC)))
+
+	// Leap-day birthdays become a year older on March 1st in non-leap years.
+	leap := time.Date(2004, 2, 29, 0, 0, 0, 0, time.UTC)
+	assert.Equal(t, 18, profile.AgeAt(leap, time.Date(2023, 2, 28, 0, 0, 0, 0, time.UTC)))
+	assert.Equal(t, 19, profile.AgeAt(leap, time.Date(2023, 3, 1, 0, 0, 0, 0, time.UTC)))
+}
@@ -0,0 +1,71 @@
+package profile
+
+import (
+	"slices"
+	"strings"
+)
+
+// Education levels.
+var EducationLevels = []string{"high_school", "trade_school", "bachelors", "masters", "doctorate"}
+
+// Relationship goals.
+var RelationshipGoals = []string{"long_term", "long_term_open", "short_term", "friendship", "not_sure"}
+
+// Smoking and drinking habits.
+var (
+	SmokingHabits  = []string{"never", "socially", "regularly"}
+	DrinkingHabits = []string{"never", "socially", "regularly"}
+)
+
+// Stances on children.
+var KidsStances = []string{"have", "want", "dont_want", "open"}
+
+// Bounds for structured attributes.
+const (
+	MaxLanguages        = 10
+	MaxOccupationLength = 100 // occupation is VARCHAR(100)
+)
+
+// languageCodes are the ISO 639-1 language codes.
+var languageCodes = strings.Fields(`
+	aa ab ae af ak am an ar as av ay az ba be bg bh bi bm bn bo br bs ca ce ch co cr cs cu cv cy
+	da de dv dz ee el en eo es et eu fa ff fi fj fo fr fy ga gd gl gn gu gv ha he hi ho hr ht hu
+	hy hz ia id ie ig ii ik io is it iu ja jv ka kg ki kj kk kl km kn ko kr ks ku kv kw ky la lb
+	lg li ln lo lt lu lv mg mh mi mk ml mn mr ms mt my na nb nd ne ng nl nn no nr nv ny oc oj om
+	or os pa pi pl ps pt qu rm rn ro ru rw sa sc sd se sg si sk sl sm sn so sq sr ss st su sv sw
+	ta te tg th ti tk tl tn to tr ts tt tw ty ug uk ur uz ve vi vo wa wo xh yi yo za zh zu
+`)
+
+// IsValidLanguage reports whether code is an ISO 639-1 language code.
+func IsValidLanguage(code string) bool {
+	_, ok := slices.BinarySearch(languageCodes, code)
+	return ok
+}
+
+// IsValidEducation reports whether v is a known education level.
+func IsValidEducation(v string) bool { return slices.Contains(EducationLevels, v) }
+
+// IsValidRelationshipGoal reports whether v is a known relationship goal.
+func IsValidRelationshipGoal(v string) bool { return slices.Contains(RelationshipGoals, v) }
+
+// IsValidSmoking reports whether v is a known smoking habit.
+func IsValidSmoking(v string) bool { return slices.Contains(SmokingHabits, v) }
+
+// IsValidDrinking reports whether v is a known drinking habit.
+func IsValidDrinking(v string) bool { return slices.Contains(DrinkingHabits, v) }
+
+// IsValidKids reports whether v is a known stance on children.
+func IsValidKids(v string) bool { return slices.Contains(KidsStances, v) }
+
+// NormalizeLanguages lowercases codes and removes duplicates, keeping the
+// first occurrence of each.
+func NormalizeLanguages(codes []string) []string {
+	normalized := make([]string, 0, len(codes))
+	for _, code := range codes {
+		code = strings.ToLower(strings.TrimSpace(code))
+		if !slices.Contains(normalized, code) {
+			normalized = append(normalized, code)
+		}
+	}
+	return normalized
+}
@@ -0,0 +1,37 @@
+package profile_test
+
+import (
+	"testing"
+
+	"github.com/kisssonik/hearts/internal/profile"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsValidLanguage(t *testing.T) {
+	for _, code := range []string{"en", "de", "zh", "aa", "zu"} {
+		assert.True(t, profile.IsValidLanguage(code), code)
+	}
+	for _, code := range []string{"", "EN", "eng", "xx", "en-US"} {
+		assert.False(t, profile.IsValidLanguage(code), code)
+	}
+}
+
+func TestNormalizeLanguages(t *testing.T) {
+	assert.Equal(t, []string{"en", "fr"}, profile.NormalizeLanguages([]string{" EN", "fr", "en"}))
+	assert.Empty(t, profile.NormalizeLanguages(nil))
+}
+
+func TestProjectFor_HidesAttributes(t *testing.T) {
+	education := "masters"
+	p := &profile.Profile{
+		UserID:          "owner",
+		Languages:       []string{"en"},
+		Education:       &education,
+		FieldVisibility: map[string]string{profile.FieldEducation: profile.PrivacyNobody},
+	}
+
+	p.ProjectFor(&profile.Profile{UserID: "viewer"})
+
+	assert.Nil(t, p.Education)
+	assert.Equal(t, []string{"en"}, p.Languages)
+}
@@ -0,0 +1,62 @@
+package profile
+
+import "strings"
+
+// Items a profile can be missing, in the order the onboarding checklist
+// shows them.
+const (
+	ItemPhotos    = "photos"
+	ItemBio       = "bio"
+	ItemPrompts   = "prompts"
+	ItemBirthDate = "birthDate"
+	ItemLocation  = "location"
+)
+
+// completenessWeights sum to 100. The completeness_score column is kept by
+// the profiles_completeness_update trigger, which must use the same weights.
+var completenessWeights = []struct {
+	item   string
+	weight int
+}{
+	{ItemPhotos, 30},
+	{ItemBio, 20},
+	{ItemPrompts, 20},
+	{ItemBirthDate, 15},
+	{ItemLocation, 15},
+}
+
+// Completeness summarises how much of a profile has been filled in.
+type Completeness struct {
+	Score   int      `json:"score"`   // 0..100
+	Missing []string `json:"missing"` // Item* constants, in checklist order
+}
+
+// has reports whether p has filled in item.
+func (p *Profile) has(item string) bool {
+	switch item {
+	case ItemPhotos:
+		return len(p.Photos) > 0
+	case ItemBio:
+		return strings.TrimSpace(p.Bio) != ""
+	case ItemPrompts:
+		return len(p.SelfDescribedStrengths) > 0 && len(p.SelfDescribedFlaws) > 0
+	case ItemBirthDate:
+		return p.BirthDate != nil
+	case ItemLocation:
+		return p.Latitude != nil && p.Longitude != nil
+	}
+	return false
+}
+
+// ComputeCompleteness fills Completeness from the profile's fields.
+func (p *Profile) ComputeCompleteness() {
+	c := &Completeness{Missing: []string{}}
+	for _, w := range completenessWeights {
+		if p.has(w.item) {
+			c.Score += w.weight
+		} else {
+			c.Missing = append(c.Missing, w.item)
+		}
+	}
+	p.Completeness = c
+}
@@ -0,0 +1,41 @@
+package profile_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kisssonik/hearts/internal/profile"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestComputeCompleteness(t *testing.T) {
+	p := &profile.Profile{Bio: "   "}
+	p.ComputeCompleteness()
+
+	assert.Equal(t, 0, p.Completeness.Score)
+	assert.Equal(t, []string{
+		profile.ItemPhotos, profile.ItemBio, profile.ItemPrompts, profile.ItemBirthDate, profile.ItemLocation,
+	}, p.Completeness.Missing)
+
+	// Prompts need both strengths and flaws.
+	birthDate := time.Date(1995, 1, 1, 0, 0, 0, 0, time.UTC)
+	lat, lon := 52.52, 13.40
+	p = &profile.Profile{
+		Photos:                 []string{"a.jpg"},
+		Bio:                    "Hello",
+		SelfDescribedStrengths: []string{"Patient"},
+		BirthDate:              &birthDate,
+		Latitude:               &lat,
+		Longitude:              &lon,
+	}
+	p.ComputeCompleteness()
+
+	assert.Equal(t, 80, p.Completeness.Score)
+	assert.Equal(t, []string{profile.ItemPrompts}, p.Completeness.Missing)
+
+	p.SelfDescribedFlaws = []string{"Stubborn"}
+	p.ComputeCompleteness()
+
+	assert.Equal(t, 100, p.Completeness.Score)
+	assert.Empty(t, p.Completeness.Missing)
+}
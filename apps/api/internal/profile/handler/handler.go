@@ -1,14 +1,19 @@
 package handler
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"mime"
 	"net/http"
-	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 
-	"github.com/google/uuid"
+	"github.com/kisssonik/hearts/internal/photo"
 	"github.com/kisssonik/hearts/internal/profile"
 	"github.com/kisssonik/hearts/internal/profile/repository"
 	"github.com/kisssonik/hearts/internal/profile/service"
@@ -17,12 +22,6 @@ import (
 	"go.uber.org/zap"
 )
 
-// UploadPhotoResponse represents the response for photo upload.
-type UploadPhotoResponse struct {
-	Key string `json:"key"`
-	URL string `json:"url"`
-}
-
 type ProfileHandler struct {
 	service service.ProfileService
 	storage storage.Provider
@@ -37,86 +36,28 @@ func (h *ProfileHandler) enrichProfile(ctx context.Context, p *profile.Profile)
 	if p == nil {
 		return
 	}
+	p.PhotoVariants = make([]*photo.Variants, len(p.Photos))
 	for i, photoKey := range p.Photos {
-		url, err := h.storage.GetPresignedURL(ctx, photoKey)
+		variants, err := photo.ResolveVariants(ctx, h.storage, photoKey)
 		if err != nil {
 			h.logger.Error("Failed to generate presigned URL", zap.String("key", photoKey), zap.Error(err))
 			continue
 		}
-		p.Photos[i] = url
+		p.Photos[i] = variants.Full
+		p.PhotoVariants[i] = variants
 	}
 }
 
-// UploadPhoto handles photo upload.
-// @Summary Upload a photo
-// @Description Upload a photo for the user profile.
-// @Tags profiles
-// @Accept multipart/form-data
-// @Produce json
-// @Security ApiKeyAuth
-// @Param photo formData file true "Photo file"
-// @Success 200 {object} UploadPhotoResponse
-// @Failure 400 {string} string "Invalid file"
-// @Failure 401 {string} string "Unauthorized"
-// @Failure 500 {string} string "Internal server error"
-// @Router /profiles/upload [post]
-func (h *ProfileHandler) UploadPhoto(w http.ResponseWriter, r *http.Request) {
-	userID, ok := r.Context().Value(auth.UserIDKey).(string)
-	if !ok {
-		http.Error(w, "Unauthorized", http.StatusUnauthorized)
-		return
-	}
-
-	// Limit upload size to 10MB
-	r.ParseMultipartForm(10 << 20)
-
-	file, header, err := r.FormFile("photo")
-	if err != nil {
-		h.logger.Error("Failed to get file from form", zap.Error(err))
-		http.Error(w, "Invalid file", http.StatusBadRequest)
-		return
-	}
-	defer file.Close()
-
-	// Generate a unique filename
-	ext := filepath.Ext(header.Filename)
-	filename := fmt.Sprintf("%s/%s%s", userID, uuid.New().String(), ext)
-
-	// Upload to storage
-	key, err := h.storage.Upload(r.Context(), file, header.Size, header.Header.Get("Content-Type"), filename)
-	if err != nil {
-		h.logger.Error("Failed to upload file", zap.Error(err))
-		http.Error(w, "Failed to upload file", http.StatusInternalServerError)
-		return
-	}
-
-	// Generate a presigned URL for immediate display (optional)
-	// or just return the key/url. Since we are using MinIO locally,
-	// we might want to return a presigned URL or a public URL if configured.
-	// For now, let's return the key and a presigned URL.
-	url, err := h.storage.GetPresignedURL(r.Context(), key)
-	if err != nil {
-		h.logger.Error("Failed to generate presigned URL", zap.Error(err))
-		// We can still return the key, but the frontend might not be able to display it immediately without a URL.
-	}
-
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(UploadPhotoResponse{
-		Key: key,
-		URL: url,
-	})
-}
-
 // Create handles profile creation.
 // @Summary Create a profile
-// @Description Create a new profile for the authenticated user.
+// @Description Create a new profile for the authenticated user. birthDate is required and must meet the minimum age.
 // @Tags profiles
 // @Accept json
 // @Produce json
 // @Security ApiKeyAuth
 // @Param input body service.CreateProfileInput true "Profile creation input"
 // @Success 201 {object} profile.Profile
-// @Failure 400 {string} string "Invalid request body"
+// @Failure 400 {string} string "Invalid request body, missing birth date or below minimum age"
 // @Failure 401 {string} string "Unauthorized"
 // @Failure 409 {string} string "Profile already exists"
 // @Failure 500 {string} string "Internal server error"
@@ -146,6 +87,10 @@ func (h *ProfileHandler) Create(w http.ResponseWriter, r *http.Request) {
 			http.Error(w, "Profile already exists", http.StatusConflict)
 			return
 		}
+		if status, ok := validationStatus(err); ok {
+			http.Error(w, err.Error(), status)
+			return
+		}
 		h.logger.Error("Failed to create profile", zap.Error(err))
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 		return
@@ -153,6 +98,7 @@ func (h *ProfileHandler) Create(w http.ResponseWriter, r *http.Request) {
 
 	h.enrichProfile(r.Context(), p)
 
+	setETag(w, p)
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
 	json.NewEncoder(w).Encode(p)
@@ -179,29 +125,39 @@ func (h *ProfileHandler) GetMe(w http.ResponseWriter, r *http.Request) {
 
 	h.enrichProfile(r.Context(), p)
 
+	setETag(w, p)
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(p)
 }
 
 // Get handles retrieving a profile by user ID.
 // @Summary Get a profile
-// @Description Get a user's profile by their user ID.
+// @Description Get another user's public profile. Profiles that blocked the viewer, are paused
+// @Description or are otherwise hidden from the viewer are reported as not found.
 // @Tags profiles
 // @Produce json
+// @Security ApiKeyAuth
 // @Param userID path string true "User ID"
-// @Success 200 {object} profile.Profile
+// @Success 200 {object} profile.PublicProfile
 // @Failure 400 {string} string "User ID required"
+// @Failure 401 {string} string "Unauthorized"
 // @Failure 404 {string} string "Profile not found"
 // @Failure 500 {string} string "Internal server error"
 // @Router /profiles/{userID} [get]
 func (h *ProfileHandler) Get(w http.ResponseWriter, r *http.Request) {
+	viewerID, ok := r.Context().Value(auth.UserIDKey).(string)
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
 	userID := r.PathValue("userID")
 	if userID == "" {
 		http.Error(w, "User ID required", http.StatusBadRequest)
 		return
 	}
 
-	p, err := h.service.GetProfileByUserID(r.Context(), userID)
+	p, err := h.service.GetPublicProfile(r.Context(), viewerID, userID)
 	if err != nil {
 		if errors.Is(err, repository.ErrNotFound) {
 			http.Error(w, "Profile not found", http.StatusNotFound)
@@ -215,23 +171,31 @@ func (h *ProfileHandler) Get(w http.ResponseWriter, r *http.Request) {
 	h.enrichProfile(r.Context(), p)
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(p)
+	json.NewEncoder(w).Encode(p.Public())
 }
 
 // Update handles profile updates.
 // @Summary Update a profile
 // @Description Update the authenticated user's profile.
+// @Description visibility may be "visible", "paused" (hidden from discovery) or "incognito" (only shown to people you liked).
+// @Description birthDate is locked once set and can only be changed by support.
 // @Tags profiles
 // @Accept json
 // @Produce json
 // @Security ApiKeyAuth
+// @Description The If-Match header must carry the ETag from GET /profiles/me; if the profile changed since, the update is refused with 412.
+// @Param If-Match header string true "ETag of the profile being edited, or * for the current version"
 // @Param input body service.UpdateProfileInput true "Profile update input"
 // @Success 200 {object} profile.Profile
+// @Header 200 {string} ETag "Version of the updated profile"
 // @Failure 400 {string} string "Invalid request body"
 // @Failure 401 {string} string "Unauthorized"
 // @Failure 404 {string} string "Profile not found"
+// @Failure 409 {string} string "Birth date is locked"
+// @Failure 412 {string} string "Profile was modified"
+// @Failure 428 {string} string "If-Match header required"
 // @Failure 500 {string} string "Internal server error"
-// @Router /profiles [put]
+// @Router /profiles/me [put]
 func (h *ProfileHandler) Update(w http.ResponseWriter, r *http.Request) {
 	userID, ok := r.Context().Value(auth.UserIDKey).(string)
 	if !ok {
@@ -239,6 +203,11 @@ func (h *ProfileHandler) Update(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	version, ok := h.ifMatchVersion(w, r, userID)
+	if !ok {
+		return
+	}
+
 	var input service.UpdateProfileInput
 	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
 		h.logger.Error("Failed to decode request body", zap.Error(err))
@@ -246,12 +215,20 @@ func (h *ProfileHandler) Update(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	p, err := h.service.UpdateProfile(r.Context(), userID, input)
+	p, err := h.service.UpdateProfile(r.Context(), userID, version, input)
 	if err != nil {
 		if errors.Is(err, repository.ErrNotFound) {
 			http.Error(w, "Profile not found", http.StatusNotFound)
 			return
 		}
+		if errors.Is(err, repository.ErrVersionConflict) {
+			http.Error(w, err.Error(), http.StatusPreconditionFailed)
+			return
+		}
+		if status, ok := validationStatus(err); ok {
+			http.Error(w, err.Error(), status)
+			return
+		}
 		h.logger.Error("Failed to update profile", zap.Error(err))
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 		return
@@ -259,13 +236,150 @@ func (h *ProfileHandler) Update(w http.ResponseWriter, r *http.Request) {
 
 	h.enrichProfile(r.Context(), p)
 
+	setETag(w, p)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(p)
+}
+
+// Patch handles partial profile updates.
+// @Summary Patch a profile
+// @Description Apply a JSON Merge Patch (RFC 7396) to the authenticated user's profile.
+// @Description Omitted fields are unchanged. null clears gender, height, bio, flaws, strengths and location;
+// @Description firstName, birthDate and visibility cannot be cleared. latitude and longitude are set or cleared together.
+// @Description The If-Match header must carry the ETag from GET /profiles/me.
+// @Tags profiles
+// @Accept application/merge-patch+json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param If-Match header string true "ETag of the profile being edited, or * for the current version"
+// @Param input body service.PatchProfileInput true "Merge patch"
+// @Success 200 {object} profile.Profile
+// @Header 200 {string} ETag "Version of the updated profile"
+// @Failure 400 {string} string "Invalid field"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 404 {string} string "Profile not found"
+// @Failure 409 {string} string "Birth date is locked"
+// @Failure 412 {string} string "Profile was modified"
+// @Failure 415 {string} string "Unsupported content type"
+// @Failure 428 {string} string "If-Match header required"
+// @Failure 500 {string} string "Internal server error"
+// @Router /profiles/me [patch]
+func (h *ProfileHandler) Patch(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(auth.UserIDKey).(string)
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType != "application/merge-patch+json" && mediaType != "application/json" {
+		http.Error(w, "Content-Type must be application/merge-patch+json", http.StatusUnsupportedMediaType)
+		return
+	}
+
+	version, ok := h.ifMatchVersion(w, r, userID)
+	if !ok {
+		return
+	}
+
+	// A merge patch that is not an object would replace the whole profile,
+	// which is not supported.
+	var raw json.RawMessage
+	if err := json.NewDecoder(r.Body).Decode(&raw); err != nil || !bytes.HasPrefix(bytes.TrimSpace(raw), []byte("{")) {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	var input service.PatchProfileInput
+	if err := json.Unmarshal(raw, &input); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	p, err := h.service.PatchProfile(r.Context(), userID, version, input)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			http.Error(w, "Profile not found", http.StatusNotFound)
+			return
+		}
+		if errors.Is(err, repository.ErrVersionConflict) {
+			http.Error(w, err.Error(), http.StatusPreconditionFailed)
+			return
+		}
+		if status, ok := validationStatus(err); ok {
+			http.Error(w, err.Error(), status)
+			return
+		}
+		h.logger.Error("Failed to patch profile", zap.Error(err))
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	h.enrichProfile(r.Context(), p)
+
+	setETag(w, p)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(p)
+}
+
+// SetBirthDateInput is the body accepted by SetBirthDate.
+type SetBirthDateInput struct {
+	BirthDate time.Time `json:"birthDate"`
+}
+
+// SetBirthDate lets support staff correct a locked birth date.
+// @Summary Override a birth date
+// @Description Support-only endpoint to change a user's birth date after it has been locked.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param userID path string true "User ID"
+// @Param input body SetBirthDateInput true "New birth date"
+// @Success 200 {object} profile.Profile
+// @Failure 400 {string} string "Invalid birth date"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Forbidden"
+// @Failure 404 {string} string "Profile not found"
+// @Failure 500 {string} string "Internal server error"
+// @Router /admin/profiles/{userID}/birth-date [put]
+func (h *ProfileHandler) SetBirthDate(w http.ResponseWriter, r *http.Request) {
+	userID := r.PathValue("userID")
+	if userID == "" {
+		http.Error(w, "User ID required", http.StatusBadRequest)
+		return
+	}
+
+	var input SetBirthDateInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil || input.BirthDate.IsZero() {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	p, err := h.service.OverrideBirthDate(r.Context(), userID, input.BirthDate)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			http.Error(w, "Profile not found", http.StatusNotFound)
+			return
+		}
+		if status, ok := validationStatus(err); ok {
+			http.Error(w, err.Error(), status)
+			return
+		}
+		h.logger.Error("Failed to override birth date", zap.Error(err))
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	h.enrichProfile(r.Context(), p)
+
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(p)
 }
 
 // Search handles searching for profiles.
 // @Summary Search profiles
-// @Description Search for profiles based on age, gender, height and location.
+// @Description Search for profiles based on age, gender, height, location and free text.
+// @Description When q is set, results are ranked by relevance and include a highlighted bio snippet.
 // @Tags profiles
 // @Produce json
 // @Security ApiKeyAuth
@@ -275,7 +389,16 @@ func (h *ProfileHandler) Update(w http.ResponseWriter, r *http.Request) {
 // @Param minHeight query int false "Minimum height"
 // @Param maxHeight query int false "Maximum height"
 // @Param radius query float64 false "Radius in KM"
-// @Success 200 {array} profile.Profile
+// @Param q query string false "Full-text query over bio, strengths and flaws"
+// @Param verified query bool false "Only verified (true) or unverified (false) profiles"
+// @Param languages query string false "Comma-separated ISO 639-1 codes; matches profiles speaking any of them"
+// @Param education query string false "Education level"
+// @Param relationshipGoal query string false "Relationship goal"
+// @Param smoking query string false "Smoking habit"
+// @Param drinking query string false "Drinking habit"
+// @Param kids query string false "Stance on children"
+// @Success 200 {array} profile.SearchResult
+// @Failure 400 {string} string "Invalid filter"
 // @Failure 401 {string} string "Unauthorized"
 // @Failure 500 {string} string "Internal server error"
 // @Router /profiles/search [get]
@@ -328,25 +451,449 @@ func (h *ProfileHandler) Search(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	if q := strings.TrimSpace(query.Get("q")); q != "" {
+		params.Query = &q
+	}
+
+	if verifiedStr := query.Get("verified"); verifiedStr != "" {
+		if verified, err := strconv.ParseBool(verifiedStr); err == nil {
+			params.Verified = &verified
+		}
+	}
+
+	if languages := query.Get("languages"); languages != "" {
+		params.Languages = strings.Split(languages, ",")
+	}
+
+	for name, dest := range map[string]**string{
+		"education":        &params.Education,
+		"relationshipGoal": &params.RelationshipGoal,
+		"smoking":          &params.Smoking,
+		"drinking":         &params.Drinking,
+		"kids":             &params.Kids,
+	} {
+		if v := query.Get(name); v != "" {
+			*dest = &v
+		}
+	}
+
 	profiles, err := h.service.SearchProfiles(r.Context(), userID, params)
 	if err != nil {
 		if err.Error() == "profile not found" {
 			http.Error(w, "User profile not found. Please create a profile first.", http.StatusNotFound)
 			return
 		}
+		if status, ok := validationStatus(err); ok {
+			http.Error(w, err.Error(), status)
+			return
+		}
 		h.logger.Error("Failed to search profiles", zap.Error(err))
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 		return
 	}
 
+	results := make([]*profile.SearchResult, 0, len(profiles))
 	for _, p := range profiles {
 		h.enrichProfile(r.Context(), p)
+		results = append(results, p.SearchResult())
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(results)
+}
+
+// SetPassport handles turning on travel mode.
+// @Summary Set a passport location
+// @Description Browse another city before arriving. Until expiresAt (at most 30 days away), searches are
+// @Description centred on this location and other users see the profile as "traveling in" the city.
+// @Tags profiles
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param input body service.PassportInput true "Passport location"
+// @Success 200 {object} profile.Profile
+// @Header 200 {string} ETag "Version of the updated profile"
+// @Failure 400 {string} string "Invalid city, coordinates or expiry"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 404 {string} string "Profile not found"
+// @Failure 500 {string} string "Internal server error"
+// @Router /profiles/me/passport [put]
+func (h *ProfileHandler) SetPassport(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(auth.UserIDKey).(string)
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var input service.PassportInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	p, err := h.service.SetPassport(r.Context(), userID, input)
+	h.writePassportResult(w, r, p, err)
+}
+
+// ClearPassport handles turning off travel mode.
+// @Summary Clear the passport location
+// @Description End travel mode early. Searches go back to the profile's own location.
+// @Tags profiles
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} profile.Profile
+// @Header 200 {string} ETag "Version of the updated profile"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 404 {string} string "Profile not found"
+// @Failure 500 {string} string "Internal server error"
+// @Router /profiles/me/passport [delete]
+func (h *ProfileHandler) ClearPassport(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(auth.UserIDKey).(string)
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	p, err := h.service.ClearPassport(r.Context(), userID)
+	h.writePassportResult(w, r, p, err)
+}
+
+func (h *ProfileHandler) writePassportResult(w http.ResponseWriter, r *http.Request, p *profile.Profile, err error) {
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			http.Error(w, "Profile not found", http.StatusNotFound)
+			return
+		}
+		if status, ok := validationStatus(err); ok {
+			http.Error(w, err.Error(), status)
+			return
+		}
+		h.logger.Error("Failed to update passport", zap.Error(err))
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	h.enrichProfile(r.Context(), p)
+
+	setETag(w, p)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(p)
+}
+
+// ViewerResponse is one entry in the list of people who viewed a profile.
+type ViewerResponse struct {
+	Profile  *profile.PublicProfile `json:"profile"`
+	ViewedAt time.Time              `json:"viewedAt"`
+}
+
+// Viewers handles listing who viewed the authenticated user's profile.
+// @Summary List profile viewers
+// @Description List people who viewed the authenticated user's profile, most recent first.
+// @Description Views are counted once per viewer per day; incognito viewers and blocked users are not listed.
+// @Tags profiles
+// @Produce json
+// @Security ApiKeyAuth
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Param offset query int false "Offset"
+// @Success 200 {array} ViewerResponse
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 404 {string} string "Profile not found"
+// @Failure 500 {string} string "Internal server error"
+// @Router /profiles/me/viewers [get]
+func (h *ProfileHandler) Viewers(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(auth.UserIDKey).(string)
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var limit, offset int
+	query := r.URL.Query()
+	if limitStr := query.Get("limit"); limitStr != "" {
+		fmt.Sscanf(limitStr, "%d", &limit)
+	}
+	if offsetStr := query.Get("offset"); offsetStr != "" {
+		fmt.Sscanf(offsetStr, "%d", &offset)
+	}
+
+	views, err := h.service.ListViewers(r.Context(), userID, limit, offset)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			http.Error(w, "Profile not found", http.StatusNotFound)
+			return
+		}
+		h.logger.Error("Failed to list profile viewers", zap.Error(err))
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	resp := make([]ViewerResponse, 0, len(views))
+	for _, v := range views {
+		h.enrichProfile(r.Context(), v.Viewer)
+		resp = append(resp, ViewerResponse{Profile: v.Viewer.Public(), ViewedAt: v.ViewedAt})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// Revisions handles listing the authenticated user's earlier profile versions.
+// @Summary List profile revisions
+// @Description List the earlier versions of the authenticated user's profile, newest first.
+// @Description Each revision holds the displayed content (name, bio, prompts, gender, height) the profile had at that version.
+// @Tags profiles
+// @Produce json
+// @Security ApiKeyAuth
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Param offset query int false "Offset"
+// @Success 200 {array} profile.Revision
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 500 {string} string "Internal server error"
+// @Router /profiles/me/revisions [get]
+func (h *ProfileHandler) Revisions(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(auth.UserIDKey).(string)
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	h.writeRevisions(w, r, userID)
+}
+
+// RestoreRevision handles rolling the authenticated user's profile back.
+// @Summary Restore a profile revision
+// @Description Bring back the content the profile had at an earlier version. The restore is itself recorded as a revision.
+// @Description The If-Match header must carry the ETag from GET /profiles/me.
+// @Tags profiles
+// @Produce json
+// @Security ApiKeyAuth
+// @Param version path int true "Revision version"
+// @Param If-Match header string true "ETag of the profile being edited, or * for the current version"
+// @Success 200 {object} profile.Profile
+// @Header 200 {string} ETag "Version of the updated profile"
+// @Failure 400 {string} string "Invalid version or restored field"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 404 {string} string "Profile or revision not found"
+// @Failure 412 {string} string "Profile was modified"
+// @Failure 428 {string} string "If-Match header required"
+// @Failure 500 {string} string "Internal server error"
+// @Router /profiles/me/revisions/{version}/restore [post]
+func (h *ProfileHandler) RestoreRevision(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(auth.UserIDKey).(string)
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	revision, err := strconv.Atoi(r.PathValue("version"))
+	if err != nil {
+		http.Error(w, "Invalid version", http.StatusBadRequest)
+		return
 	}
 
-	if profiles == nil {
-		profiles = []*profile.Profile{}
+	version, ok := h.ifMatchVersion(w, r, userID)
+	if !ok {
+		return
+	}
+	h.writeRestoreResult(w, r, userID, version, revision)
+}
+
+// AdminRevisions lets moderators see a user's profile history.
+// @Summary List a user's profile revisions
+// @Description Moderator-only. List the earlier versions of a user's profile, newest first.
+// @Tags admin
+// @Produce json
+// @Security ApiKeyAuth
+// @Param userID path string true "User ID"
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Param offset query int false "Offset"
+// @Success 200 {array} profile.Revision
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Forbidden"
+// @Failure 500 {string} string "Internal server error"
+// @Router /admin/profiles/{userID}/revisions [get]
+func (h *ProfileHandler) AdminRevisions(w http.ResponseWriter, r *http.Request) {
+	h.writeRevisions(w, r, r.PathValue("userID"))
+}
+
+// AdminRestoreRevision lets moderators roll a user's profile back.
+// @Summary Restore a user's profile revision
+// @Description Moderator-only. Bring back the content a user's profile had at an earlier version.
+// @Tags admin
+// @Produce json
+// @Security ApiKeyAuth
+// @Param userID path string true "User ID"
+// @Param version path int true "Revision version"
+// @Success 200 {object} profile.Profile
+// @Failure 400 {string} string "Invalid version or restored field"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Forbidden"
+// @Failure 404 {string} string "Profile or revision not found"
+// @Failure 412 {string} string "Profile was modified"
+// @Failure 500 {string} string "Internal server error"
+// @Router /admin/profiles/{userID}/revisions/{version}/restore [post]
+func (h *ProfileHandler) AdminRestoreRevision(w http.ResponseWriter, r *http.Request) {
+	userID := r.PathValue("userID")
+	revision, err := strconv.Atoi(r.PathValue("version"))
+	if err != nil {
+		http.Error(w, "Invalid version", http.StatusBadRequest)
+		return
+	}
+
+	// Moderators restore over whatever the profile currently holds.
+	p, err := h.service.GetProfileByUserID(r.Context(), userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			http.Error(w, "Profile not found", http.StatusNotFound)
+			return
+		}
+		h.logger.Error("Failed to get profile", zap.Error(err))
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	h.writeRestoreResult(w, r, userID, p.Version, revision)
+}
+
+func (h *ProfileHandler) writeRevisions(w http.ResponseWriter, r *http.Request, userID string) {
+	var limit, offset int
+	query := r.URL.Query()
+	if limitStr := query.Get("limit"); limitStr != "" {
+		fmt.Sscanf(limitStr, "%d", &limit)
+	}
+	if offsetStr := query.Get("offset"); offsetStr != "" {
+		fmt.Sscanf(offsetStr, "%d", &offset)
+	}
+
+	revisions, err := h.service.ListRevisions(r.Context(), userID, limit, offset)
+	if err != nil {
+		h.logger.Error("Failed to list profile revisions", zap.Error(err))
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	if revisions == nil {
+		revisions = []*profile.Revision{}
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(profiles)
+	json.NewEncoder(w).Encode(revisions)
+}
+
+func (h *ProfileHandler) writeRestoreResult(w http.ResponseWriter, r *http.Request, userID string, version, revision int) {
+	p, err := h.service.RestoreRevision(r.Context(), userID, version, revision)
+	if err != nil {
+		if status, ok := validationStatus(err); ok {
+			http.Error(w, err.Error(), status)
+			return
+		}
+		switch {
+		case errors.Is(err, repository.ErrNotFound), errors.Is(err, repository.ErrRevisionNotFound):
+			http.Error(w, err.Error(), http.StatusNotFound)
+		case errors.Is(err, repository.ErrVersionConflict):
+			http.Error(w, err.Error(), http.StatusPreconditionFailed)
+		default:
+			h.logger.Error("Failed to restore profile revision", zap.Error(err))
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	h.enrichProfile(r.Context(), p)
+
+	setETag(w, p)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(p)
+}
+
+// validationStatus maps profile validation errors to an HTTP status code.
+func validationStatus(err error) (int, bool) {
+	switch {
+	case errors.Is(err, service.ErrInvalidVisibility),
+		errors.Is(err, service.ErrInvalidFirstName),
+		errors.Is(err, service.ErrInvalidGender),
+		errors.Is(err, service.ErrInvalidHeight),
+		errors.Is(err, service.ErrInvalidLocation),
+		errors.Is(err, service.ErrInvalidPassportCity),
+		errors.Is(err, service.ErrInvalidPassportTime),
+		errors.Is(err, service.ErrInvalidFieldPrivacy),
+		errors.Is(err, service.ErrInvalidLanguages),
+		errors.Is(err, service.ErrInvalidEducation),
+		errors.Is(err, service.ErrInvalidOccupation),
+		errors.Is(err, service.ErrInvalidRelationship),
+		errors.Is(err, service.ErrInvalidSmoking),
+		errors.Is(err, service.ErrInvalidDrinking),
+		errors.Is(err, service.ErrInvalidKids),
+		errors.Is(err, service.ErrBirthDateRequired),
+		errors.Is(err, service.ErrInvalidBirthDate),
+		errors.Is(err, service.ErrUnderage):
+		return http.StatusBadRequest, true
+	case errors.Is(err, service.ErrBirthDateLocked):
+		return http.StatusConflict, true
+	}
+	return 0, false
+}
+
+// setETag exposes the profile version so clients can send it back in
+// If-Match.
+func setETag(w http.ResponseWriter, p *profile.Profile) {
+	w.Header().Set("ETag", fmt.Sprintf(`"%d"`, p.Version))
+}
+
+// ifMatchVersion reads the version a write is based on from If-Match. On
+// failure it writes the error response and returns false. If-Match uses
+// strong comparison, so weak tags never match; when the header lists several
+// tags, the one naming the current version is used.
+func (h *ProfileHandler) ifMatchVersion(w http.ResponseWriter, r *http.Request, userID string) (int, bool) {
+	ifMatch := r.Header.Get("If-Match")
+	if ifMatch == "" {
+		http.Error(w, "If-Match header required", http.StatusPreconditionRequired)
+		return 0, false
+	}
+	versions := parseETags(ifMatch)
+	switch len(versions) {
+	case 0:
+		http.Error(w, repository.ErrVersionConflict.Error(), http.StatusPreconditionFailed)
+		return 0, false
+	case 1:
+		return versions[0], true
+	}
+
+	p, err := h.service.GetProfileByUserID(r.Context(), userID)
+	if err != nil {
+		// Let the write itself report a missing profile or a failed lookup.
+		return versions[0], true
+	}
+	if !slices.Contains(versions, p.Version) {
+		http.Error(w, repository.ErrVersionConflict.Error(), http.StatusPreconditionFailed)
+		return 0, false
+	}
+	// The write still checks this version, so a change made since the
+	// lookup is reported as a conflict.
+	return p.Version, true
+}
+
+// parseETags reads the versions written by setETag from an If-Match list.
+// "*" matches any current version. Weak and malformed tags are skipped.
+func parseETags(header string) []int {
+	if strings.TrimSpace(header) == "*" {
+		return []int{service.AnyVersion}
+	}
+	var versions []int
+	for _, tag := range strings.Split(header, ",") {
+		if version, ok := parseETag(tag); ok {
+			versions = append(versions, version)
+		}
+	}
+	return versions
+}
+
+// parseETag reads a single strong tag written by setETag.
+func parseETag(tag string) (int, bool) {
+	tag = strings.TrimSpace(tag)
+	if len(tag) < 2 || tag[0] != '"' || tag[len(tag)-1] != '"' {
+		return 0, false
+	}
+	version, err := strconv.Atoi(tag[1 : len(tag)-1])
+	if err != nil || version < 0 {
+		return 0, false
+	}
+	return version, true
 }
@@ -8,6 +8,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/kisssonik/hearts/internal/profile"
 	"github.com/kisssonik/hearts/internal/profile/handler"
@@ -40,7 +41,47 @@ func (m *MockProfileService) GetProfileByUserID(ctx context.Context, userID stri
 	return args.Get(0).(*profile.Profile), args.Error(1)
 }
 
-func (m *MockProfileService) UpdateProfile(ctx context.Context, userID string, input service.UpdateProfileInput) (*profile.Profile, error) {
+func (m *MockProfileService) GetPublicProfile(ctx context.Context, viewerID, userID string) (*profile.Profile, error) {
+	args := m.Called(ctx, viewerID, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*profile.Profile), args.Error(1)
+}
+
+func (m *MockProfileService) UpdateProfile(ctx context.Context, userID string, version int, input service.UpdateProfileInput) (*profile.Profile, error) {
+	args := m.Called(ctx, userID, version, input)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*profile.Profile), args.Error(1)
+}
+
+func (m *MockProfileService) PatchProfile(ctx context.Context, userID string, version int, input service.PatchProfileInput) (*profile.Profile, error) {
+	args := m.Called(ctx, userID, version, input)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*profile.Profile), args.Error(1)
+}
+
+func (m *MockProfileService) SearchProfiles(ctx context.Context, userID string, params service.SearchParams) ([]*profile.Profile, error) {
+	args := m.Called(ctx, userID, params)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*profile.Profile), args.Error(1)
+}
+
+func (m *MockProfileService) OverrideBirthDate(ctx context.Context, userID string, birthDate time.Time) (*profile.Profile, error) {
+	args := m.Called(ctx, userID, birthDate)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*profile.Profile), args.Error(1)
+}
+
+func (m *MockProfileService) SetPassport(ctx context.Context, userID string, input service.PassportInput) (*profile.Profile, error) {
 	args := m.Called(ctx, userID, input)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -48,6 +89,38 @@ func (m *MockProfileService) UpdateProfile(ctx context.Context, userID string, i
 	return args.Get(0).(*profile.Profile), args.Error(1)
 }
 
+func (m *MockProfileService) ClearPassport(ctx context.Context, userID string) (*profile.Profile, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*profile.Profile), args.Error(1)
+}
+
+func (m *MockProfileService) ListRevisions(ctx context.Context, userID string, limit, offset int) ([]*profile.Revision, error) {
+	args := m.Called(ctx, userID, limit, offset)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*profile.Revision), args.Error(1)
+}
+
+func (m *MockProfileService) RestoreRevision(ctx context.Context, userID string, version, revision int) (*profile.Profile, error) {
+	args := m.Called(ctx, userID, version, revision)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*profile.Profile), args.Error(1)
+}
+
+func (m *MockProfileService) ListViewers(ctx context.Context, userID string, limit, offset int) ([]*profile.View, error) {
+	args := m.Called(ctx, userID, limit, offset)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*profile.View), args.Error(1)
+}
+
 // MockStorageProvider
 type MockStorageProvider struct {
 	mock.Mock
@@ -63,6 +136,19 @@ func (m *MockStorageProvider) GetPresignedURL(ctx context.Context, key string) (
 	return args.String(0), args.Error(1)
 }
 
+func (m *MockStorageProvider) Download(ctx context.Context, key string) ([]byte, error) {
+	args := m.Called(ctx, key)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]byte), args.Error(1)
+}
+
+func (m *MockStorageProvider) Delete(ctx context.Context, key string) error {
+	args := m.Called(ctx, key)
+	return args.Error(0)
+}
+
 func TestProfileHandler_Create(t *testing.T) {
 	mockService := new(MockProfileService)
 	mockStorage := new(MockStorageProvider)
@@ -94,18 +180,42 @@ func TestProfileHandler_Get(t *testing.T) {
 	h := handler.NewProfileHandler(mockService, mockStorage, logger)
 
 	req := httptest.NewRequest("GET", "/profiles/user1", nil)
+	req = req.WithContext(context.WithValue(req.Context(), auth.UserIDKey, "viewer"))
 	req.SetPathValue("userID", "user1")
 	w := httptest.NewRecorder()
 
-	expectedProfile := &profile.Profile{ID: "p1", UserID: "user1", FirstName: "John"}
-	mockService.On("GetProfileByUserID", mock.Anything, "user1").Return(expectedProfile, nil)
+	bucket := "< 5 km"
+	expectedProfile := &profile.Profile{ID: "p1", UserID: "user1", FirstName: "John", DistanceBucket: &bucket}
+	mockService.On("GetPublicProfile", mock.Anything, "viewer", "user1").Return(expectedProfile, nil)
 
 	h.Get(w, req)
 
 	assert.Equal(t, http.StatusOK, w.Code)
+	var resp map[string]interface{}
+	json.NewDecoder(w.Body).Decode(&resp)
+	assert.Equal(t, "John", resp["firstName"])
+	assert.Equal(t, bucket, resp["distanceBucket"])
+	assert.NotContains(t, resp, "updatedAt")
+	assert.NotContains(t, resp, "latitude")
 	mockService.AssertExpectations(t)
 }
 
+func TestProfileHandler_Get_Unauthorized(t *testing.T) {
+	mockService := new(MockProfileService)
+	mockStorage := new(MockStorageProvider)
+	logger := zap.NewNop()
+	h := handler.NewProfileHandler(mockService, mockStorage, logger)
+
+	req := httptest.NewRequest("GET", "/profiles/user1", nil)
+	req.SetPathValue("userID", "user1")
+	w := httptest.NewRecorder()
+
+	h.Get(w, req)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	mockService.AssertNotCalled(t, "GetPublicProfile")
+}
+
 func TestProfileHandler_Get_NotFound(t *testing.T) {
 	mockService := new(MockProfileService)
 	mockStorage := new(MockStorageProvider)
@@ -113,13 +223,407 @@ func TestProfileHandler_Get_NotFound(t *testing.T) {
 	h := handler.NewProfileHandler(mockService, mockStorage, logger)
 
 	req := httptest.NewRequest("GET", "/profiles/user1", nil)
+	req = req.WithContext(context.WithValue(req.Context(), auth.UserIDKey, "viewer"))
 	req.SetPathValue("userID", "user1")
 	w := httptest.NewRecorder()
 
-	mockService.On("GetProfileByUserID", mock.Anything, "user1").Return(nil, repository.ErrNotFound)
+	mockService.On("GetPublicProfile", mock.Anything, "viewer", "user1").Return(nil, repository.ErrNotFound)
 
 	h.Get(w, req)
 
 	assert.Equal(t, http.StatusNotFound, w.Code)
 	mockService.AssertExpectations(t)
 }
+
+func TestProfileHandler_Search_FullText(t *testing.T) {
+	mockService := new(MockProfileService)
+	mockStorage := new(MockStorageProvider)
+	logger := zap.NewNop()
+	h := handler.NewProfileHandler(mockService, mockStorage, logger)
+
+	req := httptest.NewRequest("GET", "/profiles/search?q=+jazz+", nil)
+	req = req.WithContext(context.WithValue(req.Context(), auth.UserIDKey, "user1"))
+	w := httptest.NewRecorder()
+
+	snippet := "Big <mark>jazz</mark> fan"
+	birthDate := time.Date(1990, 6, 15, 0, 0, 0, 0, time.UTC)
+	mockService.On("SearchProfiles", mock.Anything, "user1", mock.MatchedBy(func(p service.SearchParams) bool {
+		return p.Query != nil && *p.Query == "jazz"
+	})).Return([]*profile.Profile{{UserID: "user2", SearchSnippet: &snippet, BirthDate: &birthDate, Visibility: profile.VisibilityVisible, CreatedAt: birthDate}}, nil)
+
+	h.Search(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	// Results are public projections: no exact birth date, visibility or
+	// timestamps.
+	for _, field := range []string{"birthDate", "visibility", "createdAt", "updatedAt"} {
+		assert.NotContains(t, w.Body.String(), `"`+field+`"`)
+	}
+	var resp []*profile.SearchResult
+	json.NewDecoder(w.Body).Decode(&resp)
+	assert.Len(t, resp, 1)
+	assert.Equal(t, "user2", resp[0].UserID)
+	assert.Equal(t, snippet, *resp[0].SearchSnippet)
+	mockService.AssertExpectations(t)
+}
+
+func TestProfileHandler_Search_Verified(t *testing.T) {
+	mockService := new(MockProfileService)
+	mockStorage := new(MockStorageProvider)
+	logger := zap.NewNop()
+	h := handler.NewProfileHandler(mockService, mockStorage, logger)
+
+	req := httptest.NewRequest("GET", "/profiles/search?verified=true", nil)
+	req = req.WithContext(context.WithValue(req.Context(), auth.UserIDKey, "user1"))
+	w := httptest.NewRecorder()
+
+	mockService.On("SearchProfiles", mock.Anything, "user1", mock.MatchedBy(func(p service.SearchParams) bool {
+		return p.Verified != nil && *p.Verified
+	})).Return([]*profile.Profile{{UserID: "user2", IsVerified: true}}, nil)
+
+	h.Search(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestProfileHandler_Viewers(t *testing.T) {
+	mockService := new(MockProfileService)
+	mockStorage := new(MockStorageProvider)
+	logger := zap.NewNop()
+	h := handler.NewProfileHandler(mockService, mockStorage, logger)
+
+	req := httptest.NewRequest("GET", "/profiles/me/viewers?limit=5&offset=10", nil)
+	req = req.WithContext(context.WithValue(req.Context(), auth.UserIDKey, "user1"))
+	w := httptest.NewRecorder()
+
+	viewedAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	lat := 52.52
+	mockService.On("ListViewers", mock.Anything, "user1", 5, 10).Return([]*profile.View{
+		{Viewer: &profile.Profile{UserID: "user2", FirstName: "Jane", Latitude: &lat}, ViewedAt: viewedAt},
+	}, nil)
+
+	h.Viewers(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var resp []map[string]any
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	if assert.Len(t, resp, 1) {
+		viewer := resp[0]["profile"].(map[string]any)
+		assert.Equal(t, "user2", viewer["userId"])
+		assert.NotContains(t, viewer, "latitude")
+		assert.Equal(t, viewedAt.Format(time.RFC3339), resp[0]["viewedAt"])
+	}
+	mockService.AssertExpectations(t)
+}
+
+func TestProfileHandler_GetMe_ETag(t *testing.T) {
+	mockService := new(MockProfileService)
+	mockStorage := new(MockStorageProvider)
+	logger := zap.NewNop()
+	h := handler.NewProfileHandler(mockService, mockStorage, logger)
+
+	req := httptest.NewRequest("GET", "/profiles/me", nil)
+	req = req.WithContext(context.WithValue(req.Context(), auth.UserIDKey, "user1"))
+	w := httptest.NewRecorder()
+
+	mockService.On("GetProfileByUserID", mock.Anything, "user1").Return(&profile.Profile{UserID: "user1", Version: 4}, nil)
+
+	h.GetMe(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `"4"`, w.Header().Get("ETag"))
+}
+
+func TestProfileHandler_Update_IfMatch(t *testing.T) {
+	tests := []struct {
+		name       string
+		ifMatch    string
+		version    int // Passed to the service
+		serviceErr error
+		wantStatus int
+	}{
+		{"missing", "", 4, nil, http.StatusPreconditionRequired},
+		{"malformed", "W/abc", 4, nil, http.StatusPreconditionFailed},
+		{"negative", `"-1"`, 4, nil, http.StatusPreconditionFailed},
+		{"stale", `"4"`, 4, repository.ErrVersionConflict, http.StatusPreconditionFailed},
+		{"current", `"4"`, 4, nil, http.StatusOK},
+		{"weak", `W/"4"`, 4, nil, http.StatusPreconditionFailed},
+		{"any", "*", service.AnyVersion, nil, http.StatusOK},
+		{"list", `"3", "4"`, 4, nil, http.StatusOK},
+		{"list without current", `"2", "3"`, 4, nil, http.StatusPreconditionFailed},
+		{"list with weak current", `"3", W/"4"`, 3, repository.ErrVersionConflict, http.StatusPreconditionFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockProfileService)
+			mockStorage := new(MockStorageProvider)
+			h := handler.NewProfileHandler(mockService, mockStorage, zap.NewNop())
+
+			req := httptest.NewRequest("PUT", "/profiles/me", bytes.NewBufferString(`{"firstName":"Jane"}`))
+			req = req.WithContext(context.WithValue(req.Context(), auth.UserIDKey, "user1"))
+			if tt.ifMatch != "" {
+				req.Header.Set("If-Match", tt.ifMatch)
+			}
+			w := httptest.NewRecorder()
+
+			mockService.On("GetProfileByUserID", mock.Anything, "user1").Return(&profile.Profile{UserID: "user1", Version: 4}, nil).Maybe()
+			if tt.serviceErr != nil {
+				mockService.On("UpdateProfile", mock.Anything, "user1", tt.version, mock.Anything).Return(nil, tt.serviceErr)
+			} else {
+				mockService.On("UpdateProfile", mock.Anything, "user1", tt.version, mock.Anything).Return(&profile.Profile{UserID: "user1", Version: 5}, nil)
+			}
+
+			h.Update(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			if tt.wantStatus == http.StatusOK {
+				assert.Equal(t, `"5"`, w.Header().Get("ETag"))
+			}
+		})
+	}
+}
+
+func TestProfileHandler_Patch(t *testing.T) {
+	mockService := new(MockProfileService)
+	mockStorage := new(MockStorageProvider)
+	h := handler.NewProfileHandler(mockService, mockStorage, zap.NewNop())
+
+	req := httptest.NewRequest("PATCH", "/profiles/me", bytes.NewBufferString(`{"height": null, "bio": "New"}`))
+	req = req.WithContext(context.WithValue(req.Context(), auth.UserIDKey, "user1"))
+	req.Header.Set("Content-Type", "application/merge-patch+json")
+	req.Header.Set("If-Match", `"2"`)
+	w := httptest.NewRecorder()
+
+	mockService.On("PatchProfile", mock.Anything, "user1", 2, mock.MatchedBy(func(in service.PatchProfileInput) bool {
+		return in.Height.Present && in.Height.Null && in.Bio.Value == "New" && !in.Gender.Present
+	})).Return(&profile.Profile{UserID: "user1", Bio: "New", Version: 3}, nil)
+
+	h.Patch(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `"3"`, w.Header().Get("ETag"))
+	mockService.AssertExpectations(t)
+}
+
+func TestProfileHandler_Patch_BadRequests(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		wantStatus  int
+	}{
+		{"wrong content type", "text/plain", `{}`, http.StatusUnsupportedMediaType},
+		{"not an object", "application/merge-patch+json", `null`, http.StatusBadRequest},
+		{"wrong type", "application/merge-patch+json", `{"height": "tall"}`, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockProfileService)
+			h := handler.NewProfileHandler(mockService, new(MockStorageProvider), zap.NewNop())
+
+			req := httptest.NewRequest("PATCH", "/profiles/me", bytes.NewBufferString(tt.body))
+			req = req.WithContext(context.WithValue(req.Context(), auth.UserIDKey, "user1"))
+			req.Header.Set("Content-Type", tt.contentType)
+			req.Header.Set("If-Match", `"1"`)
+			w := httptest.NewRecorder()
+
+			h.Patch(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			mockService.AssertNotCalled(t, "PatchProfile", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestProfileHandler_Patch_ValidationError(t *testing.T) {
+	mockService := new(MockProfileService)
+	h := handler.NewProfileHandler(mockService, new(MockStorageProvider), zap.NewNop())
+
+	req := httptest.NewRequest("PATCH", "/profiles/me", bytes.NewBufferString(`{"latitude": 10}`))
+	req = req.WithContext(context.WithValue(req.Context(), auth.UserIDKey, "user1"))
+	req.Header.Set("Content-Type", "application/merge-patch+json")
+	req.Header.Set("If-Match", `"1"`)
+	w := httptest.NewRecorder()
+
+	mockService.On("PatchProfile", mock.Anything, "user1", 1, mock.Anything).Return(nil, service.ErrInvalidLocation)
+
+	h.Patch(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestProfileHandler_SetPassport(t *testing.T) {
+	mockService := new(MockProfileService)
+	h := handler.NewProfileHandler(mockService, new(MockStorageProvider), zap.NewNop())
+
+	body := `{"city": "Lisbon", "latitude": 38.72, "longitude": -9.14, "expiresAt": "2030-01-01T00:00:00Z"}`
+	req := httptest.NewRequest("PUT", "/profiles/me/passport", bytes.NewBufferString(body))
+	req = req.WithContext(context.WithValue(req.Context(), auth.UserIDKey, "user1"))
+	w := httptest.NewRecorder()
+
+	passport := &profile.Passport{City: "Lisbon", Latitude: 38.72, Longitude: -9.14, ExpiresAt: time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)}
+	mockService.On("SetPassport", mock.Anything, "user1", mock.MatchedBy(func(in service.PassportInput) bool {
+		return in.City == "Lisbon" && in.Latitude != nil && in.ExpiresAt != nil
+	})).Return(&profile.Profile{UserID: "user1", Passport: passport, Version: 3}, nil)
+
+	h.SetPassport(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `"3"`, w.Header().Get("ETag"))
+	assert.Contains(t, w.Body.String(), `"city":"Lisbon"`)
+	mockService.AssertExpectations(t)
+}
+
+func TestProfileHandler_SetPassport_Invalid(t *testing.T) {
+	mockService := new(MockProfileService)
+	h := handler.NewProfileHandler(mockService, new(MockStorageProvider), zap.NewNop())
+
+	req := httptest.NewRequest("PUT", "/profiles/me/passport", bytes.NewBufferString(`{"city": "Lisbon"}`))
+	req = req.WithContext(context.WithValue(req.Context(), auth.UserIDKey, "user1"))
+	w := httptest.NewRecorder()
+
+	mockService.On("SetPassport", mock.Anything, "user1", mock.Anything).Return(nil, service.ErrInvalidLocation)
+
+	h.SetPassport(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestProfileHandler_RestoreRevision(t *testing.T) {
+	tests := []struct {
+		name       string
+		version    string
+		ifMatch    string
+		setup      func(m *MockProfileService)
+		wantStatus int
+	}{
+		{
+			name:    "restored",
+			version: "2",
+			ifMatch: `"5"`,
+			setup: func(m *MockProfileService) {
+				m.On("RestoreRevision", mock.Anything, "user1", 5, 2).Return(&profile.Profile{UserID: "user1", Version: 6}, nil)
+			},
+			wantStatus: http.StatusOK,
+		},
+		{name: "invalid version", version: "abc", ifMatch: `"5"`, wantStatus: http.StatusBadRequest},
+		{name: "missing If-Match", version: "2", wantStatus: http.StatusPreconditionRequired},
+		{
+			name:    "unknown revision",
+			version: "9",
+			ifMatch: `"5"`,
+			setup: func(m *MockProfileService) {
+				m.On("RestoreRevision", mock.Anything, "user1", 5, 9).Return(nil, repository.ErrRevisionNotFound)
+			},
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:    "fails current rules",
+			version: "2",
+			ifMatch: `"5"`,
+			setup: func(m *MockProfileService) {
+				m.On("RestoreRevision", mock.Anything, "user1", 5, 2).Return(nil, service.ErrInvalidHeight)
+			},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:    "stale",
+			version: "2",
+			ifMatch: `"4"`,
+			setup: func(m *MockProfileService) {
+				m.On("RestoreRevision", mock.Anything, "user1", 4, 2).Return(nil, repository.ErrVersionConflict)
+			},
+			wantStatus: http.StatusPreconditionFailed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockProfileService)
+			h := handler.NewProfileHandler(mockService, new(MockStorageProvider), zap.NewNop())
+			if tt.setup != nil {
+				tt.setup(mockService)
+			}
+
+			req := httptest.NewRequest("POST", "/profiles/me/revisions/"+tt.version+"/restore", nil)
+			req.SetPathValue("version", tt.version)
+			req = req.WithContext(context.WithValue(req.Context(), auth.UserIDKey, "user1"))
+			if tt.ifMatch != "" {
+				req.Header.Set("If-Match", tt.ifMatch)
+			}
+			w := httptest.NewRecorder()
+
+			h.RestoreRevision(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			if tt.wantStatus == http.StatusOK {
+				assert.Equal(t, `"6"`, w.Header().Get("ETag"))
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestProfileHandler_Create_Underage(t *testing.T) {
+	mockService := new(MockProfileService)
+	mockStorage := new(MockStorageProvider)
+	logger := zap.NewNop()
+	h := handler.NewProfileHandler(mockService, mockStorage, logger)
+
+	birthDate := time.Now().AddDate(-16, 0, 0)
+	input := service.CreateProfileInput{FirstName: "John", BirthDate: &birthDate}
+	body, _ := json.Marshal(input)
+
+	req := httptest.NewRequest("POST", "/profiles", bytes.NewBuffer(body))
+	req = req.WithContext(context.WithValue(req.Context(), auth.UserIDKey, "user1"))
+	w := httptest.NewRecorder()
+
+	mockService.On("CreateProfile", mock.Anything, "user1", mock.Anything).Return(nil, service.ErrUnderage)
+
+	h.Create(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestProfileHandler_Search_Attributes(t *testing.T) {
+	mockService := new(MockProfileService)
+	mockStorage := new(MockStorageProvider)
+	logger := zap.NewNop()
+	h := handler.NewProfileHandler(mockService, mockStorage, logger)
+
+	req := httptest.NewRequest("GET", "/profiles/search?languages=en,de&education=masters&smoking=never", nil)
+	req = req.WithContext(context.WithValue(req.Context(), auth.UserIDKey, "user1"))
+	w := httptest.NewRecorder()
+
+	mockService.On("SearchProfiles", mock.Anything, "user1", mock.MatchedBy(func(p service.SearchParams) bool {
+		return len(p.Languages) == 2 && p.Languages[1] == "de" &&
+			p.Education != nil && *p.Education == "masters" &&
+			p.Smoking != nil && *p.Smoking == "never" &&
+			p.Drinking == nil && p.Kids == nil
+	})).Return([]*profile.Profile{}, nil)
+
+	h.Search(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestProfileHandler_Search_InvalidAttribute(t *testing.T) {
+	mockService := new(MockProfileService)
+	mockStorage := new(MockStorageProvider)
+	logger := zap.NewNop()
+	h := handler.NewProfileHandler(mockService, mockStorage, logger)
+
+	req := httptest.NewRequest("GET", "/profiles/search?kids=maybe", nil)
+	req = req.WithContext(context.WithValue(req.Context(), auth.UserIDKey, "user1"))
+	w := httptest.NewRecorder()
+
+	mockService.On("SearchProfiles", mock.Anything, "user1", mock.Anything).Return(nil, service.ErrInvalidKids)
+
+	h.Search(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
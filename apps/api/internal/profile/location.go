@@ -0,0 +1,106 @@
+package profile
+
+import (
+	"math"
+	"math/rand/v2"
+	"time"
+)
+
+const earthRadiusKM = 6371.0
+
+// Jitter parameters. The fuzzed location lies between jitterMinKM and
+// jitterMaxKM from the real one and is then snapped to a ~1 km grid, so the
+// stored point reveals neither the exact position nor the offset direction.
+const (
+	jitterMinKM     = 0.5
+	jitterMaxKM     = 1.5
+	jitterPrecision = 100 // two decimal places
+)
+
+// distanceBuckets are the upper bounds (in km) used for coarse distances.
+var distanceBuckets = []struct {
+	maxKM float64
+	label string
+}{
+	{5, "< 5 km"},
+	{10, "< 10 km"},
+	{25, "< 25 km"},
+	{50, "< 50 km"},
+	{100, "< 100 km"},
+}
+
+// DistanceKM returns the great-circle distance between two points using the
+// haversine formula.
+func DistanceKM(lat1, lon1, lat2, lon2 float64) float64 {
+	dLat := toRadians(lat2 - lat1)
+	dLon := toRadians(lon2 - lon1)
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(toRadians(lat1))*math.Cos(toRadians(lat2))*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadiusKM * math.Asin(math.Min(1, math.Sqrt(a)))
+}
+
+// DistanceBucket maps a distance to a coarse, human-readable label.
+func DistanceBucket(km float64) string {
+	for _, b := range distanceBuckets {
+		if km < b.maxKM {
+			return b.label
+		}
+	}
+	return "100+ km"
+}
+
+// JitterLocation returns a point at a random bearing and distance from the
+// given coordinates, snapped to a coarse grid.
+func JitterLocation(lat, lon float64) (float64, float64) {
+	bearing := rand.Float64() * 2 * math.Pi
+	distance := jitterMinKM + rand.Float64()*(jitterMaxKM-jitterMinKM)
+
+	dLat := (distance * math.Cos(bearing)) / earthRadiusKM
+	dLon := (distance * math.Sin(bearing)) / (earthRadiusKM * math.Cos(toRadians(lat)))
+
+	fuzzedLat := lat + dLat*180/math.Pi
+	fuzzedLon := lon + dLon*180/math.Pi
+
+	return snap(math.Max(-90, math.Min(90, fuzzedLat))), snap(fuzzedLon)
+}
+
+// FuzzLocation recomputes the jittered search location from the exact one.
+// It must be called whenever Latitude or Longitude change.
+func (p *Profile) FuzzLocation() {
+	if p.Latitude == nil || p.Longitude == nil {
+		p.FuzzedLatitude, p.FuzzedLongitude = nil, nil
+		return
+	}
+	lat, lon := JitterLocation(*p.Latitude, *p.Longitude)
+	p.FuzzedLatitude, p.FuzzedLongitude = &lat, &lon
+}
+
+// RedactLocationFor hides p's raw coordinates before it is shown to viewer.
+// When both profiles have a location, a coarse distance bucket measured from
+// the viewer's search location to p's fuzzed position is set instead. An
+// active passport is reduced to its city.
+func (p *Profile) RedactLocationFor(viewer *Profile) {
+	now := time.Now()
+	p.DistanceBucket = nil
+	if viewer != nil && p.FuzzedLatitude != nil && p.FuzzedLongitude != nil {
+		if lat, lon := viewer.SearchLocation(now); lat != nil && lon != nil {
+			bucket := DistanceBucket(DistanceKM(*lat, *lon, *p.FuzzedLatitude, *p.FuzzedLongitude))
+			p.DistanceBucket = &bucket
+		}
+	}
+	p.TravelingIn = nil
+	if p.Passport.Active(now) {
+		p.TravelingIn = &p.Passport.City
+	}
+	p.Latitude, p.Longitude = nil, nil
+	p.FuzzedLatitude, p.FuzzedLongitude = nil, nil
+	p.Passport = nil
+}
+
+func snap(v float64) float64 {
+	return math.Round(v*jitterPrecision) / jitterPrecision
+}
+
+func toRadians(deg float64) float64 {
+	return deg * math.Pi / 180
+}
@@ -0,0 +1,58 @@
+package profile_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/kisssonik/hearts/internal/profile"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDistanceBucket(t *testing.T) {
+	assert.Equal(t, "< 5 km", profile.DistanceBucket(0.2))
+	assert.Equal(t, "< 10 km", profile.DistanceBucket(5))
+	assert.Equal(t, "< 100 km", profile.DistanceBucket(99.9))
+	assert.Equal(t, "100+ km", profile.DistanceBucket(350))
+}
+
+func TestJitterLocation(t *testing.T) {
+	lat, lon := 52.5200, 13.4050
+
+	for i := 0; i < 100; i++ {
+		fLat, fLon := profile.JitterLocation(lat, lon)
+		d := profile.DistanceKM(lat, lon, fLat, fLon)
+		// Jitter is 0.5-1.5 km, grid snapping moves the point by at most ~0.8 km.
+		assert.Less(t, d, 2.5)
+		assert.InDelta(t, math.Round(fLat*100)/100, fLat, 1e-9)
+		assert.InDelta(t, math.Round(fLon*100)/100, fLon, 1e-9)
+	}
+}
+
+func TestRedactLocationFor(t *testing.T) {
+	lat, lon := 52.5200, 13.4050
+	target := &profile.Profile{Latitude: &lat, Longitude: &lon}
+	target.FuzzLocation()
+
+	viewerLat, viewerLon := 52.5300, 13.4100
+	viewer := &profile.Profile{Latitude: &viewerLat, Longitude: &viewerLon}
+
+	target.RedactLocationFor(viewer)
+
+	assert.Nil(t, target.Latitude)
+	assert.Nil(t, target.Longitude)
+	assert.Nil(t, target.FuzzedLatitude)
+	if assert.NotNil(t, target.DistanceBucket) {
+		assert.Equal(t, "< 5 km", *target.DistanceBucket)
+	}
+}
+
+func TestRedactLocationFor_NoViewer(t *testing.T) {
+	lat, lon := 52.5200, 13.4050
+	target := &profile.Profile{Latitude: &lat, Longitude: &lon}
+	target.FuzzLocation()
+
+	target.RedactLocationFor(nil)
+
+	assert.Nil(t, target.Latitude)
+	assert.Nil(t, target.DistanceBucket)
+}
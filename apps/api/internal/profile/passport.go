@@ -0,0 +1,33 @@
+package profile
+
+import "time"
+
+// MaxPassportDuration bounds how far ahead a passport can expire.
+const MaxPassportDuration = 30 * 24 * time.Hour
+
+// MaxPassportCityLength matches the passport_city column.
+const MaxPassportCityLength = 100
+
+// Passport is a temporary location a traveling user browses from. While it
+// is active, searches are centred on it instead of the profile's own
+// location.
+type Passport struct {
+	City      string    `json:"city"`
+	Latitude  float64   `json:"latitude"`
+	Longitude float64   `json:"longitude"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// Active reports whether the passport has not expired yet.
+func (pp *Passport) Active(now time.Time) bool {
+	return pp != nil && now.Before(pp.ExpiresAt)
+}
+
+// SearchLocation returns the point p searches from: the passport location
+// while one is active, otherwise the profile's own location.
+func (p *Profile) SearchLocation(now time.Time) (*float64, *float64) {
+	if p.Passport.Active(now) {
+		return &p.Passport.Latitude, &p.Passport.Longitude
+	}
+	return p.Latitude, p.Longitude
+}
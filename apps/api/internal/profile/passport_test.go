@@ -0,0 +1,53 @@
+package profile_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kisssonik/hearts/internal/profile"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSearchLocation(t *testing.T) {
+	now := time.Now()
+	lat, lon := 52.52, 13.40
+	p := &profile.Profile{Latitude: &lat, Longitude: &lon}
+
+	gotLat, gotLon := p.SearchLocation(now)
+	assert.Equal(t, lat, *gotLat)
+	assert.Equal(t, lon, *gotLon)
+
+	p.Passport = &profile.Passport{City: "Lisbon", Latitude: 38.72, Longitude: -9.14, ExpiresAt: now.Add(time.Hour)}
+	gotLat, gotLon = p.SearchLocation(now)
+	assert.Equal(t, 38.72, *gotLat)
+	assert.Equal(t, -9.14, *gotLon)
+
+	p.Passport.ExpiresAt = now.Add(-time.Hour)
+	gotLat, _ = p.SearchLocation(now)
+	assert.Equal(t, lat, *gotLat)
+}
+
+func TestRedactLocationFor_Passport(t *testing.T) {
+	lisbonLat, lisbonLon := 38.7223, -9.1393
+	target := &profile.Profile{Latitude: &lisbonLat, Longitude: &lisbonLon}
+	target.FuzzLocation()
+	target.Passport = &profile.Passport{City: "Berlin", Latitude: 52.52, Longitude: 13.40, ExpiresAt: time.Now().Add(time.Hour)}
+
+	// The viewer lives in Berlin but is browsing Lisbon.
+	berlinLat, berlinLon := 52.52, 13.40
+	viewer := &profile.Profile{
+		Latitude:  &berlinLat,
+		Longitude: &berlinLon,
+		Passport:  &profile.Passport{City: "Lisbon", Latitude: 38.7223, Longitude: -9.1393, ExpiresAt: time.Now().Add(time.Hour)},
+	}
+
+	target.RedactLocationFor(viewer)
+
+	if assert.NotNil(t, target.DistanceBucket) {
+		assert.Equal(t, "< 5 km", *target.DistanceBucket)
+	}
+	if assert.NotNil(t, target.TravelingIn) {
+		assert.Equal(t, "Berlin", *target.TravelingIn)
+	}
+	assert.Nil(t, target.Passport)
+}
@@ -0,0 +1,91 @@
+package profile
+
+// Field privacy levels. A field without a setting is shown to everyone.
+const (
+	PrivacyEveryone = "everyone"
+	PrivacyMatches  = "matches" // Only users the owner has matched with
+	PrivacyNobody   = "nobody"
+)
+
+// Fields whose visibility the owner controls, keyed as in the JSON API.
+const (
+	FieldHeight                 = "height"
+	FieldGender                 = "gender"
+	FieldSelfDescribedFlaws     = "selfDescribedFlaws"
+	FieldSelfDescribedStrengths = "selfDescribedStrengths"
+	FieldLanguages              = "languages"
+	FieldEducation              = "education"
+	FieldOccupation             = "occupation"
+	FieldRelationshipGoal       = "relationshipGoal"
+	FieldSmoking                = "smoking"
+	FieldDrinking               = "drinking"
+	FieldKids                   = "kids"
+)
+
+// privateFields maps each controllable field to the function that hides it.
+// A new field only needs an entry here to be covered by ProjectFor.
+var privateFields = map[string]func(p *Profile){
+	FieldHeight:                 func(p *Profile) { p.Height = nil },
+	FieldGender:                 func(p *Profile) { p.Gender = nil },
+	FieldSelfDescribedFlaws:     func(p *Profile) { p.SelfDescribedFlaws = nil },
+	FieldSelfDescribedStrengths: func(p *Profile) { p.SelfDescribedStrengths = nil },
+	FieldLanguages:              func(p *Profile) { p.Languages = nil },
+	FieldEducation:              func(p *Profile) { p.Education = nil },
+	FieldOccupation:             func(p *Profile) { p.Occupation = nil },
+	FieldRelationshipGoal:       func(p *Profile) { p.RelationshipGoal = nil },
+	FieldSmoking:                func(p *Profile) { p.Smoking = nil },
+	FieldDrinking:               func(p *Profile) { p.Drinking = nil },
+	FieldKids:                   func(p *Profile) { p.Kids = nil },
+}
+
+// IsPrivateField reports whether field's visibility can be configured.
+func IsPrivateField(field string) bool {
+	_, ok := privateFields[field]
+	return ok
+}
+
+// IsValidPrivacy reports whether v is a known privacy level.
+func IsValidPrivacy(v string) bool {
+	switch v {
+	case PrivacyEveryone, PrivacyMatches, PrivacyNobody:
+		return true
+	}
+	return false
+}
+
+// FieldPrivacy returns the privacy level of field.
+func (p *Profile) FieldPrivacy(field string) string {
+	if v, ok := p.FieldVisibility[field]; ok {
+		return v
+	}
+	return PrivacyEveryone
+}
+
+// ProjectFor prepares p to be shown to viewer, which may be nil for an
+// anonymous or profile-less viewer. Raw coordinates are replaced by a
+// distance bucket and fields are hidden according to the owner's privacy
+// settings, using p.MatchedWithViewer for "matches" fields. Every response
+// that shows a profile to someone else must go through this method.
+func (p *Profile) ProjectFor(viewer *Profile) {
+	self := viewer != nil && viewer.UserID == p.UserID
+	if self {
+		// There is no distance to yourself.
+		viewer = nil
+	}
+	p.RedactLocationFor(viewer)
+
+	if !self {
+		for field, hide := range privateFields {
+			switch p.FieldPrivacy(field) {
+			case PrivacyEveryone:
+			case PrivacyMatches:
+				if !p.MatchedWithViewer {
+					hide(p)
+				}
+			default:
+				hide(p)
+			}
+		}
+	}
+	p.FieldVisibility = nil
+}
@@ -0,0 +1,63 @@
+package profile_test
+
+import (
+	"testing"
+
+	"github.com/kisssonik/hearts/internal/profile"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProjectFor_FieldPrivacy(t *testing.T) {
+	newProfile := func(matched bool) *profile.Profile {
+		gender, height := profile.GenderFemale, 170
+		return &profile.Profile{
+			UserID:                 "owner",
+			Gender:                 &gender,
+			Height:                 &height,
+			SelfDescribedFlaws:     []string{"Stubborn"},
+			SelfDescribedStrengths: []string{"Patient"},
+			FieldVisibility: map[string]string{
+				profile.FieldHeight:             profile.PrivacyMatches,
+				profile.FieldGender:             profile.PrivacyNobody,
+				profile.FieldSelfDescribedFlaws: profile.PrivacyEveryone,
+			},
+			MatchedWithViewer: matched,
+		}
+	}
+	viewer := &profile.Profile{UserID: "viewer"}
+
+	t.Run("stranger", func(t *testing.T) {
+		p := newProfile(false)
+		p.ProjectFor(viewer)
+
+		assert.Nil(t, p.Height)
+		assert.Nil(t, p.Gender)
+		assert.Equal(t, []string{"Stubborn"}, p.SelfDescribedFlaws)
+		assert.Equal(t, []string{"Patient"}, p.SelfDescribedStrengths)
+		assert.Nil(t, p.FieldVisibility)
+	})
+
+	t.Run("match", func(t *testing.T) {
+		p := newProfile(true)
+		p.ProjectFor(viewer)
+
+		assert.Equal(t, 170, *p.Height)
+		assert.Nil(t, p.Gender)
+	})
+
+	t.Run("owner", func(t *testing.T) {
+		p := newProfile(false)
+		p.ProjectFor(&profile.Profile{UserID: "owner"})
+
+		assert.NotNil(t, p.Height)
+		assert.NotNil(t, p.Gender)
+	})
+
+	t.Run("anonymous", func(t *testing.T) {
+		p := newProfile(false)
+		p.ProjectFor(nil)
+
+		assert.Nil(t, p.Height)
+		assert.Nil(t, p.Gender)
+	})
+}
@@ -1,6 +1,49 @@
 package profile
 
-import "time"
+import (
+	"time"
+
+	"github.com/kisssonik/hearts/internal/photo"
+)
+
+// Visibility modes control who can discover a profile.
+const (
+	VisibilityVisible   = "visible"   // Shown to everyone
+	VisibilityPaused    = "paused"    // Hidden from discovery, existing matches unaffected
+	VisibilityIncognito = "incognito" // Only shown to users this profile has liked
+)
+
+// IsValidVisibility reports whether v is a known visibility mode.
+func IsValidVisibility(v string) bool {
+	switch v {
+	case VisibilityVisible, VisibilityPaused, VisibilityIncognito:
+		return true
+	}
+	return false
+}
+
+// Genders a profile can declare.
+const (
+	GenderMale   = "male"
+	GenderFemale = "female"
+	GenderOther  = "other"
+)
+
+// IsValidGender reports whether g is a known gender.
+func IsValidGender(g string) bool {
+	switch g {
+	case GenderMale, GenderFemale, GenderOther:
+		return true
+	}
+	return false
+}
+
+// Bounds for editable fields.
+const (
+	MaxFirstNameLength = 100 // first_name is VARCHAR(100)
+	MinHeight          = 100 // cm
+	MaxHeight          = 250 // cm
+)
 
 // Profile represents a user's profile information.
 type Profile struct {
@@ -14,11 +57,123 @@ type Profile struct {
 	BirthDate              *time.Time `json:"birthDate" db:"birth_date"`
 	Gender                 *string    `json:"gender" db:"gender"`
 	Height                 *int       `json:"height" db:"height"`
-	Latitude               *float64   `json:"latitude" db:"latitude"`
-	Longitude              *float64   `json:"longitude" db:"longitude"`
+	Languages              []string   `json:"languages" db:"languages"` // ISO 639-1 codes
+	Education              *string    `json:"education" db:"education"`
+	Occupation             *string    `json:"occupation" db:"occupation"`
+	RelationshipGoal       *string    `json:"relationshipGoal" db:"relationship_goal"`
+	Smoking                *string    `json:"smoking" db:"smoking"`
+	Drinking               *string    `json:"drinking" db:"drinking"`
+	Kids                   *string    `json:"kids" db:"kids"`
+	Latitude               *float64   `json:"latitude,omitempty" db:"latitude"`   // Only exposed to the owner
+	Longitude              *float64   `json:"longitude,omitempty" db:"longitude"` // Only exposed to the owner
+	FuzzedLatitude         *float64   `json:"-" db:"fuzzed_latitude"`
+	FuzzedLongitude        *float64   `json:"-" db:"fuzzed_longitude"`
+	Visibility             string     `json:"visibility" db:"visibility"`
+	IsVerified             bool       `json:"isVerified" db:"is_verified"` // Set by selfie verification
+	Passport               *Passport  `json:"passport,omitempty"`          // Only exposed to the owner; nil once expired
+	Version                int        `json:"-" db:"version"`              // Sent as the ETag
 	CreatedAt              time.Time  `json:"createdAt" db:"created_at"`
 	UpdatedAt              time.Time  `json:"updatedAt" db:"updated_at"`
 
+	// FieldVisibility maps private fields to a privacy level. Only exposed
+	// to the owner.
+	FieldVisibility map[string]string `json:"fieldVisibility,omitempty" db:"field_visibility"`
+
 	// Enriched fields
-	InteractionType *string `json:"interactionType,omitempty" db:"interaction_type"` // "like", "pass", or null
+	PhotoVariants   []*photo.Variants `json:"photoVariants,omitempty"`                         // Sized URLs, parallel to Photos
+	Age             *int              `json:"age,omitempty"`                                   // Computed from BirthDate
+	Completeness    *Completeness     `json:"completeness,omitempty"`                          // Only returned to the owner
+	InteractionType *string           `json:"interactionType,omitempty" db:"interaction_type"` // "like", "superlike", "pass", or null
+	DistanceBucket  *string           `json:"distanceBucket,omitempty"`                        // Coarse distance from the viewer, e.g. "< 5 km"
+	TravelingIn     *string           `json:"travelingIn,omitempty"`                           // Passport city, shown to others
+	SearchRank      *float64          `json:"searchRank,omitempty" db:"search_rank"`           // Set only for full-text queries
+	SearchSnippet   *string           `json:"searchSnippet,omitempty" db:"search_snippet"`     // HTML-escaped bio excerpt with <mark> highlights
+
+	// SuperLikedViewer is set in search results when this user super liked
+	// the viewer and the viewer has not answered yet.
+	SuperLikedViewer bool `json:"superLikedViewer,omitempty" db:"super_liked_viewer"`
+
+	// MatchedWithViewer is set by queries made on behalf of a viewer and
+	// decides whether "matches" fields are shown.
+	MatchedWithViewer bool `json:"-"`
+}
+
+// PublicProfile is the view of a profile shown to other users. It omits
+// internal fields such as timestamps, raw coordinates and visibility.
+type PublicProfile struct {
+	UserID                 string            `json:"userId"`
+	FirstName              string            `json:"firstName"`
+	Age                    *int              `json:"age,omitempty"`
+	Bio                    string            `json:"bio"`
+	Photos                 []string          `json:"photos"`
+	PhotoVariants          []*photo.Variants `json:"photoVariants,omitempty"`
+	SelfDescribedFlaws     []string          `json:"selfDescribedFlaws"`
+	SelfDescribedStrengths []string          `json:"selfDescribedStrengths"`
+	Gender                 *string           `json:"gender,omitempty"`
+	Height                 *int              `json:"height,omitempty"`
+	Languages              []string          `json:"languages,omitempty"`
+	Education              *string           `json:"education,omitempty"`
+	Occupation             *string           `json:"occupation,omitempty"`
+	RelationshipGoal       *string           `json:"relationshipGoal,omitempty"`
+	Smoking                *string           `json:"smoking,omitempty"`
+	Drinking               *string           `json:"drinking,omitempty"`
+	Kids                   *string           `json:"kids,omitempty"`
+	DistanceBucket         *string           `json:"distanceBucket,omitempty"`
+	TravelingIn            *string           `json:"travelingIn,omitempty"`
+	IsVerified             bool              `json:"isVerified"`
+}
+
+// Public projects p into its public view. Photos must already have been
+// resolved from storage keys to URLs.
+func (p *Profile) Public() *PublicProfile {
+	return &PublicProfile{
+		UserID:                 p.UserID,
+		FirstName:              p.FirstName,
+		Age:                    p.Age,
+		Bio:                    p.Bio,
+		Photos:                 p.Photos,
+		PhotoVariants:          p.PhotoVariants,
+		SelfDescribedFlaws:     p.SelfDescribedFlaws,
+		SelfDescribedStrengths: p.SelfDescribedStrengths,
+		Gender:                 p.Gender,
+		Height:                 p.Height,
+		Languages:              p.Languages,
+		Education:              p.Education,
+		Occupation:             p.Occupation,
+		RelationshipGoal:       p.RelationshipGoal,
+		Smoking:                p.Smoking,
+		Drinking:               p.Drinking,
+		Kids:                   p.Kids,
+		DistanceBucket:         p.DistanceBucket,
+		TravelingIn:            p.TravelingIn,
+		IsVerified:             p.IsVerified,
+	}
+}
+
+// SearchResult is a profile as it appears in search results: the public
+// view plus the viewer-specific search fields.
+type SearchResult struct {
+	*PublicProfile
+	InteractionType  *string  `json:"interactionType,omitempty"`
+	SearchRank       *float64 `json:"searchRank,omitempty"`
+	SearchSnippet    *string  `json:"searchSnippet,omitempty"`
+	SuperLikedViewer bool     `json:"superLikedViewer,omitempty"`
+}
+
+// SearchResult projects p into a search result. Like Public, photos must
+// already have been resolved.
+func (p *Profile) SearchResult() *SearchResult {
+	return &SearchResult{
+		PublicProfile:    p.Public(),
+		InteractionType:  p.InteractionType,
+		SearchRank:       p.SearchRank,
+		SearchSnippet:    p.SearchSnippet,
+		SuperLikedViewer: p.SuperLikedViewer,
+	}
+}
+
+// View is a visit to a profile by another user.
+type View struct {
+	Viewer   *Profile
+	ViewedAt time.Time // Most recent visit
 }
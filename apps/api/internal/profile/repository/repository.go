@@ -2,16 +2,22 @@ package repository
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/kisssonik/hearts/internal/profile"
 )
 
-var ErrNotFound = errors.New("profile not found")
+var (
+	ErrNotFound         = errors.New("profile not found")
+	ErrVersionConflict  = errors.New("profile was modified by another request")
+	ErrRevisionNotFound = errors.New("profile revision not found")
+)
 
 type SearchParams struct {
 	MinAge    *int
@@ -20,14 +26,96 @@ type SearchParams struct {
 	Gender    *string
 	MinHeight *int
 	MaxHeight *int
+	Query     *string // Full-text query over bio, strengths and flaws
+	Verified  *bool
+	// Languages matches profiles that speak at least one of the codes.
+	Languages        []string
+	Education        *string
+	RelationshipGoal *string
+	Smoking          *string
+	Drinking         *string
+	Kids             *string
 }
 
 type ProfileRepository interface {
 	Create(ctx context.Context, p *profile.Profile) error
 	GetByUserID(ctx context.Context, userID string) (*profile.Profile, error)
 	GetByUserIDs(ctx context.Context, userIDs []string) ([]*profile.Profile, error)
+	GetVisibleByUserID(ctx context.Context, viewerID, userID string) (*profile.Profile, error)
 	Update(ctx context.Context, p *profile.Profile) error
 	Search(ctx context.Context, currentUser *profile.Profile, params SearchParams) ([]*profile.Profile, error)
+	SetPassport(ctx context.Context, userID string, passport *profile.Passport) error
+	ListRevisions(ctx context.Context, userID string, limit, offset int) ([]*profile.Revision, error)
+	GetRevision(ctx context.Context, userID string, version int) (*profile.Revision, error)
+	RecordView(ctx context.Context, viewerID, viewedID string) (bool, error)
+	ListViewers(ctx context.Context, userID string, limit, offset int) ([]*profile.View, error)
+}
+
+// profileColumns lists the columns read into a profile.Profile, in the order
+// expected by scanProfile. Queries must alias the profiles table as "p".
+const profileColumns = `p.id, p.user_id, p.first_name, p.bio, p.photos, p.self_described_flaws, p.self_described_strengths,
+	p.birth_date, p.gender, p.height, p.languages, p.education, p.occupation, p.relationship_goal, p.smoking, p.drinking, p.kids, p.latitude, p.longitude, p.fuzzed_latitude, p.fuzzed_longitude, p.visibility, p.is_verified,
+	p.passport_city, p.passport_latitude, p.passport_longitude, p.passport_expires_at, p.field_visibility, p.version, p.created_at, p.updated_at`
+
+// scanProfile scans a row selected with profileColumns into p. Any extra
+// destinations are scanned from the columns following profileColumns.
+// Expired passports are dropped.
+func scanProfile(row pgx.Row, p *profile.Profile, extra ...any) error {
+	var passportCity *string
+	var passportLat, passportLon *float64
+	var passportExpiresAt *time.Time
+	dest := []any{
+		&p.ID, &p.UserID, &p.FirstName, &p.Bio, &p.Photos, &p.SelfDescribedFlaws, &p.SelfDescribedStrengths,
+		&p.BirthDate, &p.Gender, &p.Height, &p.Languages, &p.Education, &p.Occupation, &p.RelationshipGoal, &p.Smoking, &p.Drinking, &p.Kids, &p.Latitude, &p.Longitude, &p.FuzzedLatitude, &p.FuzzedLongitude, &p.Visibility, &p.IsVerified,
+		&passportCity, &passportLat, &passportLon, &passportExpiresAt, &p.FieldVisibility, &p.Version, &p.CreatedAt, &p.UpdatedAt,
+	}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return err
+	}
+	now := time.Now()
+	p.ComputeAge(now)
+	if passportCity != nil && passportLat != nil && passportLon != nil && passportExpiresAt != nil {
+		passport := &profile.Passport{City: *passportCity, Latitude: *passportLat, Longitude: *passportLon, ExpiresAt: *passportExpiresAt}
+		if passport.Active(now) {
+			p.Passport = passport
+		}
+	}
+	return nil
+}
+
+// matchedWithSQL is a condition that holds when profile p and the user bound
+// to param are matched.
+func matchedWithSQL(param string) string {
+	return `EXISTS (
+		SELECT 1 FROM matches m
+		WHERE (m.user1_id = p.user_id AND m.user2_id = ` + param + `)
+		   OR (m.user1_id = ` + param + ` AND m.user2_id = p.user_id)
+	)`
+}
+
+// fieldVisibleSQL is a condition that holds when profile p shows field to
+// the user bound to param. Filters on private fields must include it, or the
+// filter would reveal hidden values.
+func fieldVisibleSQL(field, param string) string {
+	return fmt.Sprintf(`(COALESCE(p.field_visibility->>'%s', '%s') = '%s' OR (p.field_visibility->>'%s' = '%s' AND %s))`,
+		field, profile.PrivacyEveryone, profile.PrivacyEveryone, field, profile.PrivacyMatches, matchedWithSQL(param))
+}
+
+// fieldVisibility returns the value stored in field_visibility, which must
+// be a JSON object.
+func fieldVisibility(p *profile.Profile) map[string]string {
+	if p.FieldVisibility == nil {
+		return map[string]string{}
+	}
+	return p.FieldVisibility
+}
+
+// languages returns the value stored in languages, which is NOT NULL.
+func languages(p *profile.Profile) []string {
+	if p.Languages == nil {
+		return []string{}
+	}
+	return p.Languages
 }
 
 type pgxProfileRepository struct {
@@ -39,26 +127,35 @@ func NewProfileRepository(db *pgxpool.Pool) ProfileRepository {
 }
 
 func (r *pgxProfileRepository) Create(ctx context.Context, p *profile.Profile) error {
+	if p.Visibility == "" {
+		p.Visibility = profile.VisibilityVisible
+	}
+	// Photos are owned by profile_photos; pick up any uploaded before the
+	// profile existed.
 	query := `
-		INSERT INTO profiles (user_id, first_name, bio, photos, self_described_flaws, self_described_strengths, birth_date, gender, height, latitude, longitude)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
-		RETURNING id, created_at, updated_at
+		INSERT INTO profiles (user_id, first_name, bio, photos, self_described_flaws, self_described_strengths, birth_date, gender, height, latitude, longitude, fuzzed_latitude, fuzzed_longitude, visibility, field_visibility,
+			languages, education, occupation, relationship_goal, smoking, drinking, kids)
+		VALUES ($1, $2, $3, ARRAY(
+			SELECT storage_key FROM profile_photos
+			WHERE user_id = $1 AND status = 'ready' AND moderation_status = 'approved'
+			ORDER BY is_primary DESC, position ASC
+		), $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21)
+		RETURNING id, photos, version, created_at, updated_at
 	`
 	return r.db.QueryRow(ctx, query,
-		p.UserID, p.FirstName, p.Bio, p.Photos, p.SelfDescribedFlaws, p.SelfDescribedStrengths, p.BirthDate, p.Gender, p.Height, p.Latitude, p.Longitude,
-	).Scan(&p.ID, &p.CreatedAt, &p.UpdatedAt)
+		p.UserID, p.FirstName, p.Bio, p.SelfDescribedFlaws, p.SelfDescribedStrengths, p.BirthDate, p.Gender, p.Height, p.Latitude, p.Longitude, p.FuzzedLatitude, p.FuzzedLongitude, p.Visibility, fieldVisibility(p),
+		languages(p), p.Education, p.Occupation, p.RelationshipGoal, p.Smoking, p.Drinking, p.Kids,
+	).Scan(&p.ID, &p.Photos, &p.Version, &p.CreatedAt, &p.UpdatedAt)
 }
 
 func (r *pgxProfileRepository) GetByUserID(ctx context.Context, userID string) (*profile.Profile, error) {
 	query := `
-		SELECT id, user_id, first_name, bio, photos, self_described_flaws, self_described_strengths, birth_date, gender, height, latitude, longitude, created_at, updated_at
-		FROM profiles
-		WHERE user_id = $1
+		SELECT ` + profileColumns + `
+		FROM profiles p
+		WHERE p.user_id = $1
 	`
 	p := &profile.Profile{}
-	err := r.db.QueryRow(ctx, query, userID).Scan(
-		&p.ID, &p.UserID, &p.FirstName, &p.Bio, &p.Photos, &p.SelfDescribedFlaws, &p.SelfDescribedStrengths, &p.BirthDate, &p.Gender, &p.Height, &p.Latitude, &p.Longitude, &p.CreatedAt, &p.UpdatedAt,
-	)
+	err := scanProfile(r.db.QueryRow(ctx, query, userID), p)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, ErrNotFound
@@ -70,9 +167,9 @@ func (r *pgxProfileRepository) GetByUserID(ctx context.Context, userID string) (
 
 func (r *pgxProfileRepository) GetByUserIDs(ctx context.Context, userIDs []string) ([]*profile.Profile, error) {
 	query := `
-		SELECT id, user_id, first_name, bio, photos, self_described_flaws, self_described_strengths, birth_date, gender, height, latitude, longitude, created_at, updated_at
-		FROM profiles
-		WHERE user_id = ANY($1)
+		SELECT ` + profileColumns + `
+		FROM profiles p
+		WHERE p.user_id = ANY($1)
 	`
 	rows, err := r.db.Query(ctx, query, userIDs)
 	if err != nil {
@@ -83,9 +180,7 @@ func (r *pgxProfileRepository) GetByUserIDs(ctx context.Context, userIDs []strin
 	var profiles []*profile.Profile
 	for rows.Next() {
 		p := &profile.Profile{}
-		if err := rows.Scan(
-			&p.ID, &p.UserID, &p.FirstName, &p.Bio, &p.Photos, &p.SelfDescribedFlaws, &p.SelfDescribedStrengths, &p.BirthDate, &p.Gender, &p.Height, &p.Latitude, &p.Longitude, &p.CreatedAt, &p.UpdatedAt,
-		); err != nil {
+		if err := scanProfile(rows, p); err != nil {
 			return nil, err
 		}
 		profiles = append(profiles, p)
@@ -93,16 +188,162 @@ func (r *pgxProfileRepository) GetByUserIDs(ctx context.Context, userIDs []strin
 	return profiles, nil
 }
 
+// GetVisibleByUserID returns userID's profile only if viewerID is allowed to
+// see it. Profiles that blocked the viewer, paused profiles the viewer is not
+// matched with, and incognito profiles that have not liked the viewer are
+// reported as ErrNotFound so their existence is not leaked.
+func (r *pgxProfileRepository) GetVisibleByUserID(ctx context.Context, viewerID, userID string) (*profile.Profile, error) {
+	query := fmt.Sprintf(`
+		SELECT `+profileColumns+`, `+matchedWithSQL("$2")+` AS matched_with_viewer
+		FROM profiles p
+		WHERE p.user_id = $1
+		  AND NOT EXISTS (SELECT 1 FROM user_blocks b WHERE b.blocker_id = p.user_id AND b.blocked_id = $2)
+		  AND (
+			p.user_id = $2
+			OR p.visibility = '%s'
+			OR (p.visibility = '%s' AND EXISTS (
+				SELECT 1 FROM likes l WHERE l.from_user_id = p.user_id AND l.to_user_id = $2 AND l.is_like = TRUE
+			))
+			OR (p.visibility = '%s' AND `+matchedWithSQL("$2")+`)
+		  )
+	`, profile.VisibilityVisible, profile.VisibilityIncognito, profile.VisibilityPaused)
+	p := &profile.Profile{}
+	err := scanProfile(r.db.QueryRow(ctx, query, userID, viewerID), p, &p.MatchedWithViewer)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return p, nil
+}
+
+// Update writes p if it is still at p.Version and bumps the version. If
+// another update got there first it returns ErrVersionConflict. The content
+// being replaced is kept as a revision, in the same transaction.
 func (r *pgxProfileRepository) Update(ctx context.Context, p *profile.Profile) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	// Locking the row makes a concurrent update wait and then see the new
+	// version, so each version is recorded once.
+	current := &profile.Profile{}
+	err = scanProfile(tx.QueryRow(ctx, `
+		SELECT `+profileColumns+`
+		FROM profiles p
+		WHERE p.user_id = $1 AND p.version = $2
+		FOR UPDATE
+	`, p.UserID, p.Version), current)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrVersionConflict
+		}
+		return err
+	}
+
+	snapshot, err := json.Marshal(current.Snapshot())
+	if err != nil {
+		return err
+	}
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO profile_revisions (user_id, version, snapshot)
+		VALUES ($1, $2, $3)
+	`, current.UserID, current.Version, snapshot); err != nil {
+		return err
+	}
+
 	query := `
 		UPDATE profiles
-		SET first_name = $1, bio = $2, photos = $3, self_described_flaws = $4, self_described_strengths = $5, birth_date = $6, gender = $7, height = $8, latitude = $9, longitude = $10, updated_at = NOW()
-		WHERE user_id = $11
-		RETURNING updated_at
+		SET first_name = $1, bio = $2, self_described_flaws = $3, self_described_strengths = $4, birth_date = $5, gender = $6, height = $7, latitude = $8, longitude = $9, fuzzed_latitude = $10, fuzzed_longitude = $11, visibility = $12,
+			field_visibility = $13, languages = $14, education = $15, occupation = $16, relationship_goal = $17, smoking = $18, drinking = $19, kids = $20,
+			version = version + 1, updated_at = NOW()
+		WHERE user_id = $21
+		RETURNING version, updated_at
 	`
-	return r.db.QueryRow(ctx, query,
-		p.FirstName, p.Bio, p.Photos, p.SelfDescribedFlaws, p.SelfDescribedStrengths, p.BirthDate, p.Gender, p.Height, p.Latitude, p.Longitude, p.UserID,
-	).Scan(&p.UpdatedAt)
+	if err := tx.QueryRow(ctx, query,
+		p.FirstName, p.Bio, p.SelfDescribedFlaws, p.SelfDescribedStrengths, p.BirthDate, p.Gender, p.Height, p.Latitude, p.Longitude, p.FuzzedLatitude, p.FuzzedLongitude, p.Visibility, fieldVisibility(p),
+		languages(p), p.Education, p.Occupation, p.RelationshipGoal, p.Smoking, p.Drinking, p.Kids, p.UserID,
+	).Scan(&p.Version, &p.UpdatedAt); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+const revisionColumns = `id, user_id, version, snapshot, created_at`
+
+func scanRevision(row pgx.Row, rev *profile.Revision) error {
+	return row.Scan(&rev.ID, &rev.UserID, &rev.Version, &rev.Snapshot, &rev.CreatedAt)
+}
+
+// ListRevisions returns userID's earlier versions, newest first.
+func (r *pgxProfileRepository) ListRevisions(ctx context.Context, userID string, limit, offset int) ([]*profile.Revision, error) {
+	query := `
+		SELECT ` + revisionColumns + `
+		FROM profile_revisions
+		WHERE user_id = $1
+		ORDER BY version DESC
+		LIMIT $2 OFFSET $3
+	`
+	rows, err := r.db.Query(ctx, query, userID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var revisions []*profile.Revision
+	for rows.Next() {
+		rev := &profile.Revision{}
+		if err := scanRevision(rows, rev); err != nil {
+			return nil, err
+		}
+		revisions = append(revisions, rev)
+	}
+	return revisions, rows.Err()
+}
+
+func (r *pgxProfileRepository) GetRevision(ctx context.Context, userID string, version int) (*profile.Revision, error) {
+	query := `
+		SELECT ` + revisionColumns + `
+		FROM profile_revisions
+		WHERE user_id = $1 AND version = $2
+	`
+	rev := &profile.Revision{}
+	if err := scanRevision(r.db.QueryRow(ctx, query, userID, version), rev); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrRevisionNotFound
+		}
+		return nil, err
+	}
+	return rev, nil
+}
+
+// SetPassport sets or, when passport is nil, clears userID's travel
+// location. Like the home location, the passport is a setting rather than
+// versioned content, so it leaves the version and revisions alone.
+func (r *pgxProfileRepository) SetPassport(ctx context.Context, userID string, passport *profile.Passport) error {
+	var city *string
+	var lat, lon *float64
+	var expiresAt *time.Time
+	if passport != nil {
+		city, lat, lon, expiresAt = &passport.City, &passport.Latitude, &passport.Longitude, &passport.ExpiresAt
+	}
+	query := `
+		UPDATE profiles
+		SET passport_city = $1, passport_latitude = $2, passport_longitude = $3, passport_expires_at = $4,
+			updated_at = NOW()
+		WHERE user_id = $5
+	`
+	tag, err := r.db.Exec(ctx, query, city, lat, lon, expiresAt, userID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
 }
 
 func (r *pgxProfileRepository) Search(ctx context.Context, currentUser *profile.Profile, params SearchParams) ([]*profile.Profile, error) {
@@ -115,9 +356,18 @@ func (r *pgxProfileRepository) Search(ctx context.Context, currentUser *profile.
 	args = append(args, currentUser.UserID)
 	argIdx++
 
-	// REMOVED explicit exclusion of liked users. 
+	// REMOVED explicit exclusion of liked users.
 	// Instead we will join with likes to get status.
 
+	// Never show users who blocked the searcher.
+	conditions = append(conditions, "NOT EXISTS (SELECT 1 FROM user_blocks b WHERE b.blocker_id = p.user_id AND b.blocked_id = $1)")
+
+	// Respect visibility: paused profiles never show up, incognito profiles
+	// only show up for users they have liked themselves.
+	conditions = append(conditions, fmt.Sprintf(`(p.visibility = '%s' OR (p.visibility = '%s' AND EXISTS (
+		SELECT 1 FROM likes il WHERE il.from_user_id = p.user_id AND il.to_user_id = $1 AND il.is_like = TRUE
+	)))`, profile.VisibilityVisible, profile.VisibilityIncognito))
+
 	if params.MinAge != nil {
 		conditions = append(conditions, fmt.Sprintf("EXTRACT(YEAR FROM AGE(p.birth_date)) >= $%d", argIdx))
 		args = append(args, *params.MinAge)
@@ -131,11 +381,16 @@ func (r *pgxProfileRepository) Search(ctx context.Context, currentUser *profile.
 	}
 
 	if params.Gender != nil {
+		conditions = append(conditions, fieldVisibleSQL(profile.FieldGender, "$1"))
 		conditions = append(conditions, fmt.Sprintf("p.gender = $%d", argIdx))
 		args = append(args, *params.Gender)
 		argIdx++
 	}
 
+	if params.MinHeight != nil || params.MaxHeight != nil {
+		conditions = append(conditions, fieldVisibleSQL(profile.FieldHeight, "$1"))
+	}
+
 	if params.MinHeight != nil {
 		conditions = append(conditions, fmt.Sprintf("p.height >= $%d", argIdx))
 		args = append(args, *params.MinHeight)
@@ -148,18 +403,87 @@ func (r *pgxProfileRepository) Search(ctx context.Context, currentUser *profile.
 		argIdx++
 	}
 
-	if params.RadiusKM != nil && currentUser.Latitude != nil && currentUser.Longitude != nil {
+	if params.Verified != nil {
+		conditions = append(conditions, fmt.Sprintf("p.is_verified = $%d", argIdx))
+		args = append(args, *params.Verified)
+		argIdx++
+	}
+
+	if len(params.Languages) > 0 {
+		conditions = append(conditions, fieldVisibleSQL(profile.FieldLanguages, "$1"))
+		conditions = append(conditions, fmt.Sprintf("p.languages && $%d", argIdx))
+		args = append(args, params.Languages)
+		argIdx++
+	}
+
+	// Enum attributes, each filterable only where the owner shows it.
+	for _, filter := range []struct {
+		field, column string
+		value         *string
+	}{
+		{profile.FieldEducation, "education", params.Education},
+		{profile.FieldRelationshipGoal, "relationship_goal", params.RelationshipGoal},
+		{profile.FieldSmoking, "smoking", params.Smoking},
+		{profile.FieldDrinking, "drinking", params.Drinking},
+		{profile.FieldKids, "kids", params.Kids},
+	} {
+		if filter.value == nil {
+			continue
+		}
+		conditions = append(conditions, fieldVisibleSQL(filter.field, "$1"))
+		conditions = append(conditions, fmt.Sprintf("p.%s = $%d", filter.column, argIdx))
+		args = append(args, *filter.value)
+		argIdx++
+	}
+
+	// Full-text search. websearch_to_tsquery accepts free-form user input
+	// ("jazz -country", "\"rock climbing\"") without raising syntax errors.
+	// People who super liked the searcher and are still waiting for an
+	// answer come first, then more complete profiles; full-text relevance
+	// takes precedence over completeness when there is a query.
+	rankSelect := "NULL::float8 AS search_rank, NULL::text AS search_snippet"
+	orderClause := "ORDER BY super_liked_viewer DESC, p.completeness_score DESC"
+	if params.Query != nil {
+		// The bio is always public; strengths and flaws only match, and
+		// only count towards the rank, where the owner shows them.
+		strengthsVisible := fieldVisibleSQL(profile.FieldSelfDescribedStrengths, "$1")
+		flawsVisible := fieldVisibleSQL(profile.FieldSelfDescribedFlaws, "$1")
+		conditions = append(conditions, fmt.Sprintf(`(
+			p.search_vector @@ websearch_to_tsquery('english', $%d)
+			OR (p.strengths_vector @@ websearch_to_tsquery('english', $%d) AND %s)
+			OR (p.flaws_vector @@ websearch_to_tsquery('english', $%d) AND %s)
+		)`, argIdx, argIdx, strengthsVisible, argIdx, flawsVisible))
+		visibleVector := fmt.Sprintf(`(p.search_vector
+			|| CASE WHEN %s THEN p.strengths_vector ELSE ''::tsvector END
+			|| CASE WHEN %s THEN p.flaws_vector ELSE ''::tsvector END)`, strengthsVisible, flawsVisible)
+		// The snippet is markup, so the user-written bio is HTML-escaped
+		// before ts_headline adds the <mark> tags.
+		const escapedBio = `replace(replace(replace(COALESCE(p.bio, ''), '&', '&amp;'), '<', '&lt;'), '>', '&gt;')`
+		rankSelect = fmt.Sprintf(`
+			ts_rank(`+visibleVector+`, websearch_to_tsquery('english', $%d))::float8 AS search_rank,
+			ts_headline('english', `+escapedBio+`, websearch_to_tsquery('english', $%d),
+				'StartSel=<mark>, StopSel=</mark>, MaxWords=35, MinWords=15') AS search_snippet`, argIdx, argIdx)
+		orderClause = "ORDER BY super_liked_viewer DESC, search_rank DESC, p.completeness_score DESC"
+		args = append(args, *params.Query)
+		argIdx++
+	}
+
+	// Travelers search around their passport location.
+	originLat, originLon := currentUser.SearchLocation(time.Now())
+	if params.RadiusKM != nil && originLat != nil && originLon != nil {
 		// Haversine formula
 		// 6371 is Earth radius in km
+		// Other users are matched on their fuzzed location only, so the
+		// radius filter cannot be used to trilaterate their real position.
 		haversine := fmt.Sprintf(`
-			(6371 * acos(
-				cos(radians($%d)) * cos(radians(p.latitude)) * cos(radians(p.longitude) - radians($%d)) +
-				sin(radians($%d)) * sin(radians(p.latitude))
-			)) <= $%d
+			(6371 * acos(LEAST(1,
+				cos(radians($%d)) * cos(radians(p.fuzzed_latitude)) * cos(radians(p.fuzzed_longitude) - radians($%d)) +
+				sin(radians($%d)) * sin(radians(p.fuzzed_latitude))
+			))) <= $%d
 		`, argIdx, argIdx+1, argIdx, argIdx+2)
 
 		conditions = append(conditions, haversine)
-		args = append(args, *currentUser.Latitude, *currentUser.Longitude, *params.RadiusKM)
+		args = append(args, *originLat, *originLon, *params.RadiusKM)
 		argIdx += 3
 	}
 
@@ -179,18 +503,24 @@ func (r *pgxProfileRepository) Search(ctx context.Context, currentUser *profile.
 
 	query := fmt.Sprintf(`
 		SELECT 
-			p.id, p.user_id, p.first_name, p.bio, p.photos, p.self_described_flaws, p.self_described_strengths, 
-			p.birth_date, p.gender, p.height, p.latitude, p.longitude, p.created_at, p.updated_at,
+			`+profileColumns+`,
 			CASE 
-				WHEN l.is_like IS TRUE THEN 'like'
+				WHEN l.is_like IS TRUE THEN l.kind
 				WHEN l.is_like IS FALSE THEN 'pass'
 				ELSE NULL 
-			END as interaction_type
+			END as interaction_type,
+			`+matchedWithSQL("$1")+` AS matched_with_viewer,
+			l.id IS NULL AND EXISTS (
+				SELECT 1 FROM likes sl
+				WHERE sl.from_user_id = p.user_id AND sl.to_user_id = $1 AND sl.is_like = TRUE AND sl.kind = 'superlike'
+			) AS super_liked_viewer,
+			%s
 		FROM profiles p
 		LEFT JOIN likes l ON p.user_id = l.to_user_id AND l.from_user_id = $%d
 		%s
+		%s
 		LIMIT 50
-	`, joinArgIdx, whereClause)
+	`, rankSelect, joinArgIdx, whereClause, orderClause)
 
 	rows, err := r.db.Query(ctx, query, args...)
 	if err != nil {
@@ -201,12 +531,64 @@ func (r *pgxProfileRepository) Search(ctx context.Context, currentUser *profile.
 	var profiles []*profile.Profile
 	for rows.Next() {
 		p := &profile.Profile{}
-		if err := rows.Scan(
-			&p.ID, &p.UserID, &p.FirstName, &p.Bio, &p.Photos, &p.SelfDescribedFlaws, &p.SelfDescribedStrengths, &p.BirthDate, &p.Gender, &p.Height, &p.Latitude, &p.Longitude, &p.CreatedAt, &p.UpdatedAt, &p.InteractionType,
-		); err != nil {
+		if err := scanProfile(rows, p, &p.InteractionType, &p.MatchedWithViewer, &p.SuperLikedViewer, &p.SearchRank, &p.SearchSnippet); err != nil {
 			return nil, err
 		}
 		profiles = append(profiles, p)
 	}
 	return profiles, nil
 }
+
+// RecordView stores a visit from viewerID to viewedID. It reports false if
+// the viewer had already been recorded today.
+func (r *pgxProfileRepository) RecordView(ctx context.Context, viewerID, viewedID string) (bool, error) {
+	query := `
+		INSERT INTO profile_views (viewer_id, viewed_id)
+		VALUES ($1, $2)
+		ON CONFLICT (viewer_id, viewed_id, view_date) DO NOTHING
+	`
+	tag, err := r.db.Exec(ctx, query, viewerID, viewedID)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+// ListViewers returns the users who viewed userID's profile, most recent
+// first, one entry per viewer. Viewers who are now incognito or paused, and
+// users either side has blocked, are left out.
+func (r *pgxProfileRepository) ListViewers(ctx context.Context, userID string, limit, offset int) ([]*profile.View, error) {
+	query := fmt.Sprintf(`
+		SELECT `+profileColumns+`, v.viewed_at, `+matchedWithSQL("$1")+` AS matched_with_viewer
+		FROM (
+			SELECT viewer_id, MAX(viewed_at) AS viewed_at
+			FROM profile_views
+			WHERE viewed_id = $1
+			GROUP BY viewer_id
+		) v
+		JOIN profiles p ON p.user_id = v.viewer_id
+		WHERE p.visibility = '%s'
+		  AND NOT EXISTS (
+			SELECT 1 FROM user_blocks b
+			WHERE (b.blocker_id = p.user_id AND b.blocked_id = $1)
+			   OR (b.blocker_id = $1 AND b.blocked_id = p.user_id)
+		  )
+		ORDER BY v.viewed_at DESC
+		LIMIT $2 OFFSET $3
+	`, profile.VisibilityVisible)
+	rows, err := r.db.Query(ctx, query, userID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var views []*profile.View
+	for rows.Next() {
+		v := &profile.View{Viewer: &profile.Profile{}}
+		if err := scanProfile(rows, v.Viewer, &v.ViewedAt, &v.Viewer.MatchedWithViewer); err != nil {
+			return nil, err
+		}
+		views = append(views, v)
+	}
+	return views, rows.Err()
+}
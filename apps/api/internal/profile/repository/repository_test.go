@@ -56,7 +56,6 @@ func TestProfileRepository_Create(t *testing.T) {
 		UserID:                 u.ID,
 		FirstName:              "John",
 		Bio:                    "Hello world",
-		Photos:                 []string{"photo1.jpg"},
 		SelfDescribedFlaws:     []string{"flaw1"},
 		SelfDescribedStrengths: []string{"strength1"},
 	}
@@ -119,4 +118,447 @@ func TestProfileRepository_Update(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, "Jane", fetched.FirstName)
 	assert.Equal(t, "Updated bio", fetched.Bio)
+	assert.Equal(t, p.Version, fetched.Version)
+}
+
+func TestProfileRepository_Update_VersionConflict(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	db := setupTestDB(t)
+	defer db.Close()
+
+	u := createTestUser(t, db, "test@example.com", "testuser")
+	repo := repository.NewProfileRepository(db)
+	ctx := context.Background()
+
+	require.NoError(t, repo.Create(ctx, &profile.Profile{UserID: u.ID, FirstName: "John"}))
+
+	// Two tabs read the same version.
+	first, err := repo.GetByUserID(ctx, u.ID)
+	require.NoError(t, err)
+	second, err := repo.GetByUserID(ctx, u.ID)
+	require.NoError(t, err)
+
+	first.Bio = "From the first tab"
+	require.NoError(t, repo.Update(ctx, first))
+	assert.Equal(t, second.Version+1, first.Version)
+
+	second.Bio = "From the second tab"
+	assert.ErrorIs(t, repo.Update(ctx, second), repository.ErrVersionConflict)
+
+	fetched, err := repo.GetByUserID(ctx, u.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "From the first tab", fetched.Bio)
+}
+
+func TestProfileRepository_Search_Visibility(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	db := setupTestDB(t)
+	defer db.Close()
+
+	viewer := createTestUser(t, db, "viewer@example.com", "viewer")
+	target := createTestUser(t, db, "target@example.com", "target")
+	repo := repository.NewProfileRepository(db)
+	ctx := context.Background()
+
+	viewerProfile := &profile.Profile{UserID: viewer.ID, FirstName: "Viewer"}
+	require.NoError(t, repo.Create(ctx, viewerProfile))
+	targetProfile := &profile.Profile{UserID: target.ID, FirstName: "Target"}
+	require.NoError(t, repo.Create(ctx, targetProfile))
+
+	search := func() []*profile.Profile {
+		found, err := repo.Search(ctx, viewerProfile, repository.SearchParams{})
+		require.NoError(t, err)
+		return found
+	}
+
+	assert.Len(t, search(), 1)
+
+	targetProfile.Visibility = profile.VisibilityPaused
+	require.NoError(t, repo.Update(ctx, targetProfile))
+	assert.Empty(t, search())
+
+	targetProfile.Visibility = profile.VisibilityIncognito
+	require.NoError(t, repo.Update(ctx, targetProfile))
+	assert.Empty(t, search())
+
+	_, err := db.Exec(ctx, "INSERT INTO likes (from_user_id, to_user_id, is_like) VALUES ($1, $2, TRUE)", target.ID, viewer.ID)
+	require.NoError(t, err)
+	assert.Len(t, search(), 1)
+}
+
+func TestProfileRepository_Search_RanksByCompleteness(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	db := setupTestDB(t)
+	defer db.Close()
+
+	viewer := createTestUser(t, db, "viewer@example.com", "viewer")
+	sparse := createTestUser(t, db, "sparse@example.com", "sparse")
+	full := createTestUser(t, db, "full@example.com", "full")
+	repo := repository.NewProfileRepository(db)
+	ctx := context.Background()
+
+	viewerProfile := &profile.Profile{UserID: viewer.ID, FirstName: "Viewer"}
+	require.NoError(t, repo.Create(ctx, viewerProfile))
+	require.NoError(t, repo.Create(ctx, &profile.Profile{UserID: sparse.ID, FirstName: "Sparse"}))
+
+	birthDate := time.Date(1995, 1, 1, 0, 0, 0, 0, time.UTC)
+	lat, lon := 52.52, 13.40
+	fullProfile := &profile.Profile{
+		UserID:                 full.ID,
+		FirstName:              "Full",
+		Bio:                    "Hello",
+		SelfDescribedStrengths: []string{"Patient"},
+		SelfDescribedFlaws:     []string{"Stubborn"},
+		BirthDate:              &birthDate,
+		Latitude:               &lat,
+		Longitude:              &lon,
+	}
+	require.NoError(t, repo.Create(ctx, fullProfile))
+
+	// The stored score must agree with the Go calculation.
+	var score int
+	require.NoError(t, db.QueryRow(ctx, "SELECT completeness_score FROM profiles WHERE user_id = $1", full.ID).Scan(&score))
+	fullProfile.ComputeCompleteness()
+	assert.Equal(t, fullProfile.Completeness.Score, score)
+
+	found, err := repo.Search(ctx, viewerProfile, repository.SearchParams{})
+	require.NoError(t, err)
+	require.Len(t, found, 2)
+	assert.Equal(t, full.ID, found[0].UserID)
+	assert.Equal(t, sparse.ID, found[1].UserID)
+}
+
+func TestProfileRepository_Search_SuperLikersFirst(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	db := setupTestDB(t)
+	defer db.Close()
+
+	viewer := createTestUser(t, db, "viewer@example.com", "viewer")
+	sparse := createTestUser(t, db, "sparse@example.com", "sparse")
+	full := createTestUser(t, db, "full@example.com", "full")
+	repo := repository.NewProfileRepository(db)
+	ctx := context.Background()
+
+	viewerProfile := &profile.Profile{UserID: viewer.ID, FirstName: "Viewer"}
+	require.NoError(t, repo.Create(ctx, viewerProfile))
+	require.NoError(t, repo.Create(ctx, &profile.Profile{UserID: sparse.ID, FirstName: "Sparse"}))
+	require.NoError(t, repo.Create(ctx, &profile.Profile{UserID: full.ID, FirstName: "Full", Bio: "Hello", SelfDescribedStrengths: []string{"Patient"}}))
+
+	_, err := db.Exec(ctx, "INSERT INTO likes (from_user_id, to_user_id, is_like, kind) VALUES ($1, $2, TRUE, 'superlike')", sparse.ID, viewer.ID)
+	require.NoError(t, err)
+
+	found, err := repo.Search(ctx, viewerProfile, repository.SearchParams{})
+	require.NoError(t, err)
+	require.Len(t, found, 2)
+	assert.Equal(t, sparse.ID, found[0].UserID)
+	assert.True(t, found[0].SuperLikedViewer)
+	assert.False(t, found[1].SuperLikedViewer)
+
+	// Once answered, the super liker loses the boost.
+	_, err = db.Exec(ctx, "INSERT INTO likes (from_user_id, to_user_id, is_like) VALUES ($1, $2, FALSE)", viewer.ID, sparse.ID)
+	require.NoError(t, err)
+	found, err = repo.Search(ctx, viewerProfile, repository.SearchParams{})
+	require.NoError(t, err)
+	require.Len(t, found, 2)
+	assert.Equal(t, full.ID, found[0].UserID)
+}
+
+func TestProfileRepository_Views(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	db := setupTestDB(t)
+	defer db.Close()
+
+	owner := createTestUser(t, db, "owner@example.com", "owner")
+	visitor := createTestUser(t, db, "visitor@example.com", "visitor")
+	blocked := createTestUser(t, db, "blocked@example.com", "blocked")
+	repo := repository.NewProfileRepository(db)
+	ctx := context.Background()
+
+	for _, u := range []*user.User{owner, visitor, blocked} {
+		require.NoError(t, repo.Create(ctx, &profile.Profile{UserID: u.ID, FirstName: u.Username}))
+	}
+
+	isNew, err := repo.RecordView(ctx, visitor.ID, owner.ID)
+	require.NoError(t, err)
+	assert.True(t, isNew)
+
+	// A second visit on the same day is not a new view.
+	isNew, err = repo.RecordView(ctx, visitor.ID, owner.ID)
+	require.NoError(t, err)
+	assert.False(t, isNew)
+
+	_, err = repo.RecordView(ctx, blocked.ID, owner.ID)
+	require.NoError(t, err)
+	_, err = db.Exec(ctx, "INSERT INTO user_blocks (blocker_id, blocked_id) VALUES ($1, $2)", owner.ID, blocked.ID)
+	require.NoError(t, err)
+
+	views, err := repo.ListViewers(ctx, owner.ID, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, views, 1)
+	assert.Equal(t, visitor.ID, views[0].Viewer.UserID)
+	assert.False(t, views[0].ViewedAt.IsZero())
+}
+
+func TestProfileRepository_Search_Passport(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	db := setupTestDB(t)
+	defer db.Close()
+
+	traveler := createTestUser(t, db, "traveler@example.com", "traveler")
+	local := createTestUser(t, db, "local@example.com", "local")
+	repo := repository.NewProfileRepository(db)
+	ctx := context.Background()
+
+	berlinLat, berlinLon := 52.52, 13.40
+	travelerProfile := &profile.Profile{UserID: traveler.ID, FirstName: "Traveler", Latitude: &berlinLat, Longitude: &berlinLon}
+	travelerProfile.FuzzLocation()
+	require.NoError(t, repo.Create(ctx, travelerProfile))
+
+	lisbonLat, lisbonLon := 38.72, -9.14
+	localProfile := &profile.Profile{UserID: local.ID, FirstName: "Local", Latitude: &lisbonLat, Longitude: &lisbonLon}
+	localProfile.FuzzLocation()
+	require.NoError(t, repo.Create(ctx, localProfile))
+
+	radius := 25.0
+	search := func() []*profile.Profile {
+		current, err := repo.GetByUserID(ctx, traveler.ID)
+		require.NoError(t, err)
+		found, err := repo.Search(ctx, current, repository.SearchParams{RadiusKM: &radius})
+		require.NoError(t, err)
+		return found
+	}
+
+	assert.Empty(t, search())
+
+	passport := &profile.Passport{City: "Lisbon", Latitude: lisbonLat, Longitude: lisbonLon, ExpiresAt: time.Now().Add(24 * time.Hour)}
+	require.NoError(t, repo.SetPassport(ctx, traveler.ID, passport))
+	assert.Len(t, search(), 1)
+
+	fetched, err := repo.GetByUserID(ctx, traveler.ID)
+	require.NoError(t, err)
+	if assert.NotNil(t, fetched.Passport) {
+		assert.Equal(t, "Lisbon", fetched.Passport.City)
+	}
+	assert.Equal(t, travelerProfile.Version, fetched.Version)
+	revisions, err := repo.ListRevisions(ctx, traveler.ID, 10, 0)
+	require.NoError(t, err)
+	assert.Empty(t, revisions)
+
+	require.NoError(t, repo.SetPassport(ctx, traveler.ID, nil))
+	assert.Empty(t, search())
+}
+
+func TestProfileRepository_Update_RecordsRevision(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	db := setupTestDB(t)
+	defer db.Close()
+
+	u := createTestUser(t, db, "test@example.com", "testuser")
+	repo := repository.NewProfileRepository(db)
+	ctx := context.Background()
+
+	p := &profile.Profile{UserID: u.ID, FirstName: "John", Bio: "First bio"}
+	require.NoError(t, repo.Create(ctx, p))
+
+	p.Bio = "Second bio"
+	require.NoError(t, repo.Update(ctx, p))
+
+	// A conflicting update records nothing.
+	stale := *p
+	stale.Version = 1
+	assert.ErrorIs(t, repo.Update(ctx, &stale), repository.ErrVersionConflict)
+
+	revisions, err := repo.ListRevisions(ctx, u.ID, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, revisions, 1)
+	assert.Equal(t, 1, revisions[0].Version)
+	assert.Equal(t, "First bio", revisions[0].Snapshot.Bio)
+
+	rev, err := repo.GetRevision(ctx, u.ID, 1)
+	require.NoError(t, err)
+	assert.Equal(t, "John", rev.Snapshot.FirstName)
+
+	_, err = repo.GetRevision(ctx, u.ID, 2)
+	assert.ErrorIs(t, err, repository.ErrRevisionNotFound)
+}
+
+func TestProfileRepository_Search_FieldPrivacy(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	db := setupTestDB(t)
+	defer db.Close()
+
+	viewer := createTestUser(t, db, "viewer@example.com", "viewer")
+	target := createTestUser(t, db, "target@example.com", "target")
+	repo := repository.NewProfileRepository(db)
+	ctx := context.Background()
+
+	viewerProfile := &profile.Profile{UserID: viewer.ID, FirstName: "Viewer"}
+	require.NoError(t, repo.Create(ctx, viewerProfile))
+	gender := profile.GenderFemale
+	targetProfile := &profile.Profile{
+		UserID:          target.ID,
+		FirstName:       "Target",
+		Gender:          &gender,
+		FieldVisibility: map[string]string{profile.FieldGender: profile.PrivacyMatches},
+	}
+	require.NoError(t, repo.Create(ctx, targetProfile))
+
+	searchByGender := func() []*profile.Profile {
+		found, err := repo.Search(ctx, viewerProfile, repository.SearchParams{Gender: &gender})
+		require.NoError(t, err)
+		return found
+	}
+
+	// Filtering on a hidden field must not reveal its value.
+	assert.Empty(t, searchByGender())
+
+	_, err := db.Exec(ctx, "INSERT INTO matches (user1_id, user2_id) VALUES (LEAST($1::uuid, $2::uuid), GREATEST($1::uuid, $2::uuid))", viewer.ID, target.ID)
+	require.NoError(t, err)
+
+	found := searchByGender()
+	require.Len(t, found, 1)
+	assert.True(t, found[0].MatchedWithViewer)
+	assert.Equal(t, map[string]string{profile.FieldGender: profile.PrivacyMatches}, found[0].FieldVisibility)
+}
+
+func TestProfileRepository_Search_QueryRespectsFieldPrivacy(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	db := setupTestDB(t)
+	defer db.Close()
+
+	viewer := createTestUser(t, db, "viewer@example.com", "viewer")
+	target := createTestUser(t, db, "target@example.com", "target")
+	repo := repository.NewProfileRepository(db)
+	ctx := context.Background()
+
+	viewerProfile := &profile.Profile{UserID: viewer.ID, FirstName: "Viewer"}
+	require.NoError(t, repo.Create(ctx, viewerProfile))
+	require.NoError(t, repo.Create(ctx, &profile.Profile{
+		UserID:                 target.ID,
+		FirstName:              "Target",
+		Bio:                    "I love hiking",
+		SelfDescribedFlaws:     []string{"Procrastination"},
+		SelfDescribedStrengths: []string{"Juggling"},
+		FieldVisibility: map[string]string{
+			profile.FieldSelfDescribedFlaws:     profile.PrivacyNobody,
+			profile.FieldSelfDescribedStrengths: profile.PrivacyMatches,
+		},
+	}))
+
+	search := func(query string) []*profile.Profile {
+		found, err := repo.Search(ctx, viewerProfile, repository.SearchParams{Query: &query})
+		require.NoError(t, err)
+		return found
+	}
+
+	assert.Len(t, search("hiking"), 1)
+	// Words in hidden fields must not be probed through search.
+	assert.Empty(t, search("procrastination"))
+	assert.Empty(t, search("juggling"))
+
+	_, err := db.Exec(ctx, "INSERT INTO matches (user1_id, user2_id) VALUES (LEAST($1::uuid, $2::uuid), GREATEST($1::uuid, $2::uuid))", viewer.ID, target.ID)
+	require.NoError(t, err)
+	assert.Len(t, search("juggling"), 1)
+	assert.Empty(t, search("procrastination"))
+}
+
+func TestProfileRepository_Search_SnippetEscapesBio(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	db := setupTestDB(t)
+	defer db.Close()
+
+	viewer := createTestUser(t, db, "viewer@example.com", "viewer")
+	target := createTestUser(t, db, "target@example.com", "target")
+	repo := repository.NewProfileRepository(db)
+	ctx := context.Background()
+
+	viewerProfile := &profile.Profile{UserID: viewer.ID, FirstName: "Viewer"}
+	require.NoError(t, repo.Create(ctx, viewerProfile))
+	require.NoError(t, repo.Create(ctx, &profile.Profile{
+		UserID:    target.ID,
+		FirstName: "Target",
+		Bio:       `Jazz & <img src=x onerror="alert(1)"> fan`,
+	}))
+
+	query := "jazz"
+	found, err := repo.Search(ctx, viewerProfile, repository.SearchParams{Query: &query})
+	require.NoError(t, err)
+	require.Len(t, found, 1)
+	require.NotNil(t, found[0].SearchSnippet)
+	snippet := *found[0].SearchSnippet
+	assert.Contains(t, snippet, "<mark>Jazz</mark>")
+	assert.Contains(t, snippet, "&amp;")
+	assert.Contains(t, snippet, "&lt;img")
+	assert.NotContains(t, snippet, "<img")
+}
+
+func TestProfileRepository_Search_Attributes(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	db := setupTestDB(t)
+	defer db.Close()
+
+	viewer := createTestUser(t, db, "viewer@example.com", "viewer")
+	target := createTestUser(t, db, "target@example.com", "target")
+	repo := repository.NewProfileRepository(db)
+	ctx := context.Background()
+
+	viewerProfile := &profile.Profile{UserID: viewer.ID, FirstName: "Viewer"}
+	require.NoError(t, repo.Create(ctx, viewerProfile))
+	goal, kids := "long_term", "want"
+	targetProfile := &profile.Profile{
+		UserID:           target.ID,
+		FirstName:        "Target",
+		Languages:        []string{"en", "pt"},
+		RelationshipGoal: &goal,
+		Kids:             &kids,
+		FieldVisibility:  map[string]string{profile.FieldKids: profile.PrivacyNobody},
+	}
+	require.NoError(t, repo.Create(ctx, targetProfile))
+
+	search := func(params repository.SearchParams) []*profile.Profile {
+		found, err := repo.Search(ctx, viewerProfile, params)
+		require.NoError(t, err)
+		return found
+	}
+
+	found := search(repository.SearchParams{Languages: []string{"pt", "ja"}, RelationshipGoal: &goal})
+	require.Len(t, found, 1)
+	assert.Equal(t, []string{"en", "pt"}, found[0].Languages)
+
+	assert.Empty(t, search(repository.SearchParams{Languages: []string{"ja"}}))
+	// Kids is hidden, so filtering on it must not match.
+	assert.Empty(t, search(repository.SearchParams{Kids: &kids}))
 }
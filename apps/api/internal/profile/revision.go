@@ -0,0 +1,68 @@
+package profile
+
+import "time"
+
+// Snapshot is the displayed content of a profile at one version. Settings
+// such as visibility, location and the locked birth date are not versioned,
+// so restoring a revision never changes them.
+type Snapshot struct {
+	FirstName              string   `json:"firstName"`
+	Bio                    string   `json:"bio"`
+	SelfDescribedFlaws     []string `json:"selfDescribedFlaws"`
+	SelfDescribedStrengths []string `json:"selfDescribedStrengths"`
+	Gender                 *string  `json:"gender"`
+	Height                 *int     `json:"height"`
+	Languages              []string `json:"languages"`
+	Education              *string  `json:"education"`
+	Occupation             *string  `json:"occupation"`
+	RelationshipGoal       *string  `json:"relationshipGoal"`
+	Smoking                *string  `json:"smoking"`
+	Drinking               *string  `json:"drinking"`
+	Kids                   *string  `json:"kids"`
+}
+
+// Revision is the content a profile had at Version, recorded when that
+// version was replaced by an edit.
+type Revision struct {
+	ID        string    `json:"id" db:"id"`
+	UserID    string    `json:"userId" db:"user_id"`
+	Version   int       `json:"version" db:"version"`
+	Snapshot  Snapshot  `json:"snapshot" db:"snapshot"`
+	CreatedAt time.Time `json:"createdAt" db:"created_at"` // When the version was replaced
+}
+
+// Snapshot captures p's versioned content.
+func (p *Profile) Snapshot() Snapshot {
+	return Snapshot{
+		FirstName:              p.FirstName,
+		Bio:                    p.Bio,
+		SelfDescribedFlaws:     p.SelfDescribedFlaws,
+		SelfDescribedStrengths: p.SelfDescribedStrengths,
+		Gender:                 p.Gender,
+		Height:                 p.Height,
+		Languages:              p.Languages,
+		Education:              p.Education,
+		Occupation:             p.Occupation,
+		RelationshipGoal:       p.RelationshipGoal,
+		Smoking:                p.Smoking,
+		Drinking:               p.Drinking,
+		Kids:                   p.Kids,
+	}
+}
+
+// Restore copies s back onto p.
+func (p *Profile) Restore(s Snapshot) {
+	p.FirstName = s.FirstName
+	p.Bio = s.Bio
+	p.SelfDescribedFlaws = s.SelfDescribedFlaws
+	p.SelfDescribedStrengths = s.SelfDescribedStrengths
+	p.Gender = s.Gender
+	p.Height = s.Height
+	p.Languages = s.Languages
+	p.Education = s.Education
+	p.Occupation = s.Occupation
+	p.RelationshipGoal = s.RelationshipGoal
+	p.Smoking = s.Smoking
+	p.Drinking = s.Drinking
+	p.Kids = s.Kids
+}
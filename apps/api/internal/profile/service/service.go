@@ -3,19 +3,72 @@ package service
 import (
 	"context"
 	"errors"
+	"strings"
 	"time"
 
+	"github.com/kisssonik/hearts/internal/notification/service"
 	"github.com/kisssonik/hearts/internal/profile"
 	"github.com/kisssonik/hearts/internal/profile/repository"
+	"github.com/kisssonik/hearts/pkg/optional"
 )
 
-var ErrProfileAlreadyExists = errors.New("profile already exists")
+var (
+	ErrProfileAlreadyExists = errors.New("profile already exists")
+	ErrInvalidVisibility    = errors.New("visibility must be one of: visible, paused, incognito")
+	ErrBirthDateRequired    = errors.New("birth date is required")
+	ErrInvalidBirthDate     = errors.New("birth date is invalid")
+	ErrUnderage             = errors.New("user is below the minimum age")
+	ErrBirthDateLocked      = errors.New("birth date cannot be changed; contact support")
+	ErrInvalidFirstName     = errors.New("first name must be between 1 and 100 characters")
+	ErrInvalidGender        = errors.New("gender must be one of: male, female, other")
+	ErrInvalidHeight        = errors.New("height must be between 100 and 250 cm")
+	ErrInvalidLocation      = errors.New("latitude and longitude must be set or cleared together and be in range")
+	ErrInvalidPassportCity  = errors.New("passport city must be between 1 and 100 characters")
+	ErrInvalidPassportTime  = errors.New("passport expiry must be in the future and at most 30 days away")
+	ErrInvalidFieldPrivacy  = errors.New("field visibility must map private profile fields to everyone, matches or nobody")
+	ErrInvalidLanguages     = errors.New("languages must be at most 10 ISO 639-1 codes")
+	ErrInvalidEducation     = errors.New("education must be one of: high_school, trade_school, bachelors, masters, doctorate")
+	ErrInvalidOccupation    = errors.New("occupation must be at most 100 characters")
+	ErrInvalidRelationship  = errors.New("relationship goal must be one of: long_term, long_term_open, short_term, friendship, not_sure")
+	ErrInvalidSmoking       = errors.New("smoking must be one of: never, socially, regularly")
+	ErrInvalidDrinking      = errors.New("drinking must be one of: never, socially, regularly")
+	ErrInvalidKids          = errors.New("kids must be one of: have, want, dont_want, open")
+)
+
+// DefaultMinAge is used when Config.MinAge is not set.
+const DefaultMinAge = 18
+
+// AnyVersion, passed as the version a write is based on, applies the write
+// to whatever version is current. It is what If-Match: * asks for.
+const AnyVersion = -1
+
+// List page size bounds.
+const (
+	DefaultViewersLimit   = 20
+	MaxViewersLimit       = 100
+	DefaultRevisionsLimit = 20
+	MaxRevisionsLimit     = 100
+)
+
+// Config holds validation rules and features for profiles.
+type Config struct {
+	MinAge      int
+	NotifyViews bool // Notify users of the first view of their profile each day
+}
 
 type ProfileService interface {
 	CreateProfile(ctx context.Context, userID string, input CreateProfileInput) (*profile.Profile, error)
 	GetProfileByUserID(ctx context.Context, userID string) (*profile.Profile, error)
-	UpdateProfile(ctx context.Context, userID string, input UpdateProfileInput) (*profile.Profile, error)
+	GetPublicProfile(ctx context.Context, viewerID, userID string) (*profile.Profile, error)
+	UpdateProfile(ctx context.Context, userID string, version int, input UpdateProfileInput) (*profile.Profile, error)
+	PatchProfile(ctx context.Context, userID string, version int, input PatchProfileInput) (*profile.Profile, error)
+	OverrideBirthDate(ctx context.Context, userID string, birthDate time.Time) (*profile.Profile, error)
 	SearchProfiles(ctx context.Context, userID string, params SearchParams) ([]*profile.Profile, error)
+	SetPassport(ctx context.Context, userID string, input PassportInput) (*profile.Profile, error)
+	ClearPassport(ctx context.Context, userID string) (*profile.Profile, error)
+	ListViewers(ctx context.Context, userID string, limit, offset int) ([]*profile.View, error)
+	ListRevisions(ctx context.Context, userID string, limit, offset int) ([]*profile.Revision, error)
+	RestoreRevision(ctx context.Context, userID string, version, revision int) (*profile.Profile, error)
 }
 
 type SearchParams struct {
@@ -25,17 +78,32 @@ type SearchParams struct {
 	Gender    *string
 	MinHeight *int
 	MaxHeight *int
+	Query     *string
+	Verified  *bool
+	// Languages matches profiles that speak any of the given codes.
+	Languages        []string
+	Education        *string
+	RelationshipGoal *string
+	Smoking          *string
+	Drinking         *string
+	Kids             *string
 }
 
 type CreateProfileInput struct {
 	FirstName              string     `json:"firstName"`
 	Bio                    string     `json:"bio"`
-	Photos                 []string   `json:"photos"`
 	SelfDescribedFlaws     []string   `json:"selfDescribedFlaws"`
 	SelfDescribedStrengths []string   `json:"selfDescribedStrengths"`
 	BirthDate              *time.Time `json:"birthDate"`
 	Gender                 *string    `json:"gender"`
 	Height                 *int       `json:"height"`
+	Languages              []string   `json:"languages"`
+	Education              *string    `json:"education"`
+	Occupation             *string    `json:"occupation"`
+	RelationshipGoal       *string    `json:"relationshipGoal"`
+	Smoking                *string    `json:"smoking"`
+	Drinking               *string    `json:"drinking"`
+	Kids                   *string    `json:"kids"`
 	Latitude               *float64   `json:"latitude"`
 	Longitude              *float64   `json:"longitude"`
 }
@@ -43,25 +111,87 @@ type CreateProfileInput struct {
 type UpdateProfileInput struct {
 	FirstName              *string    `json:"firstName"`
 	Bio                    *string    `json:"bio"`
-	Photos                 []string   `json:"photos"`
 	SelfDescribedFlaws     []string   `json:"selfDescribedFlaws"`
 	SelfDescribedStrengths []string   `json:"selfDescribedStrengths"`
 	BirthDate              *time.Time `json:"birthDate"`
 	Gender                 *string    `json:"gender"`
 	Height                 *int       `json:"height"`
+	Languages              []string   `json:"languages"`
+	Education              *string    `json:"education"`
+	Occupation             *string    `json:"occupation"`
+	RelationshipGoal       *string    `json:"relationshipGoal"`
+	Smoking                *string    `json:"smoking"`
+	Drinking               *string    `json:"drinking"`
+	Kids                   *string    `json:"kids"`
 	Latitude               *float64   `json:"latitude"`
 	Longitude              *float64   `json:"longitude"`
+	Visibility             *string    `json:"visibility"`
+	// FieldVisibility replaces the per-field privacy settings when set.
+	FieldVisibility map[string]string `json:"fieldVisibility"`
+}
+
+// PatchProfileInput is a JSON Merge Patch (RFC 7396) of the editable fields.
+// Absent members are left unchanged; null clears the field where that is
+// allowed.
+type PatchProfileInput struct {
+	FirstName              optional.Field[string]    `json:"firstName" swaggertype:"string"`
+	Bio                    optional.Field[string]    `json:"bio" swaggertype:"string"`
+	SelfDescribedFlaws     optional.Field[[]string]  `json:"selfDescribedFlaws" swaggertype:"array,string"`
+	SelfDescribedStrengths optional.Field[[]string]  `json:"selfDescribedStrengths" swaggertype:"array,string"`
+	BirthDate              optional.Field[time.Time] `json:"birthDate" swaggertype:"string" format:"date-time"`
+	Gender                 optional.Field[string]    `json:"gender" swaggertype:"string"`
+	Height                 optional.Field[int]       `json:"height" swaggertype:"integer"`
+	Languages              optional.Field[[]string]  `json:"languages" swaggertype:"array,string"`
+	Education              optional.Field[string]    `json:"education" swaggertype:"string"`
+	Occupation             optional.Field[string]    `json:"occupation" swaggertype:"string"`
+	RelationshipGoal       optional.Field[string]    `json:"relationshipGoal" swaggertype:"string"`
+	Smoking                optional.Field[string]    `json:"smoking" swaggertype:"string"`
+	Drinking               optional.Field[string]    `json:"drinking" swaggertype:"string"`
+	Kids                   optional.Field[string]    `json:"kids" swaggertype:"string"`
+	Latitude               optional.Field[float64]   `json:"latitude" swaggertype:"number"`
+	Longitude              optional.Field[float64]   `json:"longitude" swaggertype:"number"`
+	Visibility             optional.Field[string]    `json:"visibility" swaggertype:"string"`
+	// FieldVisibility is merged key by key; a null value resets that field
+	// to everyone and a null object resets all of them.
+	FieldVisibility optional.Field[map[string]string] `json:"fieldVisibility" swaggertype:"object"`
+}
+
+// PassportInput sets a temporary travel location.
+type PassportInput struct {
+	City      string     `json:"city"`
+	Latitude  *float64   `json:"latitude"`
+	Longitude *float64   `json:"longitude"`
+	ExpiresAt *time.Time `json:"expiresAt"`
 }
 
 type profileService struct {
-	repo repository.ProfileRepository
+	repo                repository.ProfileRepository
+	notificationService service.NotificationService
+	minAge              int
+	notifyViews         bool
 }
 
-func NewProfileService(repo repository.ProfileRepository) ProfileService {
-	return &profileService{repo: repo}
+func NewProfileService(repo repository.ProfileRepository, notificationService service.NotificationService, cfg Config) ProfileService {
+	minAge := cfg.MinAge
+	if minAge <= 0 {
+		minAge = DefaultMinAge
+	}
+	return &profileService{
+		repo:                repo,
+		notificationService: notificationService,
+		minAge:              minAge,
+		notifyViews:         cfg.NotifyViews && notificationService != nil,
+	}
 }
 
 func (s *profileService) CreateProfile(ctx context.Context, userID string, input CreateProfileInput) (*profile.Profile, error) {
+	if input.BirthDate == nil {
+		return nil, ErrBirthDateRequired
+	}
+	if err := s.validateBirthDate(*input.BirthDate); err != nil {
+		return nil, err
+	}
+
 	// Check if profile already exists
 	_, err := s.repo.GetByUserID(ctx, userID)
 	if err == nil {
@@ -75,31 +205,119 @@ func (s *profileService) CreateProfile(ctx context.Context, userID string, input
 		UserID:                 userID,
 		FirstName:              input.FirstName,
 		Bio:                    input.Bio,
-		Photos:                 input.Photos,
 		SelfDescribedFlaws:     input.SelfDescribedFlaws,
 		SelfDescribedStrengths: input.SelfDescribedStrengths,
 		BirthDate:              input.BirthDate,
 		Gender:                 input.Gender,
 		Height:                 input.Height,
+		Languages:              input.Languages,
+		Education:              input.Education,
+		Occupation:             input.Occupation,
+		RelationshipGoal:       input.RelationshipGoal,
+		Smoking:                input.Smoking,
+		Drinking:               input.Drinking,
+		Kids:                   input.Kids,
 		Latitude:               input.Latitude,
 		Longitude:              input.Longitude,
+		Visibility:             profile.VisibilityVisible,
+	}
+	if err := validateFields(p); err != nil {
+		return nil, err
 	}
+	p.FuzzLocation()
 
 	if err := s.repo.Create(ctx, p); err != nil {
 		return nil, err
 	}
+	fillDerived(p)
 	return p, nil
 }
 
+// GetProfileByUserID returns the owner's view of a profile, including the
+// completeness checklist.
 func (s *profileService) GetProfileByUserID(ctx context.Context, userID string) (*profile.Profile, error) {
-	return s.repo.GetByUserID(ctx, userID)
+	p, err := s.repo.GetByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	fillDerived(p)
+	return p, nil
+}
+
+// GetPublicProfile returns userID's profile as seen by viewerID, with the
+// location reduced to a distance bucket and private fields hidden. Profiles
+// the viewer may not see are reported as repository.ErrNotFound. The visit
+// is recorded unless the viewer is incognito.
+func (s *profileService) GetPublicProfile(ctx context.Context, viewerID, userID string) (*profile.Profile, error) {
+	p, err := s.repo.GetVisibleByUserID(ctx, viewerID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if viewerID == userID {
+		p.ProjectFor(p)
+		return p, nil
+	}
+
+	viewer, err := s.repo.GetByUserID(ctx, viewerID)
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
+		return nil, err
+	}
+	p.ProjectFor(viewer)
+	s.recordView(ctx, viewer, userID)
+	return p, nil
+}
+
+// recordView stores a visit and notifies the owner of the first one each day.
+// Failures are ignored so they never stop the profile from loading.
+func (s *profileService) recordView(ctx context.Context, viewer *profile.Profile, viewedID string) {
+	if viewer == nil || viewer.Visibility == profile.VisibilityIncognito {
+		return
+	}
+	isNew, err := s.repo.RecordView(ctx, viewer.UserID, viewedID)
+	if err != nil || !isNew || !s.notifyViews {
+		return
+	}
+	_ = s.notificationService.NotifyProfileView(ctx, viewedID)
+}
+
+// ListViewers returns who recently viewed userID's profile, most recent
+// first, projected for userID.
+func (s *profileService) ListViewers(ctx context.Context, userID string, limit, offset int) ([]*profile.View, error) {
+	if limit <= 0 {
+		limit = DefaultViewersLimit
+	}
+	limit = min(limit, MaxViewersLimit)
+	offset = max(offset, 0)
+
+	owner, err := s.repo.GetByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	views, err := s.repo.ListViewers(ctx, userID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	for _, v := range views {
+		v.Viewer.ProjectFor(owner)
+	}
+	if views == nil {
+		views = []*profile.View{}
+	}
+	return views, nil
 }
 
-func (s *profileService) UpdateProfile(ctx context.Context, userID string, input UpdateProfileInput) (*profile.Profile, error) {
+// UpdateProfile applies input to the profile if it is still at version, the
+// version the client last read. Otherwise it returns
+// repository.ErrVersionConflict and nothing is written.
+func (s *profileService) UpdateProfile(ctx context.Context, userID string, version int, input UpdateProfileInput) (*profile.Profile, error) {
 	p, err := s.repo.GetByUserID(ctx, userID)
 	if err != nil {
 		return nil, err
 	}
+	if version != AnyVersion && p.Version != version {
+		return nil, repository.ErrVersionConflict
+	}
 
 	if input.FirstName != nil {
 		p.FirstName = *input.FirstName
@@ -107,9 +325,6 @@ func (s *profileService) UpdateProfile(ctx context.Context, userID string, input
 	if input.Bio != nil {
 		p.Bio = *input.Bio
 	}
-	if input.Photos != nil {
-		p.Photos = input.Photos
-	}
 	if input.SelfDescribedFlaws != nil {
 		p.SelfDescribedFlaws = input.SelfDescribedFlaws
 	}
@@ -117,7 +332,9 @@ func (s *profileService) UpdateProfile(ctx context.Context, userID string, input
 		p.SelfDescribedStrengths = input.SelfDescribedStrengths
 	}
 	if input.BirthDate != nil {
-		p.BirthDate = input.BirthDate
+		if err := s.setBirthDate(p, *input.BirthDate); err != nil {
+			return nil, err
+		}
 	}
 	if input.Gender != nil {
 		p.Gender = input.Gender
@@ -125,19 +342,341 @@ func (s *profileService) UpdateProfile(ctx context.Context, userID string, input
 	if input.Height != nil {
 		p.Height = input.Height
 	}
+	if input.Languages != nil {
+		p.Languages = input.Languages
+	}
+	if input.Education != nil {
+		p.Education = input.Education
+	}
+	if input.Occupation != nil {
+		p.Occupation = input.Occupation
+	}
+	if input.RelationshipGoal != nil {
+		p.RelationshipGoal = input.RelationshipGoal
+	}
+	if input.Smoking != nil {
+		p.Smoking = input.Smoking
+	}
+	if input.Drinking != nil {
+		p.Drinking = input.Drinking
+	}
+	if input.Kids != nil {
+		p.Kids = input.Kids
+	}
+	prevLat, prevLon := p.Latitude, p.Longitude
 	if input.Latitude != nil {
 		p.Latitude = input.Latitude
 	}
 	if input.Longitude != nil {
 		p.Longitude = input.Longitude
 	}
+	if err := validateFields(p); err != nil {
+		return nil, err
+	}
+	fuzzIfMoved(p, prevLat, prevLon)
+	if input.Visibility != nil {
+		if !profile.IsValidVisibility(*input.Visibility) {
+			return nil, ErrInvalidVisibility
+		}
+		p.Visibility = *input.Visibility
+	}
+	if input.FieldVisibility != nil {
+		if err := validateFieldPrivacy(input.FieldVisibility); err != nil {
+			return nil, err
+		}
+		p.FieldVisibility = input.FieldVisibility
+	}
+
+	if err := s.repo.Update(ctx, p); err != nil {
+		return nil, err
+	}
+	fillDerived(p)
+	return p, nil
+}
+
+// PatchProfile applies a merge patch to the profile if it is still at
+// version. Each present member is validated on its own; location members
+// are validated together because they only make sense as a pair.
+func (s *profileService) PatchProfile(ctx context.Context, userID string, version int, input PatchProfileInput) (*profile.Profile, error) {
+	p, err := s.repo.GetByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if version != AnyVersion && p.Version != version {
+		return nil, repository.ErrVersionConflict
+	}
+
+	if input.FirstName.Present {
+		p.FirstName = input.FirstName.Value
+	}
+	if input.Bio.Present {
+		p.Bio = input.Bio.Value
+	}
+	if input.SelfDescribedFlaws.Present {
+		p.SelfDescribedFlaws = input.SelfDescribedFlaws.Value
+	}
+	if input.SelfDescribedStrengths.Present {
+		p.SelfDescribedStrengths = input.SelfDescribedStrengths.Value
+	}
+	if input.BirthDate.Present {
+		if input.BirthDate.Null {
+			return nil, ErrBirthDateRequired
+		}
+		if err := s.setBirthDate(p, input.BirthDate.Value); err != nil {
+			return nil, err
+		}
+	}
+	if input.Gender.Present {
+		p.Gender = input.Gender.Ptr()
+	}
+	if input.Height.Present {
+		p.Height = input.Height.Ptr()
+	}
+	if input.Languages.Present {
+		p.Languages = input.Languages.Value
+	}
+	if input.Education.Present {
+		p.Education = input.Education.Ptr()
+	}
+	if input.Occupation.Present {
+		p.Occupation = input.Occupation.Ptr()
+	}
+	if input.RelationshipGoal.Present {
+		p.RelationshipGoal = input.RelationshipGoal.Ptr()
+	}
+	if input.Smoking.Present {
+		p.Smoking = input.Smoking.Ptr()
+	}
+	if input.Drinking.Present {
+		p.Drinking = input.Drinking.Ptr()
+	}
+	if input.Kids.Present {
+		p.Kids = input.Kids.Ptr()
+	}
+	prevLat, prevLon := p.Latitude, p.Longitude
+	if input.Latitude.Present {
+		p.Latitude = input.Latitude.Ptr()
+	}
+	if input.Longitude.Present {
+		p.Longitude = input.Longitude.Ptr()
+	}
+	if err := validateFields(p); err != nil {
+		return nil, err
+	}
+	fuzzIfMoved(p, prevLat, prevLon)
+	if input.Visibility.Present {
+		if input.Visibility.Null || !profile.IsValidVisibility(input.Visibility.Value) {
+			return nil, ErrInvalidVisibility
+		}
+		p.Visibility = input.Visibility.Value
+	}
+	if input.FieldVisibility.Present {
+		if err := mergeFieldPrivacy(p, input.FieldVisibility); err != nil {
+			return nil, err
+		}
+	}
 
 	if err := s.repo.Update(ctx, p); err != nil {
 		return nil, err
 	}
+	fillDerived(p)
 	return p, nil
 }
 
+// fillDerived computes the fields the owner sees but that are not stored, so
+// every path returning the owner's profile gives the same response.
+func fillDerived(p *profile.Profile) {
+	p.ComputeAge(time.Now())
+	p.ComputeCompleteness()
+}
+
+// fuzzIfMoved re-jitters p's fuzzed location only when its real location
+// differs from prevLat/prevLon. Re-rolling on every save would let an
+// observer average the noise away.
+func fuzzIfMoved(p *profile.Profile, prevLat, prevLon *float64) {
+	moved := !sameCoordinate(prevLat, p.Latitude) || !sameCoordinate(prevLon, p.Longitude)
+	if moved || (p.Latitude != nil && p.FuzzedLatitude == nil) {
+		p.FuzzLocation()
+	}
+}
+
+// sameCoordinate reports whether a and b are both unset or equal.
+func sameCoordinate(a, b *float64) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// validateFields normalizes and checks the editable fields of p after an
+// update has been applied, so create, PUT and PATCH share the same rules. A
+// nil optional field is unset and always valid; location coordinates must be
+// set or cleared together.
+func validateFields(p *profile.Profile) error {
+	p.FirstName = strings.TrimSpace(p.FirstName)
+	if p.FirstName == "" || len([]rune(p.FirstName)) > profile.MaxFirstNameLength {
+		return ErrInvalidFirstName
+	}
+	if p.Gender != nil && !profile.IsValidGender(*p.Gender) {
+		return ErrInvalidGender
+	}
+	if p.Height != nil && (*p.Height < profile.MinHeight || *p.Height > profile.MaxHeight) {
+		return ErrInvalidHeight
+	}
+	if (p.Latitude == nil) != (p.Longitude == nil) {
+		return ErrInvalidLocation
+	}
+	if p.Latitude != nil && (*p.Latitude < -90 || *p.Latitude > 90 || *p.Longitude < -180 || *p.Longitude > 180) {
+		return ErrInvalidLocation
+	}
+	return validateAttributes(p)
+}
+
+// validateAttributes normalizes p's languages and occupation and checks the
+// enum attributes. A nil attribute is unset and always valid.
+func validateAttributes(p *profile.Profile) error {
+	if p.Languages != nil {
+		p.Languages = profile.NormalizeLanguages(p.Languages)
+		if len(p.Languages) > profile.MaxLanguages {
+			return ErrInvalidLanguages
+		}
+		for _, code := range p.Languages {
+			if !profile.IsValidLanguage(code) {
+				return ErrInvalidLanguages
+			}
+		}
+	}
+	if p.Occupation != nil {
+		occupation := strings.TrimSpace(*p.Occupation)
+		if len([]rune(occupation)) > profile.MaxOccupationLength {
+			return ErrInvalidOccupation
+		}
+		if occupation == "" {
+			p.Occupation = nil
+		} else {
+			p.Occupation = &occupation
+		}
+	}
+	return validateEnums(p.Education, p.RelationshipGoal, p.Smoking, p.Drinking, p.Kids)
+}
+
+// validateEnums checks the enum attributes shared by profiles and search
+// filters, in that order.
+func validateEnums(education, relationshipGoal, smoking, drinking, kids *string) error {
+	for _, check := range []struct {
+		value *string
+		valid func(string) bool
+		err   error
+	}{
+		{education, profile.IsValidEducation, ErrInvalidEducation},
+		{relationshipGoal, profile.IsValidRelationshipGoal, ErrInvalidRelationship},
+		{smoking, profile.IsValidSmoking, ErrInvalidSmoking},
+		{drinking, profile.IsValidDrinking, ErrInvalidDrinking},
+		{kids, profile.IsValidKids, ErrInvalidKids},
+	} {
+		if check.value != nil && !check.valid(*check.value) {
+			return check.err
+		}
+	}
+	return nil
+}
+
+// validateFieldPrivacy checks a full set of per-field privacy settings.
+func validateFieldPrivacy(settings map[string]string) error {
+	for field, privacy := range settings {
+		if !profile.IsPrivateField(field) || !profile.IsValidPrivacy(privacy) {
+			return ErrInvalidFieldPrivacy
+		}
+	}
+	return nil
+}
+
+// mergeFieldPrivacy applies a merge patch of per-field privacy settings. A
+// null member arrives as an empty string and removes the setting.
+func mergeFieldPrivacy(p *profile.Profile, patch optional.Field[map[string]string]) error {
+	if patch.Null {
+		p.FieldVisibility = nil
+		return nil
+	}
+	merged := make(map[string]string, len(p.FieldVisibility)+len(patch.Value))
+	for field, privacy := range p.FieldVisibility {
+		merged[field] = privacy
+	}
+	for field, privacy := range patch.Value {
+		if !profile.IsPrivateField(field) {
+			return ErrInvalidFieldPrivacy
+		}
+		if privacy == "" {
+			delete(merged, field)
+			continue
+		}
+		if !profile.IsValidPrivacy(privacy) {
+			return ErrInvalidFieldPrivacy
+		}
+		merged[field] = privacy
+	}
+	p.FieldVisibility = merged
+	return nil
+}
+
+// setBirthDate sets a birth date from a user edit. The birth date is locked
+// once set. Profiles created before it was required may set it once;
+// resending the current value is a no-op.
+func (s *profileService) setBirthDate(p *profile.Profile, birthDate time.Time) error {
+	if p.BirthDate != nil {
+		if !sameDate(*p.BirthDate, birthDate) {
+			return ErrBirthDateLocked
+		}
+		return nil
+	}
+	if err := s.validateBirthDate(birthDate); err != nil {
+		return err
+	}
+	p.BirthDate = &birthDate
+	return nil
+}
+
+// OverrideBirthDate changes a locked birth date. It is reserved for support
+// staff and still enforces the minimum age.
+func (s *profileService) OverrideBirthDate(ctx context.Context, userID string, birthDate time.Time) (*profile.Profile, error) {
+	if err := s.validateBirthDate(birthDate); err != nil {
+		return nil, err
+	}
+
+	p, err := s.repo.GetByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	p.BirthDate = &birthDate
+	if err := s.repo.Update(ctx, p); err != nil {
+		return nil, err
+	}
+	fillDerived(p)
+	return p, nil
+}
+
+func (s *profileService) validateBirthDate(birthDate time.Time) error {
+	now := time.Now()
+	if birthDate.After(now) {
+		return ErrInvalidBirthDate
+	}
+	age := profile.AgeAt(birthDate, now)
+	if age > profile.MaxAge {
+		return ErrInvalidBirthDate
+	}
+	if age < s.minAge {
+		return ErrUnderage
+	}
+	return nil
+}
+
+func sameDate(a, b time.Time) bool {
+	ay, am, ad := a.Date()
+	by, bm, bd := b.Date()
+	return ay == by && am == bm && ad == bd
+}
+
 func (s *profileService) SearchProfiles(ctx context.Context, userID string, params SearchParams) ([]*profile.Profile, error) {
 	// Get current user's profile to know their location
 	currentUserProfile, err := s.repo.GetByUserID(ctx, userID)
@@ -146,16 +685,122 @@ func (s *profileService) SearchProfiles(ctx context.Context, userID string, para
 	}
 
 	// If user has no location, they can't search by radius
-	if params.RadiusKM != nil && (currentUserProfile.Latitude == nil || currentUserProfile.Longitude == nil) {
+	if lat, lon := currentUserProfile.SearchLocation(time.Now()); params.RadiusKM != nil && (lat == nil || lon == nil) {
 		return nil, errors.New("user location not set")
 	}
 
-	return s.repo.Search(ctx, currentUserProfile, repository.SearchParams{
-		MinAge:    params.MinAge,
-		MaxAge:    params.MaxAge,
-		RadiusKM:  params.RadiusKM,
-		Gender:    params.Gender,
-		MinHeight: params.MinHeight,
-		MaxHeight: params.MaxHeight,
+	languages := profile.NormalizeLanguages(params.Languages)
+	for _, code := range languages {
+		if !profile.IsValidLanguage(code) {
+			return nil, ErrInvalidLanguages
+		}
+	}
+	if err := validateEnums(params.Education, params.RelationshipGoal, params.Smoking, params.Drinking, params.Kids); err != nil {
+		return nil, err
+	}
+
+	profiles, err := s.repo.Search(ctx, currentUserProfile, repository.SearchParams{
+		MinAge:           params.MinAge,
+		MaxAge:           params.MaxAge,
+		RadiusKM:         params.RadiusKM,
+		Gender:           params.Gender,
+		MinHeight:        params.MinHeight,
+		MaxHeight:        params.MaxHeight,
+		Query:            params.Query,
+		Verified:         params.Verified,
+		Languages:        languages,
+		Education:        params.Education,
+		RelationshipGoal: params.RelationshipGoal,
+		Smoking:          params.Smoking,
+		Drinking:         params.Drinking,
+		Kids:             params.Kids,
 	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, p := range profiles {
+		p.ProjectFor(currentUserProfile)
+	}
+	return profiles, nil
+}
+
+// SetPassport makes searches by userID use the given location until it
+// expires.
+func (s *profileService) SetPassport(ctx context.Context, userID string, input PassportInput) (*profile.Profile, error) {
+	city := strings.TrimSpace(input.City)
+	if city == "" || len([]rune(city)) > profile.MaxPassportCityLength {
+		return nil, ErrInvalidPassportCity
+	}
+	if input.Latitude == nil || input.Longitude == nil ||
+		*input.Latitude < -90 || *input.Latitude > 90 || *input.Longitude < -180 || *input.Longitude > 180 {
+		return nil, ErrInvalidLocation
+	}
+	now := time.Now()
+	if input.ExpiresAt == nil || !input.ExpiresAt.After(now) || input.ExpiresAt.After(now.Add(profile.MaxPassportDuration)) {
+		return nil, ErrInvalidPassportTime
+	}
+
+	passport := &profile.Passport{City: city, Latitude: *input.Latitude, Longitude: *input.Longitude, ExpiresAt: *input.ExpiresAt}
+	if err := s.repo.SetPassport(ctx, userID, passport); err != nil {
+		return nil, err
+	}
+	return s.GetProfileByUserID(ctx, userID)
+}
+
+// ClearPassport ends travel mode early.
+func (s *profileService) ClearPassport(ctx context.Context, userID string) (*profile.Profile, error) {
+	if err := s.repo.SetPassport(ctx, userID, nil); err != nil {
+		return nil, err
+	}
+	return s.GetProfileByUserID(ctx, userID)
+}
+
+// ListRevisions returns the earlier versions of userID's profile, newest
+// first.
+func (s *profileService) ListRevisions(ctx context.Context, userID string, limit, offset int) ([]*profile.Revision, error) {
+	if limit <= 0 {
+		limit = DefaultRevisionsLimit
+	}
+	limit = min(limit, MaxRevisionsLimit)
+	offset = max(offset, 0)
+
+	revisions, err := s.repo.ListRevisions(ctx, userID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	if revisions == nil {
+		revisions = []*profile.Revision{}
+	}
+	return revisions, nil
+}
+
+// RestoreRevision brings back the content the profile had at revision. Like
+// any other edit it only applies if the profile is still at version, and it
+// records the content it replaces so the restore can itself be undone.
+func (s *profileService) RestoreRevision(ctx context.Context, userID string, version, revision int) (*profile.Profile, error) {
+	p, err := s.repo.GetByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if version != AnyVersion && p.Version != version {
+		return nil, repository.ErrVersionConflict
+	}
+
+	rev, err := s.repo.GetRevision(ctx, userID, revision)
+	if err != nil {
+		return nil, err
+	}
+	// Revisions were valid under the rules of their time; the restored
+	// content must also pass today's.
+	p.Restore(rev.Snapshot)
+	if err := validateFields(p); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Update(ctx, p); err != nil {
+		return nil, err
+	}
+	fillDerived(p)
+	return p, nil
 }
@@ -2,8 +2,12 @@ package service_test
 
 import (
 	"context"
+	"encoding/json"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/kisssonik/hearts/internal/notification"
 	"github.com/kisssonik/hearts/internal/profile"
 	"github.com/kisssonik/hearts/internal/profile/repository"
 	"github.com/kisssonik/hearts/internal/profile/service"
@@ -37,20 +41,105 @@ func (m *MockProfileRepository) GetByUserIDs(ctx context.Context, userIDs []stri
 	return args.Get(0).([]*profile.Profile), args.Error(1)
 }
 
+func (m *MockProfileRepository) GetVisibleByUserID(ctx context.Context, viewerID, userID string) (*profile.Profile, error) {
+	args := m.Called(ctx, viewerID, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*profile.Profile), args.Error(1)
+}
+
 func (m *MockProfileRepository) Update(ctx context.Context, p *profile.Profile) error {
 	args := m.Called(ctx, p)
 	return args.Error(0)
 }
 
+func (m *MockProfileRepository) Search(ctx context.Context, currentUser *profile.Profile, params repository.SearchParams) ([]*profile.Profile, error) {
+	args := m.Called(ctx, currentUser, params)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*profile.Profile), args.Error(1)
+}
+
+func (m *MockProfileRepository) SetPassport(ctx context.Context, userID string, passport *profile.Passport) error {
+	args := m.Called(ctx, userID, passport)
+	return args.Error(0)
+}
+
+func (m *MockProfileRepository) ListRevisions(ctx context.Context, userID string, limit, offset int) ([]*profile.Revision, error) {
+	args := m.Called(ctx, userID, limit, offset)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*profile.Revision), args.Error(1)
+}
+
+func (m *MockProfileRepository) GetRevision(ctx context.Context, userID string, version int) (*profile.Revision, error) {
+	args := m.Called(ctx, userID, version)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*profile.Revision), args.Error(1)
+}
+
+func (m *MockProfileRepository) RecordView(ctx context.Context, viewerID, viewedID string) (bool, error) {
+	args := m.Called(ctx, viewerID, viewedID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockProfileRepository) ListViewers(ctx context.Context, userID string, limit, offset int) ([]*profile.View, error) {
+	args := m.Called(ctx, userID, limit, offset)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*profile.View), args.Error(1)
+}
+
+// MockNotificationService is a mock implementation of the notification service
+type MockNotificationService struct {
+	mock.Mock
+}
+
+func (m *MockNotificationService) NotifyMatch(ctx context.Context, user1ID, user2ID string) error {
+	args := m.Called(ctx, user1ID, user2ID)
+	return args.Error(0)
+}
+
+func (m *MockNotificationService) NotifyProfileView(ctx context.Context, userID string) error {
+	args := m.Called(ctx, userID)
+	return args.Error(0)
+}
+
+func (m *MockNotificationService) NotifySuperLike(ctx context.Context, userID string) error {
+	args := m.Called(ctx, userID)
+	return args.Error(0)
+}
+
+func (m *MockNotificationService) GetNotifications(ctx context.Context, userID string) ([]*notification.Notification, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*notification.Notification), args.Error(1)
+}
+
+func (m *MockNotificationService) MarkAsRead(ctx context.Context, notificationID string) error {
+	args := m.Called(ctx, notificationID)
+	return args.Error(0)
+}
+
 func TestCreateProfile_Success(t *testing.T) {
 	mockRepo := new(MockProfileRepository)
-	s := service.NewProfileService(mockRepo)
+	s := service.NewProfileService(mockRepo, nil, service.Config{MinAge: 18})
 	ctx := context.Background()
 	userID := "user-123"
 
+	birthDate := time.Now().AddDate(-30, 0, -1)
 	input := service.CreateProfileInput{
 		FirstName: "John",
 		Bio:       "Hello world",
+		BirthDate: &birthDate,
 	}
 
 	// Expect GetByUserID to return ErrNotFound (profile shouldn't exist)
@@ -66,16 +155,53 @@ func TestCreateProfile_Success(t *testing.T) {
 	assert.NoError(t, err)
 	assert.NotNil(t, p)
 	assert.Equal(t, "John", p.FirstName)
+	if assert.NotNil(t, p.Age) {
+		assert.Equal(t, 30, *p.Age)
+	}
 	mockRepo.AssertExpectations(t)
 }
 
+func TestCreateProfile_BirthDateValidation(t *testing.T) {
+	ctx := context.Background()
+	userID := "user-123"
+
+	underage := time.Now().AddDate(-17, 0, 0)
+	future := time.Now().AddDate(0, 0, 1)
+	ancient := time.Now().AddDate(-150, 0, 0)
+
+	cases := []struct {
+		name      string
+		birthDate *time.Time
+		want      error
+	}{
+		{"Missing", nil, service.ErrBirthDateRequired},
+		{"Underage", &underage, service.ErrUnderage},
+		{"Future", &future, service.ErrInvalidBirthDate},
+		{"Too old", &ancient, service.ErrInvalidBirthDate},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockRepo := new(MockProfileRepository)
+			s := service.NewProfileService(mockRepo, nil, service.Config{MinAge: 18})
+
+			p, err := s.CreateProfile(ctx, userID, service.CreateProfileInput{FirstName: "John", BirthDate: tc.birthDate})
+
+			assert.ErrorIs(t, err, tc.want)
+			assert.Nil(t, p)
+			mockRepo.AssertNotCalled(t, "Create")
+		})
+	}
+}
+
 func TestCreateProfile_AlreadyExists(t *testing.T) {
 	mockRepo := new(MockProfileRepository)
-	s := service.NewProfileService(mockRepo)
+	s := service.NewProfileService(mockRepo, nil, service.Config{MinAge: 18})
 	ctx := context.Background()
 	userID := "user-123"
 
-	input := service.CreateProfileInput{FirstName: "John"}
+	birthDate := time.Now().AddDate(-25, 0, 0)
+	input := service.CreateProfileInput{FirstName: "John", BirthDate: &birthDate}
 
 	// Expect GetByUserID to return an existing profile
 	existingProfile := &profile.Profile{ID: "profile-1", UserID: userID}
@@ -91,7 +217,7 @@ func TestCreateProfile_AlreadyExists(t *testing.T) {
 
 func TestUpdateProfile_Success(t *testing.T) {
 	mockRepo := new(MockProfileRepository)
-	s := service.NewProfileService(mockRepo)
+	s := service.NewProfileService(mockRepo, nil, service.Config{MinAge: 18})
 	ctx := context.Background()
 	userID := "user-123"
 
@@ -113,16 +239,274 @@ func TestUpdateProfile_Success(t *testing.T) {
 		return p.FirstName == "Johnny" && p.Bio == "Old bio"
 	})).Return(nil)
 
-	p, err := s.UpdateProfile(ctx, userID, input)
+	p, err := s.UpdateProfile(ctx, userID, 0, input)
 
 	assert.NoError(t, err)
 	assert.Equal(t, "Johnny", p.FirstName)
 	mockRepo.AssertExpectations(t)
 }
 
+func TestUpdateProfile_StaleVersion(t *testing.T) {
+	mockRepo := new(MockProfileRepository)
+	s := service.NewProfileService(mockRepo, nil, service.Config{MinAge: 18})
+	ctx := context.Background()
+	userID := "user-123"
+
+	mockRepo.On("GetByUserID", ctx, userID).Return(&profile.Profile{UserID: userID, Version: 3}, nil)
+
+	newName := "Johnny"
+	_, err := s.UpdateProfile(ctx, userID, 2, service.UpdateProfileInput{FirstName: &newName})
+
+	assert.ErrorIs(t, err, repository.ErrVersionConflict)
+	mockRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+}
+
+func TestUpdateProfile_AnyVersion(t *testing.T) {
+	mockRepo := new(MockProfileRepository)
+	s := service.NewProfileService(mockRepo, nil, service.Config{MinAge: 18})
+	ctx := context.Background()
+	userID := "user-123"
+
+	mockRepo.On("GetByUserID", ctx, userID).Return(&profile.Profile{UserID: userID, FirstName: "Jane", Version: 7}, nil)
+	mockRepo.On("Update", ctx, mock.Anything).Return(nil)
+
+	newName := "Johnny"
+	p, err := s.UpdateProfile(ctx, userID, service.AnyVersion, service.UpdateProfileInput{FirstName: &newName})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "Johnny", p.FirstName)
+}
+
+func TestUpdateProfile_UnchangedLocationKeepsFuzz(t *testing.T) {
+	mockRepo := new(MockProfileRepository)
+	s := service.NewProfileService(mockRepo, nil, service.Config{MinAge: 18})
+	ctx := context.Background()
+	userID := "user-123"
+
+	lat, lon := 52.52, 13.40
+	fuzzedLat, fuzzedLon := 52.53, 13.41
+	existing := &profile.Profile{
+		UserID: userID, FirstName: "Jane",
+		Latitude: &lat, Longitude: &lon, FuzzedLatitude: &fuzzedLat, FuzzedLongitude: &fuzzedLon,
+	}
+	mockRepo.On("GetByUserID", ctx, userID).Return(existing, nil)
+	mockRepo.On("Update", ctx, mock.Anything).Return(nil)
+
+	sameLat, sameLon := lat, lon
+	p, err := s.UpdateProfile(ctx, userID, 0, service.UpdateProfileInput{Latitude: &sameLat, Longitude: &sameLon})
+	assert.NoError(t, err)
+	assert.Equal(t, fuzzedLat, *p.FuzzedLatitude)
+	assert.Equal(t, fuzzedLon, *p.FuzzedLongitude)
+
+	// Moving re-jitters around the new location.
+	newLat := 48.85
+	p, err = s.UpdateProfile(ctx, userID, 0, service.UpdateProfileInput{Latitude: &newLat, Longitude: &sameLon})
+	assert.NoError(t, err)
+	assert.NotEqual(t, fuzzedLat, *p.FuzzedLatitude)
+	assert.InDelta(t, newLat, *p.FuzzedLatitude, 0.1)
+}
+
+func TestPatchProfile_UnchangedLocationKeepsFuzz(t *testing.T) {
+	mockRepo := new(MockProfileRepository)
+	s := service.NewProfileService(mockRepo, nil, service.Config{MinAge: 18})
+	ctx := context.Background()
+	userID := "user-123"
+
+	lat, lon := 52.52, 13.40
+	fuzzedLat, fuzzedLon := 52.53, 13.41
+	existing := &profile.Profile{
+		UserID: userID, FirstName: "Jane",
+		Latitude: &lat, Longitude: &lon, FuzzedLatitude: &fuzzedLat, FuzzedLongitude: &fuzzedLon,
+	}
+	mockRepo.On("GetByUserID", ctx, userID).Return(existing, nil)
+	mockRepo.On("Update", ctx, mock.Anything).Return(nil)
+
+	p, err := s.PatchProfile(ctx, userID, 0, patchInput(t, `{"latitude": 52.52, "longitude": 13.40}`))
+	assert.NoError(t, err)
+	assert.Equal(t, fuzzedLat, *p.FuzzedLatitude)
+	assert.Equal(t, fuzzedLon, *p.FuzzedLongitude)
+}
+
+func patchInput(t *testing.T, body string) service.PatchProfileInput {
+	var input service.PatchProfileInput
+	if err := json.Unmarshal([]byte(body), &input); err != nil {
+		t.Fatal(err)
+	}
+	return input
+}
+
+func TestPatchProfile_ClearsFields(t *testing.T) {
+	mockRepo := new(MockProfileRepository)
+	s := service.NewProfileService(mockRepo, nil, service.Config{MinAge: 18})
+	ctx := context.Background()
+	userID := "user-123"
+
+	gender, height := "female", 170
+	lat, lon := 52.52, 13.40
+	existing := &profile.Profile{
+		UserID: userID, FirstName: "Jane", Bio: "Hi", Gender: &gender, Height: &height,
+		Latitude: &lat, Longitude: &lon, FuzzedLatitude: &lat, FuzzedLongitude: &lon,
+		Visibility: profile.VisibilityVisible, Version: 2,
+	}
+	mockRepo.On("GetByUserID", ctx, userID).Return(existing, nil)
+	mockRepo.On("Update", ctx, mock.Anything).Return(nil)
+
+	p, err := s.PatchProfile(ctx, userID, 2, patchInput(t, `{"gender": null, "height": null, "latitude": null, "longitude": null}`))
+
+	assert.NoError(t, err)
+	assert.Nil(t, p.Gender)
+	assert.Nil(t, p.Height)
+	assert.Nil(t, p.Latitude)
+	assert.Nil(t, p.FuzzedLatitude)
+	assert.Equal(t, "Jane", p.FirstName)
+	assert.Equal(t, "Hi", p.Bio)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestPatchProfile_Validation(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		wantErr error
+	}{
+		{"null first name", `{"firstName": null}`, service.ErrInvalidFirstName},
+		{"blank first name", `{"firstName": "  "}`, service.ErrInvalidFirstName},
+		{"null birth date", `{"birthDate": null}`, service.ErrBirthDateRequired},
+		{"null visibility", `{"visibility": null}`, service.ErrInvalidVisibility},
+		{"unknown gender", `{"gender": "robot"}`, service.ErrInvalidGender},
+		{"height out of range", `{"height": 20}`, service.ErrInvalidHeight},
+		{"half a location", `{"latitude": 10}`, service.ErrInvalidLocation},
+		{"latitude out of range", `{"latitude": 100, "longitude": 10}`, service.ErrInvalidLocation},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockProfileRepository)
+			s := service.NewProfileService(mockRepo, nil, service.Config{MinAge: 18})
+			ctx := context.Background()
+
+			mockRepo.On("GetByUserID", ctx, "user-123").Return(&profile.Profile{UserID: "user-123", FirstName: "Jane"}, nil)
+
+			_, err := s.PatchProfile(ctx, "user-123", 0, patchInput(t, tt.body))
+
+			assert.ErrorIs(t, err, tt.wantErr)
+			mockRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestUpdateProfile_Validation(t *testing.T) {
+	blank, robot := "  ", "robot"
+	negative := -5
+	lat, lon := 500.0, 10.0
+	tests := []struct {
+		name    string
+		input   service.UpdateProfileInput
+		wantErr error
+	}{
+		{"blank first name", service.UpdateProfileInput{FirstName: &blank}, service.ErrInvalidFirstName},
+		{"unknown gender", service.UpdateProfileInput{Gender: &robot}, service.ErrInvalidGender},
+		{"negative height", service.UpdateProfileInput{Height: &negative}, service.ErrInvalidHeight},
+		{"half a location", service.UpdateProfileInput{Latitude: &lon}, service.ErrInvalidLocation},
+		{"latitude out of range", service.UpdateProfileInput{Latitude: &lat, Longitude: &lon}, service.ErrInvalidLocation},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockProfileRepository)
+			s := service.NewProfileService(mockRepo, nil, service.Config{MinAge: 18})
+			ctx := context.Background()
+
+			mockRepo.On("GetByUserID", ctx, "user-123").Return(&profile.Profile{UserID: "user-123", FirstName: "Jane"}, nil)
+
+			_, err := s.UpdateProfile(ctx, "user-123", 0, tt.input)
+
+			assert.ErrorIs(t, err, tt.wantErr)
+			mockRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestUpdateProfile_Visibility(t *testing.T) {
+	ctx := context.Background()
+	userID := "user-123"
+
+	t.Run("Valid", func(t *testing.T) {
+		mockRepo := new(MockProfileRepository)
+		s := service.NewProfileService(mockRepo, nil, service.Config{MinAge: 18})
+
+		existing := &profile.Profile{UserID: userID, FirstName: "Jane", Visibility: profile.VisibilityVisible}
+		mockRepo.On("GetByUserID", ctx, userID).Return(existing, nil)
+		mockRepo.On("Update", ctx, mock.MatchedBy(func(p *profile.Profile) bool {
+			return p.Visibility == profile.VisibilityIncognito
+		})).Return(nil)
+
+		visibility := profile.VisibilityIncognito
+		p, err := s.UpdateProfile(ctx, userID, 0, service.UpdateProfileInput{Visibility: &visibility})
+
+		assert.NoError(t, err)
+		assert.Equal(t, profile.VisibilityIncognito, p.Visibility)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		mockRepo := new(MockProfileRepository)
+		s := service.NewProfileService(mockRepo, nil, service.Config{MinAge: 18})
+
+		existing := &profile.Profile{UserID: userID, FirstName: "Jane", Visibility: profile.VisibilityVisible}
+		mockRepo.On("GetByUserID", ctx, userID).Return(existing, nil)
+
+		visibility := "invisible"
+		_, err := s.UpdateProfile(ctx, userID, 0, service.UpdateProfileInput{Visibility: &visibility})
+
+		assert.ErrorIs(t, err, service.ErrInvalidVisibility)
+		mockRepo.AssertNotCalled(t, "Update")
+	})
+}
+
+func TestUpdateProfile_BirthDateLocked(t *testing.T) {
+	mockRepo := new(MockProfileRepository)
+	s := service.NewProfileService(mockRepo, nil, service.Config{MinAge: 18})
+	ctx := context.Background()
+	userID := "user-123"
+
+	current := time.Date(1990, 5, 17, 0, 0, 0, 0, time.UTC)
+	existing := &profile.Profile{UserID: userID, FirstName: "Jane", BirthDate: &current}
+	mockRepo.On("GetByUserID", ctx, userID).Return(existing, nil)
+
+	changed := time.Date(1995, 5, 17, 0, 0, 0, 0, time.UTC)
+	_, err := s.UpdateProfile(ctx, userID, 0, service.UpdateProfileInput{BirthDate: &changed})
+
+	assert.ErrorIs(t, err, service.ErrBirthDateLocked)
+	mockRepo.AssertNotCalled(t, "Update")
+}
+
+func TestOverrideBirthDate(t *testing.T) {
+	mockRepo := new(MockProfileRepository)
+	s := service.NewProfileService(mockRepo, nil, service.Config{MinAge: 18})
+	ctx := context.Background()
+	userID := "user-123"
+
+	current := time.Date(1990, 5, 17, 0, 0, 0, 0, time.UTC)
+	existing := &profile.Profile{UserID: userID, FirstName: "Jane", BirthDate: &current}
+	mockRepo.On("GetByUserID", ctx, userID).Return(existing, nil)
+
+	corrected := time.Date(1991, 5, 17, 0, 0, 0, 0, time.UTC)
+	mockRepo.On("Update", ctx, mock.MatchedBy(func(p *profile.Profile) bool {
+		return p.BirthDate.Equal(corrected)
+	})).Return(nil)
+
+	p, err := s.OverrideBirthDate(ctx, userID, corrected)
+
+	assert.NoError(t, err)
+	assert.NotNil(t, p.Age)
+	assert.NotNil(t, p.Completeness)
+	mockRepo.AssertExpectations(t)
+}
+
 func TestUpdateProfile_NotFound(t *testing.T) {
 	mockRepo := new(MockProfileRepository)
-	s := service.NewProfileService(mockRepo)
+	s := service.NewProfileService(mockRepo, nil, service.Config{MinAge: 18})
 	ctx := context.Background()
 	userID := "user-123"
 
@@ -130,9 +514,457 @@ func TestUpdateProfile_NotFound(t *testing.T) {
 
 	mockRepo.On("GetByUserID", ctx, userID).Return(nil, repository.ErrNotFound)
 
-	p, err := s.UpdateProfile(ctx, userID, input)
+	p, err := s.UpdateProfile(ctx, userID, 0, input)
 
 	assert.Error(t, err)
 	assert.Nil(t, p)
 	assert.Equal(t, repository.ErrNotFound, err)
 }
+
+func TestGetProfileByUserID_Completeness(t *testing.T) {
+	mockRepo := new(MockProfileRepository)
+	s := service.NewProfileService(mockRepo, nil, service.Config{MinAge: 18})
+	ctx := context.Background()
+	userID := "user-123"
+
+	mockRepo.On("GetByUserID", ctx, userID).Return(&profile.Profile{UserID: userID, Bio: "Hi", Photos: []string{"a.jpg"}}, nil)
+
+	p, err := s.GetProfileByUserID(ctx, userID)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 50, p.Completeness.Score)
+	assert.Equal(t, []string{profile.ItemPrompts, profile.ItemBirthDate, profile.ItemLocation}, p.Completeness.Missing)
+}
+
+func TestSearchProfiles_PassesQuery(t *testing.T) {
+	mockRepo := new(MockProfileRepository)
+	s := service.NewProfileService(mockRepo, nil, service.Config{MinAge: 18})
+	ctx := context.Background()
+	userID := "user-123"
+
+	current := &profile.Profile{ID: "profile-1", UserID: userID}
+	mockRepo.On("GetByUserID", ctx, userID).Return(current, nil)
+
+	q := "jazz climbing"
+	snippet := "I love <mark>jazz</mark>"
+	rank := 0.6
+	results := []*profile.Profile{{UserID: "user-456", SearchRank: &rank, SearchSnippet: &snippet}}
+	mockRepo.On("Search", ctx, current, mock.MatchedBy(func(p repository.SearchParams) bool {
+		return p.Query != nil && *p.Query == q
+	})).Return(results, nil)
+
+	found, err := s.SearchProfiles(ctx, userID, service.SearchParams{Query: &q})
+
+	assert.NoError(t, err)
+	assert.Len(t, found, 1)
+	assert.Equal(t, snippet, *found[0].SearchSnippet)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestGetPublicProfile(t *testing.T) {
+	mockRepo := new(MockProfileRepository)
+	s := service.NewProfileService(mockRepo, nil, service.Config{MinAge: 18})
+	ctx := context.Background()
+
+	lat, lon := 52.52, 13.40
+	fuzzedLat, fuzzedLon := 52.53, 13.41
+	target := &profile.Profile{UserID: "target", Latitude: &lat, Longitude: &lon, FuzzedLatitude: &fuzzedLat, FuzzedLongitude: &fuzzedLon}
+	viewer := &profile.Profile{UserID: "viewer", Latitude: &lat, Longitude: &lon}
+
+	mockRepo.On("GetVisibleByUserID", ctx, "viewer", "target").Return(target, nil)
+	mockRepo.On("GetByUserID", ctx, "viewer").Return(viewer, nil)
+	mockRepo.On("RecordView", ctx, "viewer", "target").Return(true, nil)
+
+	p, err := s.GetPublicProfile(ctx, "viewer", "target")
+
+	assert.NoError(t, err)
+	assert.Nil(t, p.Latitude)
+	assert.Nil(t, p.Longitude)
+	if assert.NotNil(t, p.DistanceBucket) {
+		assert.Equal(t, "< 5 km", *p.DistanceBucket)
+	}
+}
+
+func TestGetPublicProfile_Hidden(t *testing.T) {
+	mockRepo := new(MockProfileRepository)
+	s := service.NewProfileService(mockRepo, nil, service.Config{MinAge: 18})
+	ctx := context.Background()
+
+	mockRepo.On("GetVisibleByUserID", ctx, "viewer", "target").Return(nil, repository.ErrNotFound)
+
+	p, err := s.GetPublicProfile(ctx, "viewer", "target")
+
+	assert.ErrorIs(t, err, repository.ErrNotFound)
+	assert.Nil(t, p)
+	mockRepo.AssertNotCalled(t, "GetByUserID")
+}
+
+func TestGetPublicProfile_RecordsView(t *testing.T) {
+	tests := []struct {
+		name       string
+		firstToday bool
+		notify     bool
+	}{
+		{name: "first view today notifies", firstToday: true, notify: true},
+		{name: "repeat view is silent", firstToday: false, notify: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockProfileRepository)
+			mockNotifier := new(MockNotificationService)
+			s := service.NewProfileService(mockRepo, mockNotifier, service.Config{MinAge: 18, NotifyViews: true})
+			ctx := context.Background()
+
+			mockRepo.On("GetVisibleByUserID", ctx, "viewer", "target").Return(&profile.Profile{UserID: "target"}, nil)
+			mockRepo.On("GetByUserID", ctx, "viewer").Return(&profile.Profile{UserID: "viewer", Visibility: profile.VisibilityVisible}, nil)
+			mockRepo.On("RecordView", ctx, "viewer", "target").Return(tt.firstToday, nil)
+			if tt.notify {
+				mockNotifier.On("NotifyProfileView", ctx, "target").Return(nil)
+			}
+
+			_, err := s.GetPublicProfile(ctx, "viewer", "target")
+
+			assert.NoError(t, err)
+			mockRepo.AssertExpectations(t)
+			mockNotifier.AssertExpectations(t)
+			if !tt.notify {
+				mockNotifier.AssertNotCalled(t, "NotifyProfileView", mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func TestGetPublicProfile_NotRecorded(t *testing.T) {
+	t.Run("incognito viewer", func(t *testing.T) {
+		mockRepo := new(MockProfileRepository)
+		mockNotifier := new(MockNotificationService)
+		s := service.NewProfileService(mockRepo, mockNotifier, service.Config{MinAge: 18, NotifyViews: true})
+		ctx := context.Background()
+
+		mockRepo.On("GetVisibleByUserID", ctx, "viewer", "target").Return(&profile.Profile{UserID: "target"}, nil)
+		mockRepo.On("GetByUserID", ctx, "viewer").Return(&profile.Profile{UserID: "viewer", Visibility: profile.VisibilityIncognito}, nil)
+
+		_, err := s.GetPublicProfile(ctx, "viewer", "target")
+
+		assert.NoError(t, err)
+		mockRepo.AssertNotCalled(t, "RecordView", mock.Anything, mock.Anything, mock.Anything)
+		mockNotifier.AssertNotCalled(t, "NotifyProfileView", mock.Anything, mock.Anything)
+	})
+
+	t.Run("own profile", func(t *testing.T) {
+		mockRepo := new(MockProfileRepository)
+		s := service.NewProfileService(mockRepo, nil, service.Config{MinAge: 18})
+		ctx := context.Background()
+
+		mockRepo.On("GetVisibleByUserID", ctx, "me", "me").Return(&profile.Profile{UserID: "me"}, nil)
+
+		_, err := s.GetPublicProfile(ctx, "me", "me")
+
+		assert.NoError(t, err)
+		mockRepo.AssertNotCalled(t, "RecordView", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestListViewers(t *testing.T) {
+	mockRepo := new(MockProfileRepository)
+	s := service.NewProfileService(mockRepo, nil, service.Config{MinAge: 18})
+	ctx := context.Background()
+
+	lat, lon := 52.52, 13.40
+	fuzzedLat, fuzzedLon := 52.53, 13.41
+	owner := &profile.Profile{UserID: "me", Latitude: &lat, Longitude: &lon}
+	viewer := &profile.Profile{UserID: "viewer", Latitude: &lat, Longitude: &lon, FuzzedLatitude: &fuzzedLat, FuzzedLongitude: &fuzzedLon}
+	viewedAt := time.Now()
+
+	mockRepo.On("GetByUserID", ctx, "me").Return(owner, nil)
+	// Out of range limits are clamped before reaching the repository.
+	mockRepo.On("ListViewers", ctx, "me", service.MaxViewersLimit, 0).Return([]*profile.View{{Viewer: viewer, ViewedAt: viewedAt}}, nil)
+
+	views, err := s.ListViewers(ctx, "me", 1000, -5)
+
+	assert.NoError(t, err)
+	if assert.Len(t, views, 1) {
+		assert.Equal(t, viewedAt, views[0].ViewedAt)
+		assert.Nil(t, views[0].Viewer.Latitude)
+		if assert.NotNil(t, views[0].Viewer.DistanceBucket) {
+			assert.Equal(t, "< 5 km", *views[0].Viewer.DistanceBucket)
+		}
+	}
+	mockRepo.AssertExpectations(t)
+}
+
+func TestSetPassport_Validation(t *testing.T) {
+	lat, lon := 38.72, -9.14
+	badLat := 91.0
+	soon := time.Now().Add(48 * time.Hour)
+	past := time.Now().Add(-time.Hour)
+	tooFar := time.Now().Add(profile.MaxPassportDuration + time.Hour)
+
+	tests := []struct {
+		name    string
+		input   service.PassportInput
+		wantErr error
+	}{
+		{"valid", service.PassportInput{City: "Lisbon", Latitude: &lat, Longitude: &lon, ExpiresAt: &soon}, nil},
+		{"blank city", service.PassportInput{City: "  ", Latitude: &lat, Longitude: &lon, ExpiresAt: &soon}, service.ErrInvalidPassportCity},
+		{"missing coordinates", service.PassportInput{City: "Lisbon", ExpiresAt: &soon}, service.ErrInvalidLocation},
+		{"latitude out of range", service.PassportInput{City: "Lisbon", Latitude: &badLat, Longitude: &lon, ExpiresAt: &soon}, service.ErrInvalidLocation},
+		{"missing expiry", service.PassportInput{City: "Lisbon", Latitude: &lat, Longitude: &lon}, service.ErrInvalidPassportTime},
+		{"expired", service.PassportInput{City: "Lisbon", Latitude: &lat, Longitude: &lon, ExpiresAt: &past}, service.ErrInvalidPassportTime},
+		{"too long", service.PassportInput{City: "Lisbon", Latitude: &lat, Longitude: &lon, ExpiresAt: &tooFar}, service.ErrInvalidPassportTime},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockProfileRepository)
+			s := service.NewProfileService(mockRepo, nil, service.Config{MinAge: 18})
+			ctx := context.Background()
+
+			if tt.wantErr == nil {
+				mockRepo.On("SetPassport", ctx, "user-123", mock.MatchedBy(func(pp *profile.Passport) bool {
+					return pp.City == "Lisbon" && pp.Latitude == lat && pp.Longitude == lon
+				})).Return(nil)
+				mockRepo.On("GetByUserID", ctx, "user-123").Return(&profile.Profile{UserID: "user-123"}, nil)
+			}
+
+			_, err := s.SetPassport(ctx, "user-123", tt.input)
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				mockRepo.AssertNotCalled(t, "SetPassport", mock.Anything, mock.Anything, mock.Anything)
+				return
+			}
+			assert.NoError(t, err)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestSearchProfiles_RadiusFromPassport(t *testing.T) {
+	mockRepo := new(MockProfileRepository)
+	s := service.NewProfileService(mockRepo, nil, service.Config{MinAge: 18})
+	ctx := context.Background()
+
+	// No home location, but an active passport is enough to search by radius.
+	current := &profile.Profile{UserID: "user-123", Passport: &profile.Passport{
+		City: "Lisbon", Latitude: 38.72, Longitude: -9.14, ExpiresAt: time.Now().Add(time.Hour),
+	}}
+	mockRepo.On("GetByUserID", ctx, "user-123").Return(current, nil)
+	mockRepo.On("Search", ctx, current, mock.Anything).Return([]*profile.Profile{}, nil)
+
+	radius := 25.0
+	_, err := s.SearchProfiles(ctx, "user-123", service.SearchParams{RadiusKM: &radius})
+
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestRestoreRevision(t *testing.T) {
+	oldBio := "Old bio"
+	revision := &profile.Revision{UserID: "user-123", Version: 2, Snapshot: profile.Snapshot{FirstName: "John", Bio: oldBio}}
+	tooTall := 300
+	outdated := &profile.Revision{UserID: "user-123", Version: 2, Snapshot: profile.Snapshot{FirstName: "John", Bio: oldBio, Height: &tooTall}}
+
+	tests := []struct {
+		name     string
+		version  int
+		revision *profile.Revision
+		revErr   error
+		wantErr  error
+	}{
+		{name: "restores content", version: 3, revision: revision},
+		{name: "stale version", version: 2, revision: revision, wantErr: repository.ErrVersionConflict},
+		{name: "unknown revision", version: 3, revErr: repository.ErrRevisionNotFound, wantErr: repository.ErrRevisionNotFound},
+		{name: "fails current rules", version: 3, revision: outdated, wantErr: service.ErrInvalidHeight},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockProfileRepository)
+			s := service.NewProfileService(mockRepo, nil, service.Config{MinAge: 18})
+			ctx := context.Background()
+
+			birthDate := time.Date(1990, 5, 17, 0, 0, 0, 0, time.UTC)
+			current := &profile.Profile{UserID: "user-123", FirstName: "John", Bio: "Removed", BirthDate: &birthDate, Visibility: profile.VisibilityPaused, Version: 3}
+			mockRepo.On("GetByUserID", ctx, "user-123").Return(current, nil)
+			if tt.revErr != nil {
+				mockRepo.On("GetRevision", ctx, "user-123", 2).Return(nil, tt.revErr)
+			} else {
+				mockRepo.On("GetRevision", ctx, "user-123", 2).Return(tt.revision, nil)
+			}
+			mockRepo.On("Update", ctx, mock.MatchedBy(func(p *profile.Profile) bool {
+				return p.Bio == oldBio && p.Visibility == profile.VisibilityPaused
+			})).Return(nil)
+
+			p, err := s.RestoreRevision(ctx, "user-123", tt.version, 2)
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				mockRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, oldBio, p.Bio)
+			assert.NotNil(t, p.Age)
+			assert.NotNil(t, p.Completeness)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestPatchProfile_FieldVisibility(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		want    map[string]string
+		wantErr error
+	}{
+		{
+			name: "merges settings",
+			body: `{"fieldVisibility": {"height": "nobody"}}`,
+			want: map[string]string{"gender": "matches", "height": "nobody"},
+		},
+		{
+			name: "null resets one field",
+			body: `{"fieldVisibility": {"gender": null}}`,
+			want: map[string]string{},
+		},
+		{
+			name: "null resets all fields",
+			body: `{"fieldVisibility": null}`,
+			want: nil,
+		},
+		{
+			name:    "unknown field",
+			body:    `{"fieldVisibility": {"bio": "nobody"}}`,
+			wantErr: service.ErrInvalidFieldPrivacy,
+		},
+		{
+			name:    "unknown level",
+			body:    `{"fieldVisibility": {"height": "friends"}}`,
+			wantErr: service.ErrInvalidFieldPrivacy,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockProfileRepository)
+			s := service.NewProfileService(mockRepo, nil, service.Config{MinAge: 18})
+			ctx := context.Background()
+
+			existing := &profile.Profile{UserID: "user-123", FirstName: "Jane", Version: 1, FieldVisibility: map[string]string{"gender": "matches"}}
+			mockRepo.On("GetByUserID", ctx, "user-123").Return(existing, nil)
+			mockRepo.On("Update", ctx, existing).Return(nil)
+
+			p, err := s.PatchProfile(ctx, "user-123", 1, patchInput(t, tt.body))
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				mockRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, p.FieldVisibility)
+		})
+	}
+}
+
+func TestUpdateProfile_InvalidFieldVisibility(t *testing.T) {
+	mockRepo := new(MockProfileRepository)
+	s := service.NewProfileService(mockRepo, nil, service.Config{MinAge: 18})
+	ctx := context.Background()
+
+	mockRepo.On("GetByUserID", ctx, "user-123").Return(&profile.Profile{UserID: "user-123", FirstName: "Jane", Version: 1}, nil)
+
+	_, err := s.UpdateProfile(ctx, "user-123", 1, service.UpdateProfileInput{
+		FieldVisibility: map[string]string{"height": "friends"},
+	})
+
+	assert.ErrorIs(t, err, service.ErrInvalidFieldPrivacy)
+	mockRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+}
+
+func TestPatchProfile_Attributes(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		check   func(t *testing.T, p *profile.Profile)
+		wantErr error
+	}{
+		{
+			name: "normalizes languages and trims occupation",
+			body: `{"languages": ["EN", "de", "en"], "occupation": "  Nurse ", "smoking": "never", "kids": "open"}`,
+			check: func(t *testing.T, p *profile.Profile) {
+				assert.Equal(t, []string{"en", "de"}, p.Languages)
+				assert.Equal(t, "Nurse", *p.Occupation)
+				assert.Equal(t, "never", *p.Smoking)
+				assert.Equal(t, "open", *p.Kids)
+			},
+		},
+		{
+			name: "null clears",
+			body: `{"education": null, "languages": null}`,
+			check: func(t *testing.T, p *profile.Profile) {
+				assert.Nil(t, p.Education)
+				assert.Nil(t, p.Languages)
+			},
+		},
+		{name: "unknown language", body: `{"languages": ["en", "klingon"]}`, wantErr: service.ErrInvalidLanguages},
+		{name: "too many languages", body: `{"languages": ["en","de","fr","es","it","pt","nl","sv","pl","ru","uk"]}`, wantErr: service.ErrInvalidLanguages},
+		{name: "unknown education", body: `{"education": "phd"}`, wantErr: service.ErrInvalidEducation},
+		{name: "unknown relationship goal", body: `{"relationshipGoal": "marriage"}`, wantErr: service.ErrInvalidRelationship},
+		{name: "unknown drinking habit", body: `{"drinking": "always"}`, wantErr: service.ErrInvalidDrinking},
+		{name: "long occupation", body: `{"occupation": "` + strings.Repeat("x", 101) + `"}`, wantErr: service.ErrInvalidOccupation},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockProfileRepository)
+			s := service.NewProfileService(mockRepo, nil, service.Config{MinAge: 18})
+			ctx := context.Background()
+
+			education := "masters"
+			mockRepo.On("GetByUserID", ctx, "user-123").Return(&profile.Profile{
+				UserID: "user-123", FirstName: "Jane", Version: 1, Education: &education, Languages: []string{"fr"},
+			}, nil)
+			mockRepo.On("Update", ctx, mock.Anything).Return(nil)
+
+			p, err := s.PatchProfile(ctx, "user-123", 1, patchInput(t, tt.body))
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				mockRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+				return
+			}
+			assert.NoError(t, err)
+			tt.check(t, p)
+		})
+	}
+}
+
+func TestSearchProfiles_Attributes(t *testing.T) {
+	mockRepo := new(MockProfileRepository)
+	s := service.NewProfileService(mockRepo, nil, service.Config{MinAge: 18})
+	ctx := context.Background()
+	userID := "user-123"
+
+	current := &profile.Profile{ID: "profile-1", UserID: userID}
+	mockRepo.On("GetByUserID", ctx, userID).Return(current, nil)
+
+	goal := "long_term"
+	mockRepo.On("Search", ctx, current, mock.MatchedBy(func(p repository.SearchParams) bool {
+		return assert.ObjectsAreEqual([]string{"en", "es"}, p.Languages) && p.RelationshipGoal != nil && *p.RelationshipGoal == goal
+	})).Return([]*profile.Profile{}, nil)
+
+	_, err := s.SearchProfiles(ctx, userID, service.SearchParams{Languages: []string{"EN", "es"}, RelationshipGoal: &goal})
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+
+	kids := "maybe"
+	_, err = s.SearchProfiles(ctx, userID, service.SearchParams{Kids: &kids})
+	assert.ErrorIs(t, err, service.ErrInvalidKids)
+
+	_, err = s.SearchProfiles(ctx, userID, service.SearchParams{Languages: []string{"xx"}})
+	assert.ErrorIs(t, err, service.ErrInvalidLanguages)
+}
@@ -47,7 +47,7 @@ func (s *reviewService) CreateReview(ctx context.Context, authorID string, input
 	}
 
 	// Check if users are matched
-	isMatch, err := s.likeRepo.HasMutualLike(ctx, authorID, input.TargetID)
+	isMatch, err := s.likeRepo.IsMatched(ctx, authorID, input.TargetID)
 	if err != nil {
 		return nil, err
 	}
@@ -3,8 +3,10 @@ package service_test
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/kisssonik/hearts/internal/like"
+	likeRepo "github.com/kisssonik/hearts/internal/like/repository"
 	"github.com/kisssonik/hearts/internal/review"
 	"github.com/kisssonik/hearts/internal/review/service"
 	"github.com/stretchr/testify/assert"
@@ -39,14 +41,53 @@ func (m *MockLikeRepository) Upsert(ctx context.Context, l *like.Like) error {
 	return args.Error(0)
 }
 
-func (m *MockLikeRepository) HasMutualLike(ctx context.Context, user1ID, user2ID string) (bool, error) {
+func (m *MockLikeRepository) SuperLike(ctx context.Context, l *like.Like, since time.Time, limit int) (bool, error) {
+	args := m.Called(ctx, l, since, limit)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockLikeRepository) CreateMatch(ctx context.Context, user1ID, user2ID string) (bool, error) {
+	args := m.Called(ctx, user1ID, user2ID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockLikeRepository) IsMatched(ctx context.Context, user1ID, user2ID string) (bool, error) {
 	args := m.Called(ctx, user1ID, user2ID)
 	return args.Bool(0), args.Error(1)
 }
 
-func (m *MockLikeRepository) GetMatches(ctx context.Context, userID string) ([]string, error) {
+func (m *MockLikeRepository) ListMatches(ctx context.Context, userID string, after *likeRepo.MatchCursor, limit int) ([]*like.Match, error) {
+	args := m.Called(ctx, userID, after, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*like.Match), args.Error(1)
+}
+
+func (m *MockLikeRepository) Unmatch(ctx context.Context, userID, otherUserID string) (bool, error) {
+	args := m.Called(ctx, userID, otherUserID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockLikeRepository) ListReceived(ctx context.Context, userID string, limit, offset int) ([]*like.ReceivedLike, error) {
+	args := m.Called(ctx, userID, limit, offset)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*like.ReceivedLike), args.Error(1)
+}
+
+func (m *MockLikeRepository) CountReceived(ctx context.Context, userID string) (int, error) {
 	args := m.Called(ctx, userID)
-	return args.Get(0).([]string), args.Error(1)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockLikeRepository) Rewind(ctx context.Context, userID string, since time.Time) (*like.Like, error) {
+	args := m.Called(ctx, userID, since)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*like.Like), args.Error(1)
 }
 
 func TestReviewService_CreateReview(t *testing.T) {
@@ -59,7 +100,7 @@ func TestReviewService_CreateReview(t *testing.T) {
 	targetID := "u2"
 
 	// Case 1: Success
-	mockLikeRepo.On("HasMutualLike", ctx, authorID, targetID).Return(true, nil)
+	mockLikeRepo.On("IsMatched", ctx, authorID, targetID).Return(true, nil)
 	mockRepo.On("Create", ctx, mock.Anything).Return(nil)
 
 	rev, err := s.CreateReview(ctx, authorID, service.CreateReviewInput{
@@ -71,7 +112,7 @@ func TestReviewService_CreateReview(t *testing.T) {
 	assert.NotNil(t, rev)
 
 	// Case 2: No match
-	mockLikeRepo.On("HasMutualLike", ctx, authorID, "u3").Return(false, nil)
+	mockLikeRepo.On("IsMatched", ctx, authorID, "u3").Return(false, nil)
 
 	_, err = s.CreateReview(ctx, authorID, service.CreateReviewInput{
 		TargetID: "u3",
@@ -0,0 +1,99 @@
+// Package facematch compares a verification selfie against a user's profile
+// photo.
+package facematch
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"math/bits"
+
+	// Register decoders used by image.Decode.
+	_ "image/jpeg"
+	_ "image/png"
+
+	"golang.org/x/image/draw"
+)
+
+// Decisions a Comparer can return. A comparer never rejects on its own;
+// anything it is unsure about goes to a human moderator. Only a comparer that
+// checks liveness, i.e. that the selfie is a new picture showing the
+// requested pose, may approve.
+const (
+	DecisionApprove = "approve"
+	DecisionReview  = "review"
+)
+
+// Result is the outcome of comparing two faces. Duplicate marks a selfie that
+// is a near copy of the reference photo, e.g. the profile photo uploaded
+// again, rather than a new picture; it is never approved automatically.
+type Result struct {
+	Decision  string
+	Score     float64 // Similarity, 0..1
+	Duplicate bool
+}
+
+// Comparer decides whether selfie shows the same person as reference, doing
+// the pose given by its code. reference is nil when the user has no approved
+// photo to compare against.
+type Comparer interface {
+	Compare(ctx context.Context, selfie, reference []byte, pose string) (Result, error)
+}
+
+// DuplicateThreshold is the similarity at or above which the stub comparer
+// flags a selfie as a copy of the reference photo.
+const DuplicateThreshold = 0.85
+
+type stubComparer struct{}
+
+// NewStubComparer returns a deterministic, dependency free comparer for local
+// development. It cannot check the pose, so it never approves: every selfie
+// goes to manual review, with a score from 8x8 average hashes of the two
+// images. Near-identical pictures are flagged as duplicates.
+func NewStubComparer() Comparer {
+	return stubComparer{}
+}
+
+func (stubComparer) Compare(_ context.Context, selfie, reference []byte, _ string) (Result, error) {
+	if reference == nil {
+		return Result{Decision: DecisionReview}, nil
+	}
+
+	a, err := averageHash(selfie)
+	if err != nil {
+		return Result{}, err
+	}
+	b, err := averageHash(reference)
+	if err != nil {
+		return Result{}, err
+	}
+
+	score := 1 - float64(bits.OnesCount64(a^b))/64
+	return Result{Decision: DecisionReview, Score: score, Duplicate: score >= DuplicateThreshold}, nil
+}
+
+// averageHash returns a 64-bit perceptual hash: each bit is set when the
+// corresponding cell of an 8x8 grayscale thumbnail is brighter than the mean.
+func averageHash(data []byte) (uint64, error) {
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return 0, err
+	}
+
+	thumb := image.NewGray(image.Rect(0, 0, 8, 8))
+	draw.ApproxBiLinear.Scale(thumb, thumb.Bounds(), src, src.Bounds(), draw.Src, nil)
+
+	var sum int
+	for _, px := range thumb.Pix {
+		sum += int(px)
+	}
+	mean := sum / len(thumb.Pix)
+
+	var hash uint64
+	for i, px := range thumb.Pix {
+		if int(px) > mean {
+			hash |= 1 << uint(i)
+		}
+	}
+	return hash, nil
+}
@@ -0,0 +1,56 @@
+package facematch_test
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"testing"
+
+	"github.com/kisssonik/hearts/internal/verification/facematch"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// halfJPEG draws an image whose left or right half is white.
+func halfJPEG(t *testing.T, leftWhite bool) []byte {
+	img := image.NewRGBA(image.Rect(0, 0, 64, 64))
+	for y := 0; y < 64; y++ {
+		for x := 0; x < 64; x++ {
+			if (x < 32) == leftWhite {
+				img.Set(x, y, color.White)
+			} else {
+				img.Set(x, y, color.Black)
+			}
+		}
+	}
+	var buf bytes.Buffer
+	require.NoError(t, jpeg.Encode(&buf, img, nil))
+	return buf.Bytes()
+}
+
+func TestStubComparer(t *testing.T) {
+	c := facematch.NewStubComparer()
+	ctx := context.Background()
+
+	// A copy of the reference photo is flagged, not approved.
+	res, err := c.Compare(ctx, halfJPEG(t, true), halfJPEG(t, true), "thumbs_up")
+	require.NoError(t, err)
+	assert.Equal(t, facematch.DecisionReview, res.Decision)
+	assert.Equal(t, 1.0, res.Score)
+	assert.True(t, res.Duplicate)
+
+	res, err = c.Compare(ctx, halfJPEG(t, true), halfJPEG(t, false), "thumbs_up")
+	require.NoError(t, err)
+	assert.Equal(t, facematch.DecisionReview, res.Decision)
+	assert.Less(t, res.Score, facematch.DuplicateThreshold)
+	assert.False(t, res.Duplicate)
+
+	res, err = c.Compare(ctx, halfJPEG(t, true), nil, "thumbs_up")
+	require.NoError(t, err)
+	assert.Equal(t, facematch.DecisionReview, res.Decision)
+
+	_, err = c.Compare(ctx, []byte("nope"), halfJPEG(t, true), "thumbs_up")
+	assert.Error(t, err)
+}
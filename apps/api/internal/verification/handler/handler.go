@@ -0,0 +1,285 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/kisssonik/hearts/internal/verification"
+	"github.com/kisssonik/hearts/internal/verification/repository"
+	"github.com/kisssonik/hearts/internal/verification/service"
+	"github.com/kisssonik/hearts/pkg/auth"
+	"go.uber.org/zap"
+)
+
+// RejectInput is the body accepted by Reject.
+type RejectInput struct {
+	Reason string `json:"reason"`
+}
+
+// maxRequestBytes bounds the whole multipart request body.
+const maxRequestBytes = 16 << 20
+
+type VerificationHandler struct {
+	service service.VerificationService
+	logger  *zap.Logger
+}
+
+func NewVerificationHandler(s service.VerificationService, l *zap.Logger) *VerificationHandler {
+	return &VerificationHandler{service: s, logger: l}
+}
+
+// StartChallenge handles requesting a verification pose.
+// @Summary Start verification
+// @Description Issue a random pose the user must copy in a selfie. The challenge expires after 10 minutes.
+// @Tags verification
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 201 {object} verification.Verification
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 409 {string} string "Already verified or awaiting review"
+// @Failure 500 {string} string "Internal server error"
+// @Router /verification/challenge [post]
+func (h *VerificationHandler) StartChallenge(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(auth.UserIDKey).(string)
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	v, err := h.service.StartChallenge(r.Context(), userID)
+	if err != nil {
+		if errors.Is(err, service.ErrAlreadyVerified) || errors.Is(err, service.ErrReviewPending) {
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		}
+		h.logger.Error("Failed to start verification", zap.Error(err))
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(v)
+}
+
+// SubmitSelfie handles uploading the selfie for the open challenge.
+// @Summary Submit verification selfie
+// @Description Upload a selfie copying the issued pose. It is compared with the primary photo;
+// @Description the profile is verified immediately on a confident match, otherwise the attempt waits for a moderator.
+// @Tags verification
+// @Accept multipart/form-data
+// @Produce json
+// @Security ApiKeyAuth
+// @Param selfie formData file true "Selfie"
+// @Success 200 {object} verification.Verification
+// @Failure 400 {string} string "Invalid file"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 409 {string} string "No open challenge"
+// @Failure 410 {string} string "Challenge expired"
+// @Failure 413 {string} string "File too large"
+// @Failure 415 {string} string "Unsupported image type"
+// @Failure 500 {string} string "Internal server error"
+// @Router /verification/selfie [post]
+func (h *VerificationHandler) SubmitSelfie(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(auth.UserIDKey).(string)
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxRequestBytes)
+	if err := r.ParseMultipartForm(10 << 20); err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			http.Error(w, service.ErrSelfieTooLarge.Error(), http.StatusRequestEntityTooLarge)
+			return
+		}
+		h.logger.Warn("Failed to parse multipart form", zap.Error(err))
+		http.Error(w, "Invalid multipart form", http.StatusBadRequest)
+		return
+	}
+
+	file, _, err := r.FormFile("selfie")
+	if err != nil {
+		http.Error(w, "Invalid file", http.StatusBadRequest)
+		return
+	}
+	defer file.Close()
+
+	v, err := h.service.SubmitSelfie(r.Context(), userID, file)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrNoChallenge):
+			http.Error(w, err.Error(), http.StatusConflict)
+		case errors.Is(err, service.ErrChallengeExpired):
+			http.Error(w, err.Error(), http.StatusGone)
+		case errors.Is(err, service.ErrSelfieTooLarge):
+			http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+		case errors.Is(err, service.ErrInvalidSelfie):
+			http.Error(w, err.Error(), http.StatusUnsupportedMediaType)
+		default:
+			h.logger.Error("Failed to submit selfie", zap.Error(err))
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
+
+// GetStatus handles reading the latest verification attempt.
+// @Summary Get verification status
+// @Description Get the authenticated user's latest verification attempt, including the rejection reason if any.
+// @Tags verification
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} verification.Verification
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 404 {string} string "No verification attempt"
+// @Failure 500 {string} string "Internal server error"
+// @Router /verification [get]
+func (h *VerificationHandler) GetStatus(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(auth.UserIDKey).(string)
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	v, err := h.service.GetStatus(r.Context(), userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			http.Error(w, "Verification not found", http.StatusNotFound)
+			return
+		}
+		h.logger.Error("Failed to get verification", zap.Error(err))
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
+
+// ReviewQueue lists selfies awaiting a moderator.
+// @Summary Verification review queue
+// @Description Moderator-only list of submitted selfies the face comparison could not approve, oldest first.
+// @Tags admin
+// @Produce json
+// @Security ApiKeyAuth
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Param offset query int false "Number of attempts to skip"
+// @Success 200 {array} verification.Verification
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Forbidden"
+// @Failure 500 {string} string "Internal server error"
+// @Router /admin/verifications [get]
+func (h *VerificationHandler) ReviewQueue(w http.ResponseWriter, r *http.Request) {
+	var limit, offset int
+	query := r.URL.Query()
+	if limitStr := query.Get("limit"); limitStr != "" {
+		fmt.Sscanf(limitStr, "%d", &limit)
+	}
+	if offsetStr := query.Get("offset"); offsetStr != "" {
+		fmt.Sscanf(offsetStr, "%d", &offset)
+	}
+
+	vs, err := h.service.ListPendingReview(r.Context(), limit, offset)
+	if err != nil {
+		h.logger.Error("Failed to list verification queue", zap.Error(err))
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	if vs == nil {
+		vs = []*verification.Verification{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(vs)
+}
+
+// Approve marks a submitted attempt as approved.
+// @Summary Approve a verification
+// @Description Moderator-only. Approves a submitted selfie and marks the profile as verified.
+// @Tags admin
+// @Produce json
+// @Security ApiKeyAuth
+// @Param verificationID path string true "Verification ID"
+// @Success 200 {object} verification.Verification
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Forbidden"
+// @Failure 404 {string} string "Verification not found"
+// @Failure 500 {string} string "Internal server error"
+// @Router /admin/verifications/{verificationID}/approve [post]
+func (h *VerificationHandler) Approve(w http.ResponseWriter, r *http.Request) {
+	reviewerID, ok := r.Context().Value(auth.UserIDKey).(string)
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	v, err := h.service.Approve(r.Context(), reviewerID, r.PathValue("verificationID"))
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			http.Error(w, "Verification not found", http.StatusNotFound)
+			return
+		}
+		h.logger.Error("Failed to approve verification", zap.Error(err))
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
+
+// Reject marks a submitted attempt as rejected.
+// @Summary Reject a verification
+// @Description Moderator-only. Rejects a submitted selfie; the user sees the reason and may try again.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param verificationID path string true "Verification ID"
+// @Param input body RejectInput true "Rejection reason"
+// @Success 200 {object} verification.Verification
+// @Failure 400 {string} string "Reason required"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Forbidden"
+// @Failure 404 {string} string "Verification not found"
+// @Failure 500 {string} string "Internal server error"
+// @Router /admin/verifications/{verificationID}/reject [post]
+func (h *VerificationHandler) Reject(w http.ResponseWriter, r *http.Request) {
+	reviewerID, ok := r.Context().Value(auth.UserIDKey).(string)
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var input RejectInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	v, err := h.service.Reject(r.Context(), reviewerID, r.PathValue("verificationID"), strings.TrimSpace(input.Reason))
+	if err != nil {
+		if errors.Is(err, service.ErrReasonRequired) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if errors.Is(err, repository.ErrNotFound) {
+			http.Error(w, "Verification not found", http.StatusNotFound)
+			return
+		}
+		h.logger.Error("Failed to reject verification", zap.Error(err))
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,232 @@
+package handler_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/kisssonik/hearts/internal/verification"
+	"github.com/kisssonik/hearts/internal/verification/handler"
+	"github.com/kisssonik/hearts/internal/verification/repository"
+	"github.com/kisssonik/hearts/internal/verification/service"
+	"github.com/kisssonik/hearts/pkg/auth"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+)
+
+// MockVerificationService
+type MockVerificationService struct {
+	mock.Mock
+}
+
+func (m *MockVerificationService) StartChallenge(ctx context.Context, userID string) (*verification.Verification, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*verification.Verification), args.Error(1)
+}
+
+func (m *MockVerificationService) SubmitSelfie(ctx context.Context, userID string, file io.Reader) (*verification.Verification, error) {
+	args := m.Called(ctx, userID, file)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*verification.Verification), args.Error(1)
+}
+
+func (m *MockVerificationService) GetStatus(ctx context.Context, userID string) (*verification.Verification, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*verification.Verification), args.Error(1)
+}
+
+func (m *MockVerificationService) ListPendingReview(ctx context.Context, limit, offset int) ([]*verification.Verification, error) {
+	args := m.Called(ctx, limit, offset)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*verification.Verification), args.Error(1)
+}
+
+func (m *MockVerificationService) Approve(ctx context.Context, reviewerID, id string) (*verification.Verification, error) {
+	args := m.Called(ctx, reviewerID, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*verification.Verification), args.Error(1)
+}
+
+func (m *MockVerificationService) Reject(ctx context.Context, reviewerID, id, reason string) (*verification.Verification, error) {
+	args := m.Called(ctx, reviewerID, id, reason)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*verification.Verification), args.Error(1)
+}
+
+func withUser(r *http.Request, userID string) *http.Request {
+	return r.WithContext(context.WithValue(r.Context(), auth.UserIDKey, userID))
+}
+
+func selfieRequest() *http.Request {
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	part, _ := mw.CreateFormFile("selfie", "me.jpg")
+	part.Write([]byte("data"))
+	mw.Close()
+
+	req := withUser(httptest.NewRequest("POST", "/verification/selfie", &body), "user1")
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	return req
+}
+
+func TestVerificationHandler_StartChallenge(t *testing.T) {
+	mockService := new(MockVerificationService)
+	h := handler.NewVerificationHandler(mockService, zap.NewNop())
+
+	req := withUser(httptest.NewRequest("POST", "/verification/challenge", nil), "user1")
+	w := httptest.NewRecorder()
+
+	mockService.On("StartChallenge", mock.Anything, "user1").
+		Return(&verification.Verification{ID: "v1", Pose: verification.Poses[0], Status: verification.StatusChallenged}, nil)
+
+	h.StartChallenge(w, req)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var resp map[string]interface{}
+	json.NewDecoder(w.Body).Decode(&resp)
+	assert.Equal(t, "v1", resp["id"])
+	assert.Equal(t, verification.Poses[0].Instructions, resp["pose"].(map[string]interface{})["instructions"])
+}
+
+func TestVerificationHandler_StartChallenge_Conflict(t *testing.T) {
+	mockService := new(MockVerificationService)
+	h := handler.NewVerificationHandler(mockService, zap.NewNop())
+
+	req := withUser(httptest.NewRequest("POST", "/verification/challenge", nil), "user1")
+	w := httptest.NewRecorder()
+
+	mockService.On("StartChallenge", mock.Anything, "user1").Return(nil, service.ErrAlreadyVerified)
+
+	h.StartChallenge(w, req)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+}
+
+func TestVerificationHandler_SubmitSelfie(t *testing.T) {
+	mockService := new(MockVerificationService)
+	h := handler.NewVerificationHandler(mockService, zap.NewNop())
+
+	key := "verifications/user1/v1.jpg"
+	mockService.On("SubmitSelfie", mock.Anything, "user1", mock.Anything).
+		Return(&verification.Verification{ID: "v1", Status: verification.StatusSubmitted, SelfieKey: &key}, nil)
+
+	w := httptest.NewRecorder()
+	h.SubmitSelfie(w, selfieRequest())
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var resp map[string]interface{}
+	json.NewDecoder(w.Body).Decode(&resp)
+	assert.Equal(t, verification.StatusSubmitted, resp["status"])
+	assert.NotContains(t, w.Body.String(), key)
+}
+
+func TestVerificationHandler_SubmitSelfie_Errors(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+	}{
+		{"no challenge", service.ErrNoChallenge, http.StatusConflict},
+		{"expired", service.ErrChallengeExpired, http.StatusGone},
+		{"invalid", service.ErrInvalidSelfie, http.StatusUnsupportedMediaType},
+		{"too large", service.ErrSelfieTooLarge, http.StatusRequestEntityTooLarge},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockVerificationService)
+			h := handler.NewVerificationHandler(mockService, zap.NewNop())
+
+			mockService.On("SubmitSelfie", mock.Anything, "user1", mock.Anything).Return(nil, tt.err)
+
+			w := httptest.NewRecorder()
+			h.SubmitSelfie(w, selfieRequest())
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+		})
+	}
+}
+
+func TestVerificationHandler_GetStatus_NotFound(t *testing.T) {
+	mockService := new(MockVerificationService)
+	h := handler.NewVerificationHandler(mockService, zap.NewNop())
+
+	req := withUser(httptest.NewRequest("GET", "/verification", nil), "user1")
+	w := httptest.NewRecorder()
+
+	mockService.On("GetStatus", mock.Anything, "user1").Return(nil, repository.ErrNotFound)
+
+	h.GetStatus(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestVerificationHandler_ReviewQueue(t *testing.T) {
+	mockService := new(MockVerificationService)
+	h := handler.NewVerificationHandler(mockService, zap.NewNop())
+
+	req := withUser(httptest.NewRequest("GET", "/admin/verifications?limit=5&offset=10", nil), "admin1")
+	w := httptest.NewRecorder()
+
+	mockService.On("ListPendingReview", mock.Anything, 5, 10).
+		Return([]*verification.Verification{{ID: "v1", SelfieURL: "http://selfie"}}, nil)
+
+	h.ReviewQueue(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "http://selfie")
+	mockService.AssertExpectations(t)
+}
+
+func TestVerificationHandler_Reject(t *testing.T) {
+	mockService := new(MockVerificationService)
+	h := handler.NewVerificationHandler(mockService, zap.NewNop())
+
+	req := withUser(httptest.NewRequest("POST", "/admin/verifications/v1/reject", strings.NewReader(`{"reason":" blurry "}`)), "admin1")
+	req.SetPathValue("verificationID", "v1")
+	w := httptest.NewRecorder()
+
+	mockService.On("Reject", mock.Anything, "admin1", "v1", "blurry").
+		Return(&verification.Verification{ID: "v1", Status: verification.StatusRejected}, nil)
+
+	h.Reject(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestVerificationHandler_Approve_NotFound(t *testing.T) {
+	mockService := new(MockVerificationService)
+	h := handler.NewVerificationHandler(mockService, zap.NewNop())
+
+	req := withUser(httptest.NewRequest("POST", "/admin/verifications/v1/approve", nil), "admin1")
+	req.SetPathValue("verificationID", "v1")
+	w := httptest.NewRecorder()
+
+	mockService.On("Approve", mock.Anything, "admin1", "v1").Return(nil, repository.ErrNotFound)
+
+	h.Approve(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
@@ -0,0 +1,145 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/kisssonik/hearts/internal/verification"
+)
+
+var ErrNotFound = errors.New("verification not found")
+
+type VerificationRepository interface {
+	Create(ctx context.Context, v *verification.Verification) error
+	GetLatestByUserID(ctx context.Context, userID string) (*verification.Verification, error)
+	MarkSubmitted(ctx context.Context, id, selfieKey string, score *float64, duplicate bool) error
+	Decide(ctx context.Context, id, status string, reason, reviewerID *string) (*verification.Verification, error)
+	ListSubmitted(ctx context.Context, limit, offset int) ([]*verification.Verification, error)
+}
+
+type pgxVerificationRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewVerificationRepository(db *pgxpool.Pool) VerificationRepository {
+	return &pgxVerificationRepository{db: db}
+}
+
+const verificationColumns = `id, user_id, pose, status, selfie_key, score, duplicate, reason, reviewed_by, expires_at, created_at, updated_at`
+
+func scanVerification(row pgx.Row, v *verification.Verification) error {
+	var pose string
+	if err := row.Scan(&v.ID, &v.UserID, &pose, &v.Status, &v.SelfieKey, &v.Score, &v.Duplicate, &v.Reason, &v.ReviewedBy, &v.ExpiresAt, &v.CreatedAt, &v.UpdatedAt); err != nil {
+		return err
+	}
+	v.Pose, _ = verification.PoseByCode(pose)
+	v.Pose.Code = pose
+	return nil
+}
+
+func (r *pgxVerificationRepository) Create(ctx context.Context, v *verification.Verification) error {
+	query := `
+		INSERT INTO verifications (user_id, pose, status, expires_at)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, created_at, updated_at
+	`
+	return r.db.QueryRow(ctx, query, v.UserID, v.Pose.Code, v.Status, v.ExpiresAt).Scan(&v.ID, &v.CreatedAt, &v.UpdatedAt)
+}
+
+func (r *pgxVerificationRepository) GetLatestByUserID(ctx context.Context, userID string) (*verification.Verification, error) {
+	query := `
+		SELECT ` + verificationColumns + `
+		FROM verifications
+		WHERE user_id = $1
+		ORDER BY created_at DESC
+		LIMIT 1
+	`
+	v := &verification.Verification{}
+	if err := scanVerification(r.db.QueryRow(ctx, query, userID), v); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return v, nil
+}
+
+// MarkSubmitted attaches a selfie, and the automated comparison score and
+// duplicate flag if any, to an open challenge.
+func (r *pgxVerificationRepository) MarkSubmitted(ctx context.Context, id, selfieKey string, score *float64, duplicate bool) error {
+	query := `
+		UPDATE verifications
+		SET status = 'submitted', selfie_key = $1, score = $2, duplicate = $3, updated_at = NOW()
+		WHERE id = $4 AND status = 'challenged'
+	`
+	tag, err := r.db.Exec(ctx, query, selfieKey, score, duplicate, id)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// Decide records the outcome of a submitted attempt. Approving also marks
+// the user's profile as verified, in the same transaction.
+func (r *pgxVerificationRepository) Decide(ctx context.Context, id, status string, reason, reviewerID *string) (*verification.Verification, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+		UPDATE verifications
+		SET status = $1, reason = $2, reviewed_by = $3, updated_at = NOW()
+		WHERE id = $4 AND status = 'submitted'
+		RETURNING ` + verificationColumns
+	v := &verification.Verification{}
+	if err := scanVerification(tx.QueryRow(ctx, query, status, reason, reviewerID, id), v); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	if status == verification.StatusApproved {
+		if _, err := tx.Exec(ctx, `UPDATE profiles SET is_verified = TRUE WHERE user_id = $1`, v.UserID); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// ListSubmitted returns attempts awaiting a human decision, oldest first.
+func (r *pgxVerificationRepository) ListSubmitted(ctx context.Context, limit, offset int) ([]*verification.Verification, error) {
+	query := `
+		SELECT ` + verificationColumns + `
+		FROM verifications
+		WHERE status = 'submitted'
+		ORDER BY updated_at ASC
+		LIMIT $1 OFFSET $2
+	`
+	rows, err := r.db.Query(ctx, query, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var vs []*verification.Verification
+	for rows.Next() {
+		v := &verification.Verification{}
+		if err := scanVerification(rows, v); err != nil {
+			return nil, err
+		}
+		vs = append(vs, v)
+	}
+	return vs, rows.Err()
+}
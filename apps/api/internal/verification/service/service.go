@@ -0,0 +1,265 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"time"
+
+	"github.com/kisssonik/hearts/internal/photo"
+	photoRepo "github.com/kisssonik/hearts/internal/photo/repository"
+	"github.com/kisssonik/hearts/internal/verification"
+	"github.com/kisssonik/hearts/internal/verification/facematch"
+	"github.com/kisssonik/hearts/internal/verification/repository"
+	"github.com/kisssonik/hearts/pkg/imaging"
+	"github.com/kisssonik/hearts/pkg/storage"
+)
+
+var (
+	ErrAlreadyVerified  = errors.New("profile is already verified")
+	ErrReviewPending    = errors.New("a selfie is already awaiting review")
+	ErrNoChallenge      = errors.New("no open verification challenge; request a new pose first")
+	ErrChallengeExpired = errors.New("verification challenge expired; request a new pose")
+	ErrInvalidSelfie    = errors.New("selfie must be a JPEG, PNG or WebP image")
+	ErrSelfieTooLarge   = errors.New("selfie is too large")
+	ErrReasonRequired   = errors.New("a reason is required to reject a verification")
+)
+
+const (
+	// ChallengeTTL is how long a user has to submit a selfie for a pose.
+	ChallengeTTL = 10 * time.Minute
+	// MaxSelfieBytes bounds the accepted upload size.
+	MaxSelfieBytes = 10 << 20
+
+	DefaultQueueLimit = 20
+	MaxQueueLimit     = 100
+)
+
+type VerificationService interface {
+	StartChallenge(ctx context.Context, userID string) (*verification.Verification, error)
+	SubmitSelfie(ctx context.Context, userID string, file io.Reader) (*verification.Verification, error)
+	GetStatus(ctx context.Context, userID string) (*verification.Verification, error)
+	ListPendingReview(ctx context.Context, limit, offset int) ([]*verification.Verification, error)
+	Approve(ctx context.Context, reviewerID, id string) (*verification.Verification, error)
+	Reject(ctx context.Context, reviewerID, id, reason string) (*verification.Verification, error)
+}
+
+type verificationService struct {
+	repo      repository.VerificationRepository
+	photoRepo photoRepo.PhotoRepository
+	storage   storage.Provider
+	comparer  facematch.Comparer
+	processor *imaging.Processor
+	slots     imaging.Slots
+}
+
+// NewVerificationService returns a service that decodes selfies in slots,
+// which should be the ones photo uploads use. A nil slots processes one
+// selfie at a time.
+func NewVerificationService(repo repository.VerificationRepository, photoRepo photoRepo.PhotoRepository, storage storage.Provider, comparer facematch.Comparer, slots imaging.Slots) VerificationService {
+	if slots == nil {
+		slots = imaging.NewSlots(1)
+	}
+	return &verificationService{
+		repo:      repo,
+		photoRepo: photoRepo,
+		storage:   storage,
+		comparer:  comparer,
+		processor: imaging.NewProcessor([]imaging.Variant{{Name: "selfie", MaxSide: 1024}}, 85, 0),
+		slots:     slots,
+	}
+}
+
+// StartChallenge issues a new random pose. Any earlier open challenge is
+// superseded because only the latest attempt is considered.
+func (s *verificationService) StartChallenge(ctx context.Context, userID string) (*verification.Verification, error) {
+	latest, err := s.repo.GetLatestByUserID(ctx, userID)
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
+		return nil, err
+	}
+	if latest != nil {
+		switch latest.Status {
+		case verification.StatusApproved:
+			return nil, ErrAlreadyVerified
+		case verification.StatusSubmitted:
+			return nil, ErrReviewPending
+		}
+	}
+
+	v := &verification.Verification{
+		UserID:    userID,
+		Pose:      verification.RandomPose(),
+		Status:    verification.StatusChallenged,
+		ExpiresAt: time.Now().Add(ChallengeTTL),
+	}
+	if err := s.repo.Create(ctx, v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// SubmitSelfie stores the selfie for the open challenge and asks the
+// comparer for a decision. Attempts the comparer is unsure about wait for a
+// moderator.
+func (s *verificationService) SubmitSelfie(ctx context.Context, userID string, file io.Reader) (*verification.Verification, error) {
+	v, err := s.repo.GetLatestByUserID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrNoChallenge
+		}
+		return nil, err
+	}
+	if v.Status != verification.StatusChallenged {
+		return nil, ErrNoChallenge
+	}
+	if v.Expired(time.Now()) {
+		return nil, ErrChallengeExpired
+	}
+
+	data, err := io.ReadAll(io.LimitReader(file, MaxSelfieBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > MaxSelfieBytes {
+		return nil, ErrSelfieTooLarge
+	}
+	switch imaging.Sniff(data) {
+	case imaging.FormatJPEG, imaging.FormatPNG, imaging.FormatWebP:
+	default:
+		return nil, ErrInvalidSelfie
+	}
+
+	// The selfie and the reference photo are held decoded until compared.
+	if err := s.slots.Acquire(ctx); err != nil {
+		return nil, err
+	}
+	defer s.slots.Release()
+
+	// Re-encoding strips EXIF metadata before the selfie is stored.
+	outputs, err := s.processor.Process(data)
+	if err != nil {
+		switch {
+		case errors.Is(err, imaging.ErrUnsupportedImage):
+			return nil, ErrInvalidSelfie
+		case errors.Is(err, imaging.ErrTooManyPixels):
+			return nil, ErrSelfieTooLarge
+		}
+		return nil, err
+	}
+	selfie := outputs[0].Data
+
+	key := "verifications/" + userID + "/" + v.ID + ".jpg"
+	if _, err := s.storage.Upload(ctx, bytes.NewReader(selfie), int64(len(selfie)), imaging.ContentType, key); err != nil {
+		return nil, err
+	}
+
+	var reference []byte
+	if referenceKey, err := s.referenceKey(ctx, userID); err != nil {
+		return nil, err
+	} else if referenceKey != "" {
+		if reference, err = s.storage.Download(ctx, referenceKey); err != nil {
+			return nil, err
+		}
+	}
+	// A comparer error leaves the attempt for a moderator.
+	result, compareErr := s.comparer.Compare(ctx, selfie, reference, v.Pose.Code)
+	var score *float64
+	var duplicate bool
+	if compareErr == nil && result.Decision != "" {
+		score, duplicate = &result.Score, result.Duplicate
+	}
+
+	if err := s.repo.MarkSubmitted(ctx, v.ID, key, score, duplicate); err != nil {
+		return nil, err
+	}
+	v.Status = verification.StatusSubmitted
+	v.SelfieKey = &key
+	v.Score = score
+	v.Duplicate = duplicate
+
+	// A copy of the profile photo proves nothing about who took it, whatever
+	// the comparer decided.
+	if compareErr != nil || result.Decision != facematch.DecisionApprove || duplicate {
+		return v, nil
+	}
+	return s.repo.Decide(ctx, v.ID, verification.StatusApproved, nil, nil)
+}
+
+// referenceKey returns the storage key of the medium variant of the user's
+// primary approved photo, or "" if there is none.
+func (s *verificationService) referenceKey(ctx context.Context, userID string) (string, error) {
+	photos, err := s.photoRepo.ListByUserID(ctx, userID)
+	if err != nil {
+		return "", err
+	}
+	for _, p := range photos {
+		if p.IsPrimary && p.Status == photo.StatusReady && p.ModerationStatus == photo.ModerationApproved {
+			return photo.VariantKey(p.StorageKey, photo.VariantMedium), nil
+		}
+	}
+	return "", nil
+}
+
+func (s *verificationService) GetStatus(ctx context.Context, userID string) (*verification.Verification, error) {
+	return s.repo.GetLatestByUserID(ctx, userID)
+}
+
+func (s *verificationService) ListPendingReview(ctx context.Context, limit, offset int) ([]*verification.Verification, error) {
+	if limit <= 0 {
+		limit = DefaultQueueLimit
+	}
+	limit = min(limit, MaxQueueLimit)
+	offset = max(offset, 0)
+
+	vs, err := s.repo.ListSubmitted(ctx, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	for _, v := range vs {
+		s.resolveSelfieURL(ctx, v)
+		s.resolveReferenceURL(ctx, v)
+	}
+	if vs == nil {
+		vs = []*verification.Verification{}
+	}
+	return vs, nil
+}
+
+func (s *verificationService) Approve(ctx context.Context, reviewerID, id string) (*verification.Verification, error) {
+	return s.repo.Decide(ctx, id, verification.StatusApproved, nil, &reviewerID)
+}
+
+func (s *verificationService) Reject(ctx context.Context, reviewerID, id, reason string) (*verification.Verification, error) {
+	if reason == "" {
+		return nil, ErrReasonRequired
+	}
+	return s.repo.Decide(ctx, id, verification.StatusRejected, &reason, &reviewerID)
+}
+
+// resolveSelfieURL presigns the selfie for moderators. A failure leaves the
+// URL empty.
+func (s *verificationService) resolveSelfieURL(ctx context.Context, v *verification.Verification) {
+	if v.SelfieKey == nil {
+		return
+	}
+	url, err := s.storage.GetPresignedURL(ctx, *v.SelfieKey)
+	if err != nil {
+		return
+	}
+	v.SelfieURL = url
+}
+
+// resolveReferenceURL presigns the user's current profile photo so
+// moderators can compare faces. A failure leaves the URL empty.
+func (s *verificationService) resolveReferenceURL(ctx context.Context, v *verification.Verification) {
+	key, err := s.referenceKey(ctx, v.UserID)
+	if err != nil || key == "" {
+		return
+	}
+	url, err := s.storage.GetPresignedURL(ctx, key)
+	if err != nil {
+		return
+	}
+	v.ReferenceURL = url
+}
@@ -0,0 +1,457 @@
+package service_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"image"
+	"image/png"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/kisssonik/hearts/internal/photo"
+	"github.com/kisssonik/hearts/internal/verification"
+	"github.com/kisssonik/hearts/internal/verification/facematch"
+	"github.com/kisssonik/hearts/internal/verification/repository"
+	"github.com/kisssonik/hearts/internal/verification/service"
+	"github.com/kisssonik/hearts/pkg/imaging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockVerificationRepository is a mock implementation of repository.VerificationRepository
+type MockVerificationRepository struct {
+	mock.Mock
+}
+
+func (m *MockVerificationRepository) Create(ctx context.Context, v *verification.Verification) error {
+	args := m.Called(ctx, v)
+	return args.Error(0)
+}
+
+func (m *MockVerificationRepository) GetLatestByUserID(ctx context.Context, userID string) (*verification.Verification, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*verification.Verification), args.Error(1)
+}
+
+func (m *MockVerificationRepository) MarkSubmitted(ctx context.Context, id, selfieKey string, score *float64, duplicate bool) error {
+	args := m.Called(ctx, id, selfieKey, score, duplicate)
+	return args.Error(0)
+}
+
+func (m *MockVerificationRepository) Decide(ctx context.Context, id, status string, reason, reviewerID *string) (*verification.Verification, error) {
+	args := m.Called(ctx, id, status, reason, reviewerID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*verification.Verification), args.Error(1)
+}
+
+func (m *MockVerificationRepository) ListSubmitted(ctx context.Context, limit, offset int) ([]*verification.Verification, error) {
+	args := m.Called(ctx, limit, offset)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*verification.Verification), args.Error(1)
+}
+
+// MockPhotoRepository is a mock implementation of photo's repository.PhotoRepository
+type MockPhotoRepository struct {
+	mock.Mock
+}
+
+func (m *MockPhotoRepository) Create(ctx context.Context, p *photo.Photo, limit int) error {
+	args := m.Called(ctx, p, limit)
+	return args.Error(0)
+}
+
+func (m *MockPhotoRepository) MarkReady(ctx context.Context, p *photo.Photo) error {
+	args := m.Called(ctx, p)
+	return args.Error(0)
+}
+
+func (m *MockPhotoRepository) MarkFailed(ctx context.Context, photoID string) error {
+	args := m.Called(ctx, photoID)
+	return args.Error(0)
+}
+
+func (m *MockPhotoRepository) GetByID(ctx context.Context, userID, photoID string) (*photo.Photo, error) {
+	args := m.Called(ctx, userID, photoID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*photo.Photo), args.Error(1)
+}
+
+func (m *MockPhotoRepository) ListByUserID(ctx context.Context, userID string) ([]*photo.Photo, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*photo.Photo), args.Error(1)
+}
+
+func (m *MockPhotoRepository) Reorder(ctx context.Context, userID string, photoIDs []string) error {
+	args := m.Called(ctx, userID, photoIDs)
+	return args.Error(0)
+}
+
+func (m *MockPhotoRepository) SetPrimary(ctx context.Context, userID, photoID string) error {
+	args := m.Called(ctx, userID, photoID)
+	return args.Error(0)
+}
+
+func (m *MockPhotoRepository) Delete(ctx context.Context, userID, photoID string) error {
+	args := m.Called(ctx, userID, photoID)
+	return args.Error(0)
+}
+
+func (m *MockPhotoRepository) FindByID(ctx context.Context, photoID string) (*photo.Photo, error) {
+	args := m.Called(ctx, photoID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*photo.Photo), args.Error(1)
+}
+
+func (m *MockPhotoRepository) SetModeration(ctx context.Context, photoID, status string, reason, moderatorID *string) (*photo.Photo, error) {
+	args := m.Called(ctx, photoID, status, reason, moderatorID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*photo.Photo), args.Error(1)
+}
+
+func (m *MockPhotoRepository) ListModerationQueue(ctx context.Context, limit, offset int) ([]*photo.Photo, error) {
+	args := m.Called(ctx, limit, offset)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*photo.Photo), args.Error(1)
+}
+
+// MockStorageProvider
+type MockStorageProvider struct {
+	mock.Mock
+}
+
+func (m *MockStorageProvider) Upload(ctx context.Context, file io.Reader, size int64, contentType, key string) (string, error) {
+	args := m.Called(ctx, file, size, contentType, key)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockStorageProvider) GetPresignedURL(ctx context.Context, key string) (string, error) {
+	args := m.Called(ctx, key)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockStorageProvider) Download(ctx context.Context, key string) ([]byte, error) {
+	args := m.Called(ctx, key)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]byte), args.Error(1)
+}
+
+func (m *MockStorageProvider) Delete(ctx context.Context, key string) error {
+	args := m.Called(ctx, key)
+	return args.Error(0)
+}
+
+// MockComparer
+type MockComparer struct {
+	mock.Mock
+}
+
+func (m *MockComparer) Compare(ctx context.Context, selfie, reference []byte, pose string) (facematch.Result, error) {
+	args := m.Called(ctx, selfie, reference, pose)
+	return args.Get(0).(facematch.Result), args.Error(1)
+}
+
+func testPNG(t *testing.T, w, h int) []byte {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, w, h))); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// hugePNG is a tiny PNG whose header declares width × height pixels.
+func hugePNG(t *testing.T, width, height uint32) []byte {
+	data := testPNG(t, 1, 1)
+	ihdr := data[12:29]
+	binary.BigEndian.PutUint32(ihdr[4:8], width)
+	binary.BigEndian.PutUint32(ihdr[8:12], height)
+	binary.BigEndian.PutUint32(data[29:33], crc32.ChecksumIEEE(ihdr))
+	return data
+}
+
+func openChallenge() *verification.Verification {
+	return &verification.Verification{
+		ID:        "v1",
+		UserID:    "user1",
+		Pose:      verification.Pose{Code: "thumbs_up"},
+		Status:    verification.StatusChallenged,
+		ExpiresAt: time.Now().Add(time.Minute),
+	}
+}
+
+func TestVerificationService_StartChallenge(t *testing.T) {
+	mockRepo := new(MockVerificationRepository)
+	s := service.NewVerificationService(mockRepo, new(MockPhotoRepository), new(MockStorageProvider), new(MockComparer), nil)
+
+	ctx := context.Background()
+	mockRepo.On("GetLatestByUserID", ctx, "user1").Return(nil, repository.ErrNotFound)
+	mockRepo.On("Create", ctx, mock.MatchedBy(func(v *verification.Verification) bool {
+		_, known := verification.PoseByCode(v.Pose.Code)
+		return v.UserID == "user1" && v.Status == verification.StatusChallenged && known && v.ExpiresAt.After(time.Now())
+	})).Return(nil)
+
+	v, err := s.StartChallenge(ctx, "user1")
+
+	assert.NoError(t, err)
+	assert.NotEmpty(t, v.Pose.Instructions)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestVerificationService_StartChallenge_Conflicts(t *testing.T) {
+	tests := []struct {
+		status  string
+		wantErr error
+	}{
+		{verification.StatusApproved, service.ErrAlreadyVerified},
+		{verification.StatusSubmitted, service.ErrReviewPending},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.status, func(t *testing.T) {
+			mockRepo := new(MockVerificationRepository)
+			s := service.NewVerificationService(mockRepo, new(MockPhotoRepository), new(MockStorageProvider), new(MockComparer), nil)
+
+			ctx := context.Background()
+			mockRepo.On("GetLatestByUserID", ctx, "user1").Return(&verification.Verification{Status: tt.status}, nil)
+
+			_, err := s.StartChallenge(ctx, "user1")
+
+			assert.ErrorIs(t, err, tt.wantErr)
+			mockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestVerificationService_SubmitSelfie_AutoApproved(t *testing.T) {
+	mockRepo := new(MockVerificationRepository)
+	mockPhotos := new(MockPhotoRepository)
+	mockStorage := new(MockStorageProvider)
+	mockComparer := new(MockComparer)
+	s := service.NewVerificationService(mockRepo, mockPhotos, mockStorage, mockComparer, nil)
+
+	ctx := context.Background()
+	reference := []byte("reference")
+
+	mockRepo.On("GetLatestByUserID", ctx, "user1").Return(openChallenge(), nil)
+	mockStorage.On("Upload", ctx, mock.Anything, mock.Anything, "image/jpeg", "verifications/user1/v1.jpg").Return("key", nil)
+	mockPhotos.On("ListByUserID", ctx, "user1").Return([]*photo.Photo{
+		{StorageKey: "user1/a/full.jpg", Status: photo.StatusReady, ModerationStatus: photo.ModerationApproved},
+		{StorageKey: "user1/b/full.jpg", IsPrimary: true, Status: photo.StatusReady, ModerationStatus: photo.ModerationApproved},
+	}, nil)
+	mockStorage.On("Download", ctx, "user1/b/medium.jpg").Return(reference, nil)
+	mockComparer.On("Compare", ctx, mock.Anything, reference, "thumbs_up").Return(facematch.Result{Decision: facematch.DecisionApprove, Score: 0.95}, nil)
+	mockRepo.On("MarkSubmitted", ctx, "v1", "verifications/user1/v1.jpg", mock.MatchedBy(func(score *float64) bool {
+		return score != nil && *score == 0.95
+	}), false).Return(nil)
+	approved := &verification.Verification{ID: "v1", Status: verification.StatusApproved}
+	mockRepo.On("Decide", ctx, "v1", verification.StatusApproved, (*string)(nil), (*string)(nil)).Return(approved, nil)
+
+	v, err := s.SubmitSelfie(ctx, "user1", bytes.NewReader(testPNG(t, 400, 400)))
+
+	assert.NoError(t, err)
+	assert.Equal(t, verification.StatusApproved, v.Status)
+	mockRepo.AssertExpectations(t)
+	mockStorage.AssertExpectations(t)
+	mockComparer.AssertExpectations(t)
+}
+
+func TestVerificationService_SubmitSelfie_NeedsReview(t *testing.T) {
+	mockRepo := new(MockVerificationRepository)
+	mockPhotos := new(MockPhotoRepository)
+	mockStorage := new(MockStorageProvider)
+	mockComparer := new(MockComparer)
+	s := service.NewVerificationService(mockRepo, mockPhotos, mockStorage, mockComparer, nil)
+
+	ctx := context.Background()
+
+	mockRepo.On("GetLatestByUserID", ctx, "user1").Return(openChallenge(), nil)
+	mockStorage.On("Upload", ctx, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return("key", nil)
+	mockPhotos.On("ListByUserID", ctx, "user1").Return([]*photo.Photo{}, nil)
+	mockComparer.On("Compare", ctx, mock.Anything, []byte(nil), mock.Anything).Return(facematch.Result{Decision: facematch.DecisionReview}, nil)
+	mockRepo.On("MarkSubmitted", ctx, "v1", mock.Anything, mock.Anything, false).Return(nil)
+
+	v, err := s.SubmitSelfie(ctx, "user1", bytes.NewReader(testPNG(t, 400, 400)))
+
+	assert.NoError(t, err)
+	assert.Equal(t, verification.StatusSubmitted, v.Status)
+	mockRepo.AssertNotCalled(t, "Decide", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestVerificationService_SubmitSelfie_DuplicateNeedsReview(t *testing.T) {
+	mockRepo := new(MockVerificationRepository)
+	mockPhotos := new(MockPhotoRepository)
+	mockStorage := new(MockStorageProvider)
+	mockComparer := new(MockComparer)
+	s := service.NewVerificationService(mockRepo, mockPhotos, mockStorage, mockComparer, nil)
+
+	ctx := context.Background()
+	reference := []byte("reference")
+
+	mockRepo.On("GetLatestByUserID", ctx, "user1").Return(openChallenge(), nil)
+	mockStorage.On("Upload", ctx, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return("key", nil)
+	mockPhotos.On("ListByUserID", ctx, "user1").Return([]*photo.Photo{
+		{StorageKey: "user1/b/full.jpg", IsPrimary: true, Status: photo.StatusReady, ModerationStatus: photo.ModerationApproved},
+	}, nil)
+	mockStorage.On("Download", ctx, "user1/b/medium.jpg").Return(reference, nil)
+	// Even an approving comparer cannot verify a re-uploaded profile photo.
+	mockComparer.On("Compare", ctx, mock.Anything, reference, "thumbs_up").Return(facematch.Result{Decision: facematch.DecisionApprove, Score: 1, Duplicate: true}, nil)
+	mockRepo.On("MarkSubmitted", ctx, "v1", mock.Anything, mock.Anything, true).Return(nil)
+
+	v, err := s.SubmitSelfie(ctx, "user1", bytes.NewReader(testPNG(t, 400, 400)))
+
+	assert.NoError(t, err)
+	assert.Equal(t, verification.StatusSubmitted, v.Status)
+	assert.True(t, v.Duplicate)
+	mockRepo.AssertNotCalled(t, "Decide", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestVerificationService_SubmitSelfie_Errors(t *testing.T) {
+	expired := openChallenge()
+	expired.ExpiresAt = time.Now().Add(-time.Minute)
+
+	tests := []struct {
+		name    string
+		latest  *verification.Verification
+		repoErr error
+		data    []byte
+		wantErr error
+	}{
+		{"no attempt", nil, repository.ErrNotFound, nil, service.ErrNoChallenge},
+		{"already submitted", &verification.Verification{Status: verification.StatusSubmitted}, nil, nil, service.ErrNoChallenge},
+		{"expired", expired, nil, nil, service.ErrChallengeExpired},
+		{"not an image", openChallenge(), nil, []byte("<svg></svg>"), service.ErrInvalidSelfie},
+		{"too large", openChallenge(), nil, make([]byte, service.MaxSelfieBytes+1), service.ErrSelfieTooLarge},
+		{"too many pixels", openChallenge(), nil, hugePNG(t, 100_000, 100_000), service.ErrSelfieTooLarge},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockVerificationRepository)
+			mockStorage := new(MockStorageProvider)
+			s := service.NewVerificationService(mockRepo, new(MockPhotoRepository), mockStorage, new(MockComparer), nil)
+
+			ctx := context.Background()
+			if tt.latest != nil {
+				mockRepo.On("GetLatestByUserID", ctx, "user1").Return(tt.latest, nil)
+			} else {
+				mockRepo.On("GetLatestByUserID", ctx, "user1").Return(nil, tt.repoErr)
+			}
+
+			_, err := s.SubmitSelfie(ctx, "user1", bytes.NewReader(tt.data))
+
+			assert.ErrorIs(t, err, tt.wantErr)
+			mockStorage.AssertNotCalled(t, "Upload", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestVerificationService_SubmitSelfie_WaitsForSlot(t *testing.T) {
+	mockRepo := new(MockVerificationRepository)
+	mockStorage := new(MockStorageProvider)
+	slots := imaging.NewSlots(1)
+	s := service.NewVerificationService(mockRepo, new(MockPhotoRepository), mockStorage, new(MockComparer), slots)
+
+	// A photo upload holds the only slot.
+	require.NoError(t, slots.Acquire(context.Background()))
+	defer slots.Release()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	mockRepo.On("GetLatestByUserID", ctx, "user1").Return(openChallenge(), nil)
+
+	_, err := s.SubmitSelfie(ctx, "user1", bytes.NewReader(testPNG(t, 400, 400)))
+
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	mockStorage.AssertNotCalled(t, "Upload", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestVerificationService_SubmitSelfie_ComparerError(t *testing.T) {
+	mockRepo := new(MockVerificationRepository)
+	mockPhotos := new(MockPhotoRepository)
+	mockStorage := new(MockStorageProvider)
+	mockComparer := new(MockComparer)
+	s := service.NewVerificationService(mockRepo, mockPhotos, mockStorage, mockComparer, nil)
+
+	ctx := context.Background()
+
+	mockRepo.On("GetLatestByUserID", ctx, "user1").Return(openChallenge(), nil)
+	mockStorage.On("Upload", ctx, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return("key", nil)
+	mockPhotos.On("ListByUserID", ctx, "user1").Return([]*photo.Photo{}, nil)
+	mockComparer.On("Compare", ctx, mock.Anything, mock.Anything, mock.Anything).Return(facematch.Result{}, errors.New("service down"))
+	mockRepo.On("MarkSubmitted", ctx, "v1", mock.Anything, (*float64)(nil), false).Return(nil)
+
+	v, err := s.SubmitSelfie(ctx, "user1", bytes.NewReader(testPNG(t, 400, 400)))
+
+	assert.NoError(t, err)
+	assert.Equal(t, verification.StatusSubmitted, v.Status)
+	assert.Nil(t, v.Score)
+}
+
+func TestVerificationService_Reject(t *testing.T) {
+	mockRepo := new(MockVerificationRepository)
+	s := service.NewVerificationService(mockRepo, new(MockPhotoRepository), new(MockStorageProvider), new(MockComparer), nil)
+
+	ctx := context.Background()
+
+	_, err := s.Reject(ctx, "admin1", "v1", "")
+	assert.ErrorIs(t, err, service.ErrReasonRequired)
+
+	reason := "face not visible"
+	reviewer := "admin1"
+	mockRepo.On("Decide", ctx, "v1", verification.StatusRejected, &reason, &reviewer).
+		Return(&verification.Verification{ID: "v1", Status: verification.StatusRejected, Reason: &reason}, nil)
+
+	v, err := s.Reject(ctx, "admin1", "v1", reason)
+
+	assert.NoError(t, err)
+	assert.Equal(t, verification.StatusRejected, v.Status)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestVerificationService_ListPendingReview(t *testing.T) {
+	mockRepo := new(MockVerificationRepository)
+	mockPhotos := new(MockPhotoRepository)
+	mockStorage := new(MockStorageProvider)
+	s := service.NewVerificationService(mockRepo, mockPhotos, mockStorage, new(MockComparer), nil)
+
+	ctx := context.Background()
+	key := "verifications/user1/v1.jpg"
+	mockRepo.On("ListSubmitted", ctx, service.MaxQueueLimit, 0).Return([]*verification.Verification{{ID: "v1", UserID: "user1", SelfieKey: &key}}, nil)
+	mockStorage.On("GetPresignedURL", ctx, key).Return("http://selfie", nil)
+	mockPhotos.On("ListByUserID", ctx, "user1").Return([]*photo.Photo{
+		{StorageKey: "user1/b/full.jpg", IsPrimary: true, Status: photo.StatusReady, ModerationStatus: photo.ModerationApproved},
+	}, nil)
+	mockStorage.On("GetPresignedURL", ctx, "user1/b/medium.jpg").Return("http://reference", nil)
+
+	vs, err := s.ListPendingReview(ctx, 1000, -5)
+
+	assert.NoError(t, err)
+	assert.Len(t, vs, 1)
+	assert.Equal(t, "http://selfie", vs[0].SelfieURL)
+	assert.Equal(t, "http://reference", vs[0].ReferenceURL)
+	mockRepo.AssertExpectations(t)
+}
@@ -0,0 +1,68 @@
+package verification
+
+import (
+	"math/rand/v2"
+	"time"
+)
+
+// Statuses of a verification attempt.
+const (
+	StatusChallenged = "challenged" // Pose issued, waiting for a selfie
+	StatusSubmitted  = "submitted"  // Selfie received, waiting for a decision
+	StatusApproved   = "approved"
+	StatusRejected   = "rejected"
+)
+
+// Pose is a gesture the user must copy in their selfie. Asking for a random
+// pose makes it harder to pass with a photo taken from elsewhere.
+type Pose struct {
+	Code         string `json:"code"`
+	Instructions string `json:"instructions"`
+}
+
+// Poses are the challenges a user can be given.
+var Poses = []Pose{
+	{Code: "two_fingers", Instructions: "Hold up two fingers next to your face"},
+	{Code: "thumbs_up", Instructions: "Give a thumbs up next to your face"},
+	{Code: "touch_nose", Instructions: "Touch your nose with your index finger"},
+	{Code: "cover_eye", Instructions: "Cover your left eye with your hand"},
+	{Code: "tilt_head", Instructions: "Tilt your head to the right and smile"},
+}
+
+// RandomPose picks a pose uniformly at random.
+func RandomPose() Pose {
+	return Poses[rand.IntN(len(Poses))]
+}
+
+// PoseByCode looks up a pose, returning false if the code is unknown.
+func PoseByCode(code string) (Pose, bool) {
+	for _, p := range Poses {
+		if p.Code == code {
+			return p, true
+		}
+	}
+	return Pose{}, false
+}
+
+// Verification is one attempt by a user to verify their identity.
+type Verification struct {
+	ID           string    `json:"id" db:"id"`
+	UserID       string    `json:"userId" db:"user_id"`
+	Pose         Pose      `json:"pose"`
+	Status       string    `json:"status" db:"status"`
+	SelfieKey    *string   `json:"-" db:"selfie_key"`
+	SelfieURL    string    `json:"selfieUrl,omitempty"`
+	ReferenceURL string    `json:"referenceUrl,omitempty"`             // Profile photo to compare against, for moderators
+	Score        *float64  `json:"score,omitempty" db:"score"`         // Face comparison score, 0..1
+	Duplicate    bool      `json:"duplicate,omitempty" db:"duplicate"` // Selfie is a near copy of the profile photo
+	Reason       *string   `json:"reason,omitempty" db:"reason"`       // Shown to the user on rejection
+	ReviewedBy   *string   `json:"-" db:"reviewed_by"`
+	ExpiresAt    time.Time `json:"expiresAt" db:"expires_at"`
+	CreatedAt    time.Time `json:"createdAt" db:"created_at"`
+	UpdatedAt    time.Time `json:"updatedAt" db:"updated_at"`
+}
+
+// Expired reports whether the challenge can no longer accept a selfie.
+func (v *Verification) Expired(now time.Time) bool {
+	return v.Status == StatusChallenged && !now.Before(v.ExpiresAt)
+}
@@ -41,3 +41,26 @@ func Middleware(authService AuthService, logger *zap.Logger) func(http.Handler)
 		})
 	}
 }
+
+// RequireAdmin restricts a handler to the given user IDs. It must be chained
+// after Middleware so the user ID is already present in the request context.
+func RequireAdmin(adminUserIDs []string) func(http.Handler) http.Handler {
+	admins := make(map[string]struct{}, len(adminUserIDs))
+	for _, id := range adminUserIDs {
+		admins[id] = struct{}{}
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			userID, ok := r.Context().Value(UserIDKey).(string)
+			if !ok {
+				http.Error(w, "Unauthorized", http.StatusUnauthorized)
+				return
+			}
+			if _, ok := admins[userID]; !ok {
+				http.Error(w, "Forbidden", http.StatusForbidden)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
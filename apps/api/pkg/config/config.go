@@ -19,6 +19,9 @@ type Config struct {
 	Logger   LoggerConfig   `mapstructure:"logger"`
 	Storage  StorageConfig  `mapstructure:"storage"`
 	Kafka    KafkaConfig    `mapstructure:"kafka"`
+	Profile  ProfileConfig  `mapstructure:"profile"`
+	Photo    PhotoConfig    `mapstructure:"photo"`
+	Likes    LikesConfig    `mapstructure:"likes"`
 }
 
 // AppConfig captures application-wide settings.
@@ -41,7 +44,8 @@ type DatabaseConfig struct {
 
 // AuthConfig contains authentication-related configuration.
 type AuthConfig struct {
-	JWTSecret string `mapstructure:"jwt_secret"`
+	JWTSecret    string   `mapstructure:"jwt_secret"`
+	AdminUserIDs []string `mapstructure:"admin_user_ids"`
 }
 
 // LoggerConfig describes the zap logger configuration.
@@ -67,6 +71,31 @@ type KafkaConfig struct {
 	GroupID string   `mapstructure:"group_id"`
 }
 
+// ProfileConfig contains profile validation rules and features.
+type ProfileConfig struct {
+	MinAge      int  `mapstructure:"min_age"`
+	NotifyViews bool `mapstructure:"notify_views"` // Notify users when someone views their profile
+}
+
+// PhotoConfig contains photo upload limits.
+type PhotoConfig struct {
+	MaxBytes     int64 `mapstructure:"max_bytes"`
+	MaxCount     int   `mapstructure:"max_count"`
+	MinDimension int   `mapstructure:"min_dimension"`
+	MaxDimension int   `mapstructure:"max_dimension"`
+	MaxPixels    int   `mapstructure:"max_pixels"`    // Width × height cap, bounding decoded memory
+	MaxInFlight  int   `mapstructure:"max_in_flight"` // Photos and selfies processed at the same time
+}
+
+// LikesConfig contains like and match features.
+type LikesConfig struct {
+	ReceivedFullList bool          `mapstructure:"received_full_list"`  // Show who liked you; otherwise only a blurred preview
+	SuperLikesPerDay int           `mapstructure:"super_likes_per_day"` // Resets at midnight UTC
+	LikeLimit        int           `mapstructure:"like_limit"`          // Likes allowed in any like_window
+	LikeWindow       time.Duration `mapstructure:"like_window"`
+	RewindWindow     time.Duration `mapstructure:"rewind_window"` // How long a like or pass can be undone
+}
+
 // Load reads configuration using Viper, applying sane defaults and environment overrides.
 func Load() (Config, error) {
 	v := viper.New()
@@ -102,6 +131,20 @@ func Load() (Config, error) {
 	v.SetDefault("kafka.brokers", []string{"kafka:29092"})
 	v.SetDefault("kafka.topic", "match-checks")
 	v.SetDefault("kafka.group_id", "hearts-match-checker")
+	v.SetDefault("auth.admin_user_ids", []string{})
+	v.SetDefault("profile.min_age", 18)
+	v.SetDefault("profile.notify_views", true)
+	v.SetDefault("photo.max_bytes", 10<<20)
+	v.SetDefault("photo.max_count", 6)
+	v.SetDefault("photo.min_dimension", 320)
+	v.SetDefault("photo.max_dimension", 8000)
+	v.SetDefault("photo.max_pixels", 40_000_000)
+	v.SetDefault("photo.max_in_flight", 4)
+	v.SetDefault("likes.received_full_list", true)
+	v.SetDefault("likes.super_likes_per_day", 1)
+	v.SetDefault("likes.like_limit", 100)
+	v.SetDefault("likes.like_window", "24h")
+	v.SetDefault("likes.rewind_window", "5m")
 
 	// Explicit environment bindings for commonly overridden keys.
 	_ = v.BindEnv("database.url", "DATABASE_URL")
@@ -0,0 +1,155 @@
+// Package imaging normalises uploaded photos: it decodes them, applies the
+// EXIF orientation, scales them into fixed size variants and re-encodes them
+// as JPEG. Re-encoding drops all metadata, including GPS coordinates.
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/jpeg"
+
+	// Register decoders used by image.Decode.
+	_ "image/gif"
+	_ "image/png"
+
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+)
+
+var (
+	ErrUnsupportedImage = errors.New("unsupported image format")
+	ErrTooManyPixels    = errors.New("image has too many pixels")
+)
+
+// DefaultMaxPixels bounds width × height when no limit is given. Decoded to
+// RGBA that is about 160MB.
+const DefaultMaxPixels = 40_000_000
+
+// ContentType is the MIME type of every encoded variant.
+const ContentType = "image/jpeg"
+
+// Variant describes one output size. MaxSide bounds the longest edge;
+// images smaller than that are never upscaled.
+type Variant struct {
+	Name    string
+	MaxSide int
+}
+
+// Output is a single encoded variant.
+type Output struct {
+	Name   string
+	Data   []byte
+	Width  int
+	Height int
+}
+
+type Processor struct {
+	variants  []Variant
+	quality   int
+	maxPixels int
+}
+
+// NewProcessor returns a processor for variants. Images larger than
+// maxPixels are refused before decoding; zero means DefaultMaxPixels.
+func NewProcessor(variants []Variant, quality, maxPixels int) *Processor {
+	if maxPixels <= 0 {
+		maxPixels = DefaultMaxPixels
+	}
+	return &Processor{variants: variants, quality: quality, maxPixels: maxPixels}
+}
+
+// Process decodes data and returns one output per configured variant, in the
+// configured order. The size is read from the header first, so an image that
+// declares more than maxPixels is never decoded.
+func (p *Processor) Process(data []byte) ([]Output, error) {
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrUnsupportedImage
+	}
+	if cfg.Width*cfg.Height > p.maxPixels {
+		return nil, ErrTooManyPixels
+	}
+
+	src, format, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrUnsupportedImage
+	}
+	if format == "jpeg" {
+		src = orient(src, jpegOrientation(data))
+	}
+
+	outputs := make([]Output, 0, len(p.variants))
+	for _, v := range p.variants {
+		img := fit(src, v.MaxSide)
+		var buf bytes.Buffer
+		if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: p.quality}); err != nil {
+			return nil, err
+		}
+		b := img.Bounds()
+		outputs = append(outputs, Output{Name: v.Name, Data: buf.Bytes(), Width: b.Dx(), Height: b.Dy()})
+	}
+	return outputs, nil
+}
+
+// fit scales src so its longest side is at most maxSide.
+func fit(src image.Image, maxSide int) image.Image {
+	b := src.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if w <= maxSide && h <= maxSide {
+		return src
+	}
+	if w >= h {
+		h = max(1, h*maxSide/w)
+		w = maxSide
+	} else {
+		w = max(1, w*maxSide/h)
+		h = maxSide
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), src, b, draw.Src, nil)
+	return dst
+}
+
+// orient returns src transformed so that it displays upright for the given
+// EXIF orientation.
+func orient(src image.Image, orientation int) image.Image {
+	if orientation == OrientationNormal {
+		return src
+	}
+
+	b := src.Bounds()
+	w, h := b.Dx(), b.Dy()
+	swap := orientation >= OrientationTranspose
+	dw, dh := w, h
+	if swap {
+		dw, dh = h, w
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			var dx, dy int
+			switch orientation {
+			case OrientationFlipH:
+				dx, dy = w-1-x, y
+			case OrientationRotate180:
+				dx, dy = w-1-x, h-1-y
+			case OrientationFlipV:
+				dx, dy = x, h-1-y
+			case OrientationTranspose:
+				dx, dy = y, x
+			case OrientationRotate90:
+				dx, dy = h-1-y, x
+			case OrientationTransverse:
+				dx, dy = h-1-y, w-1-x
+			case OrientationRotate270:
+				dx, dy = y, w-1-x
+			default:
+				return src
+			}
+			dst.Set(dx, dy, src.At(b.Min.X+x, b.Min.Y+y))
+		}
+	}
+	return dst
+}
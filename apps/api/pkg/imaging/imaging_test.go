@@ -0,0 +1,133 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// withOrientation inserts a minimal EXIF APP1 segment carrying orientation
+// (and a GPS IFD pointer) right after the SOI marker of a JPEG.
+func withOrientation(t *testing.T, jpg []byte, orientation uint16) []byte {
+	var tiff bytes.Buffer
+	tiff.WriteString("MM")
+	binary.Write(&tiff, binary.BigEndian, uint16(42))
+	binary.Write(&tiff, binary.BigEndian, uint32(8))
+	binary.Write(&tiff, binary.BigEndian, uint16(2))
+	// Orientation, SHORT, count 1
+	binary.Write(&tiff, binary.BigEndian, []uint16{0x0112, 3})
+	binary.Write(&tiff, binary.BigEndian, uint32(1))
+	binary.Write(&tiff, binary.BigEndian, []uint16{orientation, 0})
+	// GPSInfo IFD pointer, LONG, count 1
+	binary.Write(&tiff, binary.BigEndian, []uint16{0x8825, 4})
+	binary.Write(&tiff, binary.BigEndian, uint32(1))
+	binary.Write(&tiff, binary.BigEndian, uint32(0))
+	binary.Write(&tiff, binary.BigEndian, uint32(0))
+
+	payload := append([]byte("Exif\x00\x00"), tiff.Bytes()...)
+	seg := []byte{0xFF, 0xE1, 0, 0}
+	binary.BigEndian.PutUint16(seg[2:], uint16(len(payload)+2))
+	seg = append(seg, payload...)
+
+	require.True(t, len(jpg) > 2)
+	out := append([]byte{}, jpg[:2]...)
+	out = append(out, seg...)
+	return append(out, jpg[2:]...)
+}
+
+func testJPEG(t *testing.T, w, h int) []byte {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	// Mark the top-left corner so rotations can be checked.
+	for y := 0; y < h/4; y++ {
+		for x := 0; x < w/4; x++ {
+			img.Set(x, y, color.White)
+		}
+	}
+	var buf bytes.Buffer
+	require.NoError(t, jpeg.Encode(&buf, img, nil))
+	return buf.Bytes()
+}
+
+func TestJPEGOrientation(t *testing.T) {
+	data := withOrientation(t, testJPEG(t, 8, 4), OrientationRotate90)
+	assert.Equal(t, OrientationRotate90, jpegOrientation(data))
+	assert.Equal(t, OrientationNormal, jpegOrientation(testJPEG(t, 8, 4)))
+	assert.Equal(t, OrientationNormal, jpegOrientation([]byte("garbage")))
+}
+
+func TestProcess_VariantsAndOrientation(t *testing.T) {
+	p := NewProcessor([]Variant{{Name: "small", MaxSide: 50}, {Name: "large", MaxSide: 1000}}, 90, 0)
+	data := withOrientation(t, testJPEG(t, 400, 200), OrientationRotate90)
+
+	out, err := p.Process(data)
+	require.NoError(t, err)
+	require.Len(t, out, 2)
+
+	// Rotated to portrait, then scaled to fit.
+	assert.Equal(t, "small", out[0].Name)
+	assert.Equal(t, 25, out[0].Width)
+	assert.Equal(t, 50, out[0].Height)
+	// Never upscaled.
+	assert.Equal(t, 200, out[1].Width)
+	assert.Equal(t, 400, out[1].Height)
+
+	for _, o := range out {
+		// Re-encoded output carries no EXIF segment.
+		assert.False(t, bytes.Contains(o.Data, []byte("Exif\x00\x00")))
+		assert.Equal(t, OrientationNormal, jpegOrientation(o.Data))
+	}
+
+	// The marked top-left corner of the landscape source ends up top-right.
+	img, err := jpeg.Decode(bytes.NewReader(out[1].Data))
+	require.NoError(t, err)
+	r, _, _, _ := img.At(190, 10).RGBA()
+	assert.Greater(t, r, uint32(0x8000))
+	r, _, _, _ = img.At(10, 10).RGBA()
+	assert.Less(t, r, uint32(0x8000))
+}
+
+func TestProcess_Unsupported(t *testing.T) {
+	_, err := NewProcessor([]Variant{{Name: "full", MaxSide: 100}}, 90, 0).Process([]byte("not an image"))
+	assert.ErrorIs(t, err, ErrUnsupportedImage)
+}
+
+// hugePNG is a tiny PNG whose header declares width × height pixels.
+func hugePNG(t *testing.T, width, height uint32) []byte {
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, image.NewGray(image.Rect(0, 0, 1, 1))))
+	data := buf.Bytes()
+	// The IHDR chunk follows the 8-byte signature: length, type, then width
+	// and height, and its CRC covers type and data.
+	ihdr := data[12:29]
+	binary.BigEndian.PutUint32(ihdr[4:8], width)
+	binary.BigEndian.PutUint32(ihdr[8:12], height)
+	binary.BigEndian.PutUint32(data[29:33], crc32.ChecksumIEEE(ihdr))
+	return data
+}
+
+func TestProcess_TooManyPixels(t *testing.T) {
+	p := NewProcessor([]Variant{{Name: "full", MaxSide: 100}}, 90, 1_000_000)
+
+	_, err := p.Process(hugePNG(t, 100_000, 100_000))
+	assert.ErrorIs(t, err, ErrTooManyPixels)
+
+	_, err = p.Process(hugePNG(t, 1001, 1000))
+	assert.ErrorIs(t, err, ErrTooManyPixels)
+}
+
+func TestSniff(t *testing.T) {
+	assert.Equal(t, FormatJPEG, Sniff(testJPEG(t, 4, 4)))
+	assert.Equal(t, FormatPNG, Sniff([]byte("\x89PNG\r\n\x1a\nrest")))
+	assert.Equal(t, FormatGIF, Sniff([]byte("GIF89a...")))
+	assert.Equal(t, FormatWebP, Sniff([]byte("RIFF\x00\x00\x00\x00WEBPVP8 ")))
+	assert.Equal(t, "", Sniff([]byte("<svg xmlns=")))
+	assert.Equal(t, "", Sniff(nil))
+}
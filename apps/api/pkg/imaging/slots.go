@@ -0,0 +1,28 @@
+package imaging
+
+import "context"
+
+// Slots bounds how many images are processed at once. Decoded images are
+// large, so every caller that decodes uploads should share one Slots.
+type Slots chan struct{}
+
+// NewSlots allows n images in flight.
+func NewSlots(n int) Slots {
+	return make(Slots, n)
+}
+
+// Acquire waits for a free slot or for ctx to be done. Every successful
+// Acquire must be followed by Release.
+func (s Slots) Acquire(ctx context.Context) error {
+	select {
+	case s <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Release frees a slot taken by Acquire.
+func (s Slots) Release() {
+	<-s
+}
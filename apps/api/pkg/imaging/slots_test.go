@@ -0,0 +1,22 @@
+package imaging
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSlots(t *testing.T) {
+	slots := NewSlots(1)
+	require.NoError(t, slots.Acquire(context.Background()))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, slots.Acquire(ctx), context.DeadlineExceeded)
+
+	slots.Release()
+	assert.NoError(t, slots.Acquire(context.Background()))
+}
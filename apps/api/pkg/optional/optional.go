@@ -0,0 +1,38 @@
+// Package optional tells apart JSON members that are absent, explicitly null
+// or set, as JSON Merge Patch (RFC 7396) bodies require.
+package optional
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// Field is a struct member decoded from a merge patch. The zero value means
+// the member was absent.
+type Field[T any] struct {
+	Present bool // The member appeared in the document
+	Null    bool // The member was null, i.e. the field should be cleared
+	Value   T
+}
+
+// UnmarshalJSON is only called for members that are present.
+func (f *Field[T]) UnmarshalJSON(data []byte) error {
+	f.Present = true
+	if bytes.Equal(bytes.TrimSpace(data), []byte("null")) {
+		var zero T
+		f.Null, f.Value = true, zero
+		return nil
+	}
+	f.Null = false
+	return json.Unmarshal(data, &f.Value)
+}
+
+// Ptr returns nil if the member was null and a pointer to a copy of the
+// value otherwise.
+func (f Field[T]) Ptr() *T {
+	if f.Null {
+		return nil
+	}
+	v := f.Value
+	return &v
+}
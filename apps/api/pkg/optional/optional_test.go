@@ -0,0 +1,36 @@
+package optional_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/kisssonik/hearts/pkg/optional"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type patch struct {
+	Height optional.Field[int]    `json:"height"`
+	Gender optional.Field[string] `json:"gender"`
+	Bio    optional.Field[string] `json:"bio"`
+}
+
+func TestField_UnmarshalJSON(t *testing.T) {
+	var p patch
+	require.NoError(t, json.Unmarshal([]byte(`{"height": 180, "gender": null}`), &p))
+
+	assert.True(t, p.Height.Present)
+	assert.False(t, p.Height.Null)
+	assert.Equal(t, 180, *p.Height.Ptr())
+
+	assert.True(t, p.Gender.Present)
+	assert.True(t, p.Gender.Null)
+	assert.Nil(t, p.Gender.Ptr())
+
+	assert.False(t, p.Bio.Present)
+}
+
+func TestField_UnmarshalJSON_TypeMismatch(t *testing.T) {
+	var p patch
+	assert.Error(t, json.Unmarshal([]byte(`{"height": "tall"}`), &p))
+}
@@ -0,0 +1,62 @@
+package quota
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// memoryLimiter keeps uses in process memory. Quota is per process and lost
+// on restart, so it only stands in for a shared store in development and
+// tests.
+type memoryLimiter struct {
+	mu   sync.Mutex
+	uses map[string][]time.Time // Oldest first
+	now  func() time.Time
+}
+
+func NewMemoryLimiter() Limiter {
+	return &memoryLimiter{uses: make(map[string][]time.Time), now: time.Now}
+}
+
+func (l *memoryLimiter) Take(ctx context.Context, key string, limit Limit) (Usage, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	uses := l.uses[key]
+	// Drop the uses that have left the window.
+	cutoff := now.Add(-limit.Window)
+	i := 0
+	for i < len(uses) && !uses[i].After(cutoff) {
+		i++
+	}
+	uses = uses[i:]
+
+	if len(uses) >= limit.Max {
+		l.uses[key] = uses
+		return Usage{Remaining: 0, ResetAt: resetAt(uses, now, limit)}, ErrExceeded
+	}
+	uses = append(uses, now)
+	l.uses[key] = uses
+	return Usage{Remaining: limit.Max - len(uses), ResetAt: resetAt(uses, now, limit)}, nil
+}
+
+func (l *memoryLimiter) Refund(ctx context.Context, key string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if uses := l.uses[key]; len(uses) > 0 {
+		l.uses[key] = uses[:len(uses)-1]
+	}
+	return nil
+}
+
+// resetAt is when the oldest of uses leaves the window, or a full window
+// from now if there are none.
+func resetAt(uses []time.Time, now time.Time, limit Limit) time.Time {
+	if len(uses) == 0 {
+		return now.Add(limit.Window)
+	}
+	return uses[0].Add(limit.Window)
+}
@@ -0,0 +1,49 @@
+package quota
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryLimiter_RollingWindow(t *testing.T) {
+	ctx := context.Background()
+	start := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	now := start
+	l := NewMemoryLimiter().(*memoryLimiter)
+	l.now = func() time.Time { return now }
+	limit := Limit{Max: 2, Window: time.Hour}
+
+	usage, err := l.Take(ctx, "k", limit)
+	require.NoError(t, err)
+	assert.Equal(t, Usage{Remaining: 1, ResetAt: start.Add(time.Hour)}, usage)
+
+	now = start.Add(30 * time.Minute)
+	usage, err = l.Take(ctx, "k", limit)
+	require.NoError(t, err)
+	assert.Equal(t, Usage{Remaining: 0, ResetAt: start.Add(time.Hour)}, usage)
+
+	usage, err = l.Take(ctx, "k", limit)
+	assert.ErrorIs(t, err, ErrExceeded)
+	assert.Equal(t, Usage{Remaining: 0, ResetAt: start.Add(time.Hour)}, usage)
+
+	// Other keys have their own quota.
+	_, err = l.Take(ctx, "other", limit)
+	assert.NoError(t, err)
+
+	// The first use leaves the window and frees one unit.
+	now = start.Add(time.Hour)
+	usage, err = l.Take(ctx, "k", limit)
+	require.NoError(t, err)
+	assert.Equal(t, Usage{Remaining: 0, ResetAt: start.Add(90 * time.Minute)}, usage)
+
+	// A refund gives back the latest use.
+	require.NoError(t, l.Refund(ctx, "k"))
+	usage, err = l.Take(ctx, "k", limit)
+	require.NoError(t, err)
+	assert.Equal(t, 0, usage.Remaining)
+	assert.NoError(t, l.Refund(ctx, "unused"))
+}
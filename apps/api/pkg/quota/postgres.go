@@ -0,0 +1,81 @@
+package quota
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// postgresLimiter keeps one quota_events row per use, so quota is shared by
+// every API instance. Rows that have left the window are pruned as the key
+// is used.
+type postgresLimiter struct {
+	db *pgxpool.Pool
+}
+
+func NewPostgresLimiter(db *pgxpool.Pool) Limiter {
+	return &postgresLimiter{db: db}
+}
+
+func (l *postgresLimiter) Take(ctx context.Context, key string, limit Limit) (Usage, error) {
+	tx, err := l.db.Begin(ctx)
+	if err != nil {
+		return Usage{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	// Serialize takes for the key so the count cannot go stale before the
+	// insert.
+	if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock(hashtext('quota'), hashtext($1))`, key); err != nil {
+		return Usage{}, err
+	}
+
+	var now time.Time
+	if err := tx.QueryRow(ctx, `SELECT NOW()`).Scan(&now); err != nil {
+		return Usage{}, err
+	}
+	if _, err := tx.Exec(ctx, `
+		DELETE FROM quota_events WHERE key = $1 AND created_at <= $2
+	`, key, now.Add(-limit.Window)); err != nil {
+		return Usage{}, err
+	}
+
+	var used int
+	var oldest *time.Time
+	if err := tx.QueryRow(ctx, `
+		SELECT COUNT(*), MIN(created_at) FROM quota_events WHERE key = $1
+	`, key).Scan(&used, &oldest); err != nil {
+		return Usage{}, err
+	}
+
+	if used >= limit.Max {
+		// Keep the pruning.
+		if err := tx.Commit(ctx); err != nil {
+			return Usage{}, err
+		}
+		return Usage{Remaining: 0, ResetAt: oldest.Add(limit.Window)}, ErrExceeded
+	}
+
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO quota_events (key, created_at) VALUES ($1, $2)
+	`, key, now); err != nil {
+		return Usage{}, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return Usage{}, err
+	}
+
+	if oldest == nil {
+		oldest = &now
+	}
+	return Usage{Remaining: limit.Max - used - 1, ResetAt: oldest.Add(limit.Window)}, nil
+}
+
+func (l *postgresLimiter) Refund(ctx context.Context, key string) error {
+	_, err := l.db.Exec(ctx, `
+		DELETE FROM quota_events
+		WHERE id = (SELECT id FROM quota_events WHERE key = $1 ORDER BY created_at DESC LIMIT 1)
+	`, key)
+	return err
+}
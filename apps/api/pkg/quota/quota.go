@@ -0,0 +1,37 @@
+// Package quota enforces per-key usage limits over rolling windows, such as
+// "100 likes in any 24 hours".
+package quota
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrExceeded is returned by Take when the key has no quota left.
+var ErrExceeded = errors.New("quota exceeded")
+
+// Limit allows Max uses in any period of length Window.
+type Limit struct {
+	Max    int
+	Window time.Duration
+}
+
+// Usage is the state of a key's quota after a Take. ResetAt is when the
+// oldest use in the window expires and frees up quota again.
+type Usage struct {
+	Remaining int       `json:"remaining"`
+	ResetAt   time.Time `json:"resetAt"`
+}
+
+// Limiter spends quota. Implementations must be safe for concurrent use, so
+// two requests racing for the last unit cannot both get it.
+type Limiter interface {
+	// Take spends one use of key's quota under limit. If none is left it
+	// spends nothing and returns ErrExceeded, along with the usage.
+	Take(ctx context.Context, key string, limit Limit) (Usage, error)
+
+	// Refund gives back key's most recent use, for when the action it paid
+	// for did not happen. Refunding a key with no uses is a no-op.
+	Refund(ctx context.Context, key string) error
+}
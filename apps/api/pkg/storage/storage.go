@@ -15,6 +15,8 @@ import (
 type Provider interface {
 	Upload(ctx context.Context, file io.Reader, fileSize int64, contentType string, fileName string) (string, error)
 	GetPresignedURL(ctx context.Context, fileName string) (string, error)
+	Download(ctx context.Context, fileName string) ([]byte, error)
+	Delete(ctx context.Context, fileName string) error
 }
 
 // Config holds the configuration for the storage provider.
@@ -124,3 +126,27 @@ func (p *minioProvider) GetPresignedURL(ctx context.Context, fileName string) (s
 
 	return u.String(), nil
 }
+
+// Download reads a whole object into memory. It is intended for small
+// objects such as photo variants.
+func (p *minioProvider) Download(ctx context.Context, fileName string) ([]byte, error) {
+	obj, err := p.client.GetObject(ctx, p.bucketName, fileName, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to download file: %w", err)
+	}
+	defer obj.Close()
+
+	data, err := io.ReadAll(obj)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download file: %w", err)
+	}
+	return data, nil
+}
+
+// Delete removes an object. Deleting a key that does not exist is not an error.
+func (p *minioProvider) Delete(ctx context.Context, fileName string) error {
+	if err := p.client.RemoveObject(ctx, p.bucketName, fileName, minio.RemoveObjectOptions{}); err != nil {
+		return fmt.Errorf("failed to delete file: %w", err)
+	}
+	return nil
+}
@@ -1,12 +1,25 @@
 import { api } from "@/shared/api";
 import type { Profile } from "../model/types";
 
-export const getMyProfile = async () => {
+// The ETag is kept on the profile so edits can be sent with If-Match; the
+// API refuses updates based on a stale copy.
+export const getMyProfile = async (): Promise<Profile> => {
   const response = await api.get<Profile>("/api/v1/profiles/me");
-  return response.data;
+  return { ...response.data, etag: response.headers["etag"] };
 };
 
-export const updateProfile = async (data: Partial<Profile>) => {
-  const response = await api.put<Profile>("/api/v1/profiles/me", data);
-  return response.data;
+// Sent as a JSON Merge Patch: omitted fields are unchanged and null clears
+// a field.
+export type ProfilePatch = {
+  [K in keyof Profile]?: Profile[K] | null;
+};
+
+export const updateProfile = async (data: ProfilePatch, etag?: string) => {
+  const response = await api.patch<Profile>("/api/v1/profiles/me", data, {
+    headers: {
+      "Content-Type": "application/merge-patch+json",
+      ...(etag ? { "If-Match": etag } : {}),
+    },
+  });
+  return { ...response.data, etag: response.headers["etag"] };
 };
@@ -1,4 +1,5 @@
 export * from "./model/types";
 export * from "./model/schema";
 export * from "./ui/ProfileCard";
+export * from "./ui/CompletenessChecklist";
 export * from "./api";
@@ -1,11 +1,79 @@
+export type CompletenessItem =
+  | 'photos'
+  | 'bio'
+  | 'prompts'
+  | 'birthDate'
+  | 'location'
+
+export interface Completeness {
+  score: number
+  missing: CompletenessItem[]
+}
+
+export type FieldPrivacy = 'everyone' | 'matches' | 'nobody'
+
+export type PrivateField =
+  | 'height'
+  | 'gender'
+  | 'selfDescribedFlaws'
+  | 'selfDescribedStrengths'
+  | 'languages'
+  | 'education'
+  | 'occupation'
+  | 'relationshipGoal'
+  | 'smoking'
+  | 'drinking'
+  | 'kids'
+
+export type Education =
+  | 'high_school'
+  | 'trade_school'
+  | 'bachelors'
+  | 'masters'
+  | 'doctorate'
+
+export type RelationshipGoal =
+  | 'long_term'
+  | 'long_term_open'
+  | 'short_term'
+  | 'friendship'
+  | 'not_sure'
+
+export type Habit = 'never' | 'socially' | 'regularly'
+
+export type KidsStance = 'have' | 'want' | 'dont_want' | 'open'
+
+export interface Passport {
+  city: string
+  latitude: number
+  longitude: number
+  expiresAt: string
+}
+
 export interface Profile {
-  id: string
+  id?: string // Only set on the owner's profile
   userId: string
   firstName: string
-  birthDate: string
-  gender: 'male' | 'female' | 'other'
+  birthDate?: string // Only set on the owner's profile; others see age
+  age?: number
+  gender?: 'male' | 'female' | 'other' // Absent when hidden from the viewer
   height?: number
   bio?: string
+  // Structured attributes; absent when unset or hidden from the viewer
+  languages?: string[] // ISO 639-1 codes
+  education?: Education | null
+  occupation?: string | null
+  relationshipGoal?: RelationshipGoal | null
+  smoking?: Habit | null
+  drinking?: Habit | null
+  kids?: KidsStance | null
   photos: string[]
-  interactionType?: 'like' | 'pass' | null
+  isVerified?: boolean
+  passport?: Passport // Only set on the owner's profile while active
+  travelingIn?: string // Passport city, shown to other users
+  interactionType?: 'like' | 'superlike' | 'pass' | null
+  superLikedViewer?: boolean // Sent you a super like you have not answered
+  completeness?: Completeness
+  fieldVisibility?: Partial<Record<PrivateField, FieldPrivacy>> // Only set on the owner's profile
+  etag?: string // Only set on the owner's profile
 }
@@ -0,0 +1,45 @@
+import type { Completeness, CompletenessItem } from '../model/types'
+
+const itemLabels: Record<CompletenessItem, string> = {
+  photos: 'Add at least one photo',
+  bio: 'Write a bio',
+  prompts: 'Describe your strengths and flaws',
+  birthDate: 'Add your birth date',
+  location: 'Share your location',
+}
+
+interface CompletenessChecklistProps {
+  completeness: Completeness;
+}
+
+export const CompletenessChecklist = ({ completeness }: CompletenessChecklistProps) => {
+  if (completeness.missing.length === 0) {
+    return null;
+  }
+
+  return (
+    <div className="bg-white p-6 rounded-xl shadow-sm border border-gray-100">
+      <div className="flex justify-between items-center mb-2">
+        <h2 className="text-xl font-semibold">Complete your profile</h2>
+        <span className="text-sm font-medium text-pink-600">{completeness.score}%</span>
+      </div>
+      <div className="h-2 bg-gray-100 rounded-full overflow-hidden mb-4">
+        <div
+          className="h-full bg-pink-500 transition-all"
+          style={{ width: `${completeness.score}%` }}
+        />
+      </div>
+      <p className="text-sm text-gray-500 mb-3">
+        Complete profiles are shown to more people.
+      </p>
+      <ul className="space-y-2">
+        {completeness.missing.map((item) => (
+          <li key={item} className="flex items-center gap-2 text-gray-700">
+            <span className="w-4 h-4 rounded-full border-2 border-gray-300" />
+            {itemLabels[item]}
+          </li>
+        ))}
+      </ul>
+    </div>
+  );
+};
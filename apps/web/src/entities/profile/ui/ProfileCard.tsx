@@ -1,16 +1,51 @@
 import type { Profile } from '../model/types'
 
+const attributeLabels: Record<string, string> = {
+  high_school: 'High school',
+  trade_school: 'Trade school',
+  bachelors: "Bachelor's",
+  masters: "Master's",
+  doctorate: 'Doctorate',
+  long_term: 'Long-term',
+  long_term_open: 'Long-term, open to short',
+  short_term: 'Short-term',
+  friendship: 'New friends',
+  not_sure: 'Still figuring it out',
+};
+
+const kidsLabels: Record<string, string> = {
+  have: 'Has kids',
+  want: 'Wants kids',
+  dont_want: "Doesn't want kids",
+  open: 'Open to kids',
+};
+
 interface ProfileCardProps {
   profile: Profile;
 }
 
 export const ProfileCard = ({ profile }: ProfileCardProps) => {
-  const age =
-    new Date().getFullYear() - new Date(profile.birthDate).getFullYear();
+  const pills = [
+    profile.gender,
+    profile.occupation,
+    profile.education && attributeLabels[profile.education],
+    profile.relationshipGoal && attributeLabels[profile.relationshipGoal],
+    profile.kids && kidsLabels[profile.kids],
+    profile.smoking &&
+      (profile.smoking === 'never' ? 'Non-smoker' : `Smokes ${profile.smoking}`),
+    profile.drinking &&
+      (profile.drinking === 'never' ? "Doesn't drink" : `Drinks ${profile.drinking}`),
+    profile.languages?.length && profile.languages.join(', ').toUpperCase(),
+  ].filter((pill): pill is string => typeof pill === 'string' && pill !== '');
 
   return (
     <div className="bg-white rounded-xl shadow-sm overflow-hidden border border-gray-100 max-w-sm mx-auto hover:shadow-md transition-shadow">
       <div className="aspect-[3/4] relative bg-gray-100">
+        {profile.superLikedViewer && (
+          <span className="absolute top-3 left-3 z-10 px-2 py-1 bg-blue-500 text-white text-xs font-medium rounded-full shadow">
+            ★ Super liked you
+          </span>
+        )}
         {profile.photos[0] ? (
           <img
             src={profile.photos[0]}
@@ -24,11 +59,24 @@ export const ProfileCard = ({ profile }: ProfileCardProps) => {
         )}
         <div className="absolute bottom-0 left-0 right-0 bg-gradient-to-t from-black/60 to-transparent p-4 pt-12 text-white">
           <h3 className="text-2xl font-bold">
-            {profile.firstName}, {age}
+            {profile.firstName}{profile.age !== undefined && `, ${profile.age}`}
+            {profile.isVerified && (
+              <span
+                className="ml-2 align-middle inline-block px-2 py-0.5 bg-blue-500 text-white text-xs font-medium rounded-full"
+                title="Photo verified"
+              >
+                Verified
+              </span>
+            )}
           </h3>
           {profile.height && (
             <p className="text-sm opacity-90">{profile.height} cm</p>
           )}
+          {profile.travelingIn && (
+            <p className="text-sm opacity-90">
+              Currently in {profile.travelingIn}
+            </p>
+          )}
         </div>
       </div>
 
@@ -37,11 +85,18 @@ export const ProfileCard = ({ profile }: ProfileCardProps) => {
           <p className="text-gray-600 text-sm line-clamp-3">{profile.bio}</p>
         )}
 
-        <div className="mt-4 flex gap-2">
-          <span className="px-2 py-1 bg-gray-100 text-gray-600 text-xs rounded-full capitalize">
-            {profile.gender}
-          </span>
-        </div>
+        {pills.length > 0 && (
+          <div className="mt-4 flex flex-wrap gap-2">
+            {pills.map((pill) => (
+              <span
+                key={pill}
+                className="px-2 py-1 bg-gray-100 text-gray-600 text-xs rounded-full capitalize"
+              >
+                {pill}
+              </span>
+            ))}
+          </div>
+        )}
       </div>
     </div>
   );
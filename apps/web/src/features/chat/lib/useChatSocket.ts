@@ -9,6 +9,7 @@ export const useChatSocket = (matchId: string) => {
   const queryClient = useQueryClient();
   const token = useAuthStore((state) => state.token);
   const [isPartnerTyping, setIsPartnerTyping] = useState(false);
+  const [isUnmatched, setIsUnmatched] = useState(false);
 
   useEffect(() => {
     if (!token || !matchId) return;
@@ -41,6 +42,16 @@ export const useChatSocket = (matchId: string) => {
               return;
             }
 
+            // The match was ended, by either side: the conversation is gone.
+            if (payload.type === "unmatch") {
+              if (payload.userId === matchId) {
+                setIsUnmatched(true);
+                queryClient.removeQueries({ queryKey: ["messages", matchId] });
+              }
+              queryClient.invalidateQueries({ queryKey: ["matches"] });
+              return;
+            }
+
             // Assume it's a message if not typing
             const message: Message = payload;
 
@@ -106,5 +117,5 @@ export const useChatSocket = (matchId: string) => {
     }
   }, []);
 
-  return { sendMessageSocket, sendTyping, isPartnerTyping };
+  return { sendMessageSocket, sendTyping, isPartnerTyping, isUnmatched };
 };
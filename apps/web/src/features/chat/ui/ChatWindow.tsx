@@ -1,10 +1,12 @@
 import { useState, useEffect, useRef } from "react";
 import { useQuery, useMutation, useQueryClient } from "@tanstack/react-query";
+import { Link, useNavigate } from "@tanstack/react-router";
 import { getMessages, sendMessage } from "../api";
 import { useChatSocket } from "../lib/useChatSocket";
 import { MessageBubble } from "./MessageBubble";
 import { getErrorMessage } from "@/shared/lib/error";
 import { getMyProfile } from "@/entities/profile";
+import { unmatch } from "@/features/matches";
 import type { Message } from "../model/types";
 
 interface ChatWindowProps {
@@ -15,6 +17,7 @@ export const ChatWindow = ({ matchId }: ChatWindowProps) => {
   const [inputValue, setInputValue] = useState("");
   const messagesEndRef = useRef<HTMLDivElement>(null);
   const queryClient = useQueryClient();
+  const navigate = useNavigate();
 
   // Fetch my profile to know my ID (to determine isMine)
   const { data: myProfile } = useQuery({
@@ -34,7 +37,7 @@ export const ChatWindow = ({ matchId }: ChatWindowProps) => {
   });
 
   // Initialize WebSocket
-  const { sendTyping, isPartnerTyping } = useChatSocket(matchId);
+  const { sendTyping, isPartnerTyping, isUnmatched } = useChatSocket(matchId);
 
   const scrollToBottom = () => {
     messagesEndRef.current?.scrollIntoView({ behavior: "smooth" });
@@ -57,6 +60,21 @@ export const ChatWindow = ({ matchId }: ChatWindowProps) => {
     },
   });
 
+  const unmatchMutation = useMutation({
+    mutationFn: () => unmatch(matchId),
+    onSuccess: () => {
+      queryClient.removeQueries({ queryKey: ["messages", matchId] });
+      queryClient.invalidateQueries({ queryKey: ["matches"] });
+      navigate({ to: "/matches" });
+    },
+  });
+
+  const handleUnmatch = () => {
+    if (window.confirm("Unmatch? This ends the conversation for both of you.")) {
+      unmatchMutation.mutate();
+    }
+  };
+
   const handleSend = (e: React.FormEvent) => {
     e.preventDefault();
     if (!inputValue.trim()) return;
@@ -81,6 +99,17 @@ export const ChatWindow = ({ matchId }: ChatWindowProps) => {
     }
   };
 
+  if (isUnmatched) {
+    return (
+      <div className="text-center p-8 text-gray-500">
+        <p>This conversation has ended.</p>
+        <Link to="/matches" className="text-pink-500 text-sm mt-2 inline-block">
+          Back to matches
+        </Link>
+      </div>
+    );
+  }
+
   if (isLoading) {
     return <div className="flex justify-center p-8">Loading chat...</div>;
   }
@@ -95,6 +124,16 @@ export const ChatWindow = ({ matchId }: ChatWindowProps) => {
 
   return (
     <div className="flex flex-col h-[calc(100vh-200px)] bg-white rounded-xl shadow-sm border border-gray-100 overflow-hidden">
+      <div className="flex justify-end px-4 py-2 border-b border-gray-100">
+        <button
+          type="button"
+          onClick={handleUnmatch}
+          disabled={unmatchMutation.isPending}
+          className="text-sm text-gray-400 hover:text-red-500 transition disabled:opacity-50"
+        >
+          Unmatch
+        </button>
+      </div>
       <div className="flex-1 overflow-y-auto p-4 space-y-4">
         {messages?.map((msg) => (
           <MessageBubble
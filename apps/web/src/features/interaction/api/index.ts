@@ -1,14 +1,34 @@
 import { api } from "@/shared/api";
+import type { Profile } from "@/entities/profile";
+
+export type InteractionType = "like" | "superlike" | "pass";
+
+export interface InteractionResponse {
+  isMatch: boolean;
+  remaining?: number; // Likes left in the rolling window; not set for passes
+  resetAt?: string; // When the oldest like in the window frees up
+}
 
 export const sendInteraction = async (
   targetId: string,
-  type: "like" | "pass"
+  type: InteractionType
 ) => {
-  // Backend expects payload: { targetId: string, isLike: boolean }
-  const isLike = type === "like";
-  const response = await api.post("/api/v1/likes", {
+  // Backend expects payload: { targetId: string, isLike: boolean, kind?: "like" | "superlike" }
+  const isLike = type !== "pass";
+  const response = await api.post<InteractionResponse>("/api/v1/likes", {
     targetId,
     isLike,
+    kind: type === "superlike" ? "superlike" : undefined,
   });
   return response.data;
 };
+
+export interface RewindResponse {
+  kind: InteractionType; // What was undone
+  profile?: Profile; // Not set if the profile can no longer be shown
+}
+
+export const rewindInteraction = async () => {
+  const response = await api.post<RewindResponse>("/api/v1/likes/rewind");
+  return response.data;
+};
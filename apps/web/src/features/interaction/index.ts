@@ -1 +1,2 @@
 export * from "./ui/ProfileActions";
+export * from "./ui/RewindButton";
@@ -1,12 +1,13 @@
 import { useMutation, useQueryClient } from "@tanstack/react-query";
 import { useState } from "react";
-import { sendInteraction } from "../api";
-import { CrossIcon, HeartIcon } from "@/shared/ui/icons";
+import axios from "axios";
+import { sendInteraction, type InteractionType } from "../api";
+import { CrossIcon, HeartIcon, StarIcon } from "@/shared/ui/icons";
 
 interface ProfileActionsProps {
   targetUserId: string;
   onAction?: () => void;
-  initialInteractionType?: "like" | "pass" | null;
+  initialInteractionType?: InteractionType | null;
 }
 
 export const ProfileActions = ({
@@ -15,12 +16,11 @@ export const ProfileActions = ({
   initialInteractionType,
 }: ProfileActionsProps) => {
   const queryClient = useQueryClient();
-  const [interactionState, setInteractionState] = useState<
-    "like" | "pass" | null
-  >(initialInteractionType || null);
+  const [interactionState, setInteractionState] =
+    useState<InteractionType | null>(initialInteractionType || null);
 
   const mutation = useMutation({
-    mutationFn: ({ type }: { type: "like" | "pass" }) =>
+    mutationFn: ({ type }: { type: InteractionType }) =>
       sendInteraction(targetUserId, type),
     onSuccess: (_, variables) => {
       setInteractionState(variables.type);
@@ -30,11 +30,20 @@ export const ProfileActions = ({
   });
 
   const isLike = interactionState === "like";
+  const isSuperLike = interactionState === "superlike";
   const isPass = interactionState === "pass";
-  const hasInteracted = isLike || isPass;
+  const hasInteracted = isLike || isSuperLike || isPass;
+  // A 429 means the like quota, or for super likes the daily super like
+  // quota, is used up.
+  const limitReached =
+    axios.isAxiosError(mutation.error) &&
+    mutation.error.response?.status === 429;
+  const outOfLikes = limitReached && mutation.variables?.type === "like";
+  const outOfSuperLikes = limitReached;
+  const remaining = mutation.data?.remaining;
 
   return (
-    <div className="flex justify-center gap-4 mt-4">
+    <div className="flex flex-wrap justify-center gap-4 mt-4">
       <button
         onClick={() => mutation.mutate({ type: "pass" })}
         disabled={mutation.isPending || hasInteracted}
@@ -53,12 +62,12 @@ export const ProfileActions = ({
 
       <button
         onClick={() => mutation.mutate({ type: "like" })}
-        disabled={mutation.isPending || hasInteracted}
+        disabled={mutation.isPending || hasInteracted || outOfLikes}
         className={`w-12 h-12 flex items-center justify-center rounded-full transition shadow-md 
           ${
             isLike
               ? "bg-pink-600 text-white cursor-default"
-              : hasInteracted
+              : hasInteracted || outOfLikes
                 ? "bg-gray-200 text-gray-400 cursor-not-allowed opacity-50"
                 : "bg-pink-500 text-white hover:bg-pink-600 hover:scale-105"
           }`}
@@ -66,6 +75,34 @@ export const ProfileActions = ({
       >
         <HeartIcon />
       </button>
+
+      <button
+        onClick={() => mutation.mutate({ type: "superlike" })}
+        disabled={mutation.isPending || hasInteracted || outOfSuperLikes}
+        className={`w-12 h-12 flex items-center justify-center rounded-full transition shadow-md 
+          ${
+            isSuperLike
+              ? "bg-blue-600 text-white cursor-default"
+              : hasInteracted || outOfSuperLikes
+                ? "bg-gray-200 text-gray-400 cursor-not-allowed opacity-50"
+                : "bg-blue-500 text-white hover:bg-blue-600 hover:scale-105"
+          }`}
+        aria-label="Super like"
+        title={outOfSuperLikes ? "No super likes left today" : "Super like"}
+      >
+        <StarIcon />
+      </button>
+
+      {outOfLikes && (
+        <p className="basis-full text-center text-xs text-gray-500">
+          You're out of likes for now. Try again later.
+        </p>
+      )}
+      {remaining !== undefined && remaining <= 10 && (
+        <p className="basis-full text-center text-xs text-gray-500">
+          {remaining} {remaining === 1 ? "like" : "likes"} left
+        </p>
+      )}
     </div>
   );
 };
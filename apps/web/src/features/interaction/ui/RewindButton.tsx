@@ -0,0 +1,45 @@
+import { useMutation, useQueryClient } from "@tanstack/react-query";
+import axios from "axios";
+import { rewindInteraction } from "../api";
+
+const rewindError = (error: unknown) => {
+  if (axios.isAxiosError(error)) {
+    if (error.response?.status === 404) return "Nothing recent to undo";
+    if (error.response?.status === 409) return "That like is already a match";
+  }
+  return "Couldn't undo your last swipe";
+};
+
+export const RewindButton = () => {
+  const queryClient = useQueryClient();
+  const mutation = useMutation({
+    mutationFn: rewindInteraction,
+    onSuccess: () => {
+      // The profile comes back into the feed without an interaction.
+      queryClient.invalidateQueries({ queryKey: ["recommendations"] });
+    },
+  });
+
+  return (
+    <div className="flex items-center justify-end gap-3 px-4">
+      {mutation.isError && (
+        <span className="text-xs text-gray-500">
+          {rewindError(mutation.error)}
+        </span>
+      )}
+      {mutation.isSuccess && mutation.data.profile && (
+        <span className="text-xs text-gray-500">
+          {mutation.data.profile.firstName} is back in your feed
+        </span>
+      )}
+      <button
+        type="button"
+        onClick={() => mutation.mutate()}
+        disabled={mutation.isPending}
+        className="px-3 py-1.5 text-sm rounded-full border border-gray-200 text-gray-600 hover:bg-gray-50 disabled:opacity-50"
+      >
+        ↺ Undo last swipe
+      </button>
+    </div>
+  );
+};
@@ -2,13 +2,49 @@ import { api } from "@/shared/api";
 import type { Profile } from "@/entities/profile";
 
 export interface Match {
-  id: string;
+  userId: string; // The other user; also identifies the chat
   profile: Profile;
-  lastMessage?: string;
-  createdAt: string;
+  matchedAt: string;
+  lastMessage: string | null; // Snippet of the latest message
+  lastMessageAt: string | null;
+  lastActivityAt: string;
+  unreadCount: number;
+  sentFirstMessage: boolean;
 }
 
-export const getMatches = async () => {
-  const response = await api.get<Match[]>("/api/v1/matches");
+export interface MatchPage {
+  matches: Match[];
+  nextCursor?: string;
+}
+
+export const getMatches = async ({
+  pageParam,
+}: {
+  pageParam?: string;
+}): Promise<MatchPage> => {
+  const response = await api.get<MatchPage>("/api/v1/matches", {
+    params: { cursor: pageParam || undefined },
+  });
+  return response.data;
+};
+
+export const unmatch = async (userId: string) => {
+  await api.delete(`/api/v1/matches/${userId}`);
+};
+
+export interface ReceivedLike {
+  userId?: string; // Omitted while the inbox is locked
+  profile?: Profile;
+  likedAt: string;
+}
+
+export interface ReceivedLikesPage {
+  total: number;
+  locked: boolean; // True when the full list needs an upgrade
+  likes: ReceivedLike[];
+}
+
+export const getReceivedLikes = async (): Promise<ReceivedLikesPage> => {
+  const response = await api.get<ReceivedLikesPage>("/api/v1/likes/received");
   return response.data;
 };
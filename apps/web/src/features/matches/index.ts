@@ -1,2 +1,3 @@
 export * from "./ui/MatchesList";
+export * from "./ui/ReceivedLikes";
 export * from "./api";
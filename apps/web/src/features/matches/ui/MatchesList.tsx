@@ -1,18 +1,31 @@
-import { useQuery } from "@tanstack/react-query";
+import { useInfiniteQuery } from "@tanstack/react-query";
 import { Link } from "@tanstack/react-router";
 import { getMatches } from "../api";
 import { getErrorMessage } from "@/shared/lib/error";
 
+const formatActivity = (iso: string) => {
+  const date = new Date(iso);
+  return date.toDateString() === new Date().toDateString()
+    ? date.toLocaleTimeString([], { hour: "2-digit", minute: "2-digit" })
+    : date.toLocaleDateString([], { month: "short", day: "numeric" });
+};
+
 export const MatchesList = () => {
   const {
-    data: matches,
+    data,
     isLoading,
     isError,
     error,
-  } = useQuery({
+    fetchNextPage,
+    hasNextPage,
+    isFetchingNextPage,
+  } = useInfiniteQuery({
     queryKey: ["matches"],
     queryFn: getMatches,
+    initialPageParam: "",
+    getNextPageParam: (lastPage) => lastPage.nextCursor,
   });
+  const matches = data?.pages.flatMap((page) => page.matches);
 
   if (isLoading) {
     return (
@@ -43,40 +56,67 @@ export const MatchesList = () => {
   }
 
   return (
-    <div className="grid grid-cols-1 sm:grid-cols-2 md:grid-cols-3 gap-4">
-      {matches.map((match) => (
-        <Link
-          key={match.id}
-          to="/chat/$matchId"
-          params={{ matchId: match.id }}
-          className="flex items-center gap-4 p-4 bg-white rounded-xl shadow-sm border border-gray-100 hover:shadow-md transition hover:border-pink-200 group"
-        >
-          <div className="relative w-16 h-16 rounded-full overflow-hidden bg-gray-100 flex-shrink-0">
-            {match.profile.photos[0] ? (
-              <img
-                src={match.profile.photos[0]}
-                alt={match.profile.firstName}
-                className="w-full h-full object-cover group-hover:scale-110 transition duration-500"
-              />
-            ) : (
-              <div className="w-full h-full flex items-center justify-center text-gray-400 text-xs">
-                No Photo
-              </div>
-            )}
-          </div>
+    <div>
+      <div className="grid grid-cols-1 sm:grid-cols-2 md:grid-cols-3 gap-4">
+        {matches.map((match) => (
+          <Link
+            key={match.userId}
+            to="/chat/$matchId"
+            params={{ matchId: match.userId }}
+            className="flex items-center gap-4 p-4 bg-white rounded-xl shadow-sm border border-gray-100 hover:shadow-md transition hover:border-pink-200 group"
+          >
+            <div className="relative w-16 h-16 rounded-full overflow-hidden bg-gray-100 flex-shrink-0">
+              {match.profile.photos[0] ? (
+                <img
+                  src={match.profile.photos[0]}
+                  alt={match.profile.firstName}
+                  className="w-full h-full object-cover group-hover:scale-110 transition duration-500"
+                />
+              ) : (
+                <div className="w-full h-full flex items-center justify-center text-gray-400 text-xs">
+                  No Photo
+                </div>
+              )}
+            </div>
 
-          <div className="flex-1 min-w-0">
-            <h3 className="font-semibold text-gray-900 truncate">
-              {match.profile.firstName}
-            </h3>
-            <p className="text-sm text-gray-500 truncate">
-              {match.lastMessage || "Start a conversation 👋"}
-            </p>
-          </div>
+            <div className="flex-1 min-w-0">
+              <div className="flex items-baseline justify-between gap-2">
+                <h3 className="font-semibold text-gray-900 truncate">
+                  {match.profile.firstName}
+                </h3>
+                <span className="text-xs text-gray-400 flex-shrink-0">
+                  {formatActivity(match.lastActivityAt)}
+                </span>
+              </div>
+              <p
+                className={`text-sm truncate ${
+                  match.unreadCount > 0 ? "text-gray-900 font-medium" : "text-gray-500"
+                }`}
+              >
+                {match.lastMessage ?? "Start a conversation 👋"}
+              </p>
+            </div>
 
-          <div className="w-2 h-2 rounded-full bg-pink-500 opacity-0 group-hover:opacity-100 transition"></div>
-        </Link>
-      ))}
+            {match.unreadCount > 0 && (
+              <span className="min-w-5 h-5 px-1.5 rounded-full bg-pink-500 text-white text-xs font-medium flex items-center justify-center">
+                {match.unreadCount}
+              </span>
+            )}
+          </Link>
+        ))}
+      </div>
+      {hasNextPage && (
+        <div className="flex justify-center mt-6">
+          <button
+            type="button"
+            onClick={() => fetchNextPage()}
+            disabled={isFetchingNextPage}
+            className="text-sm text-pink-500 hover:text-pink-600 disabled:opacity-50"
+          >
+            {isFetchingNextPage ? "Loading..." : "Load more"}
+          </button>
+        </div>
+      )}
     </div>
   );
 };
@@ -0,0 +1,49 @@
+import { useQuery } from "@tanstack/react-query";
+import { getReceivedLikes } from "../api";
+
+export const ReceivedLikes = () => {
+  const { data } = useQuery({
+    queryKey: ["likes", "received"],
+    queryFn: getReceivedLikes,
+  });
+
+  if (!data || data.total === 0) {
+    return null;
+  }
+
+  return (
+    <section className="mb-8">
+      <h2 className="text-xl font-semibold text-gray-800 mb-3">
+        {data.total} {data.total === 1 ? "person likes" : "people like"} you
+      </h2>
+      <div className="flex gap-3 overflow-x-auto pb-2">
+        {data.likes.map((like, i) => (
+          <div
+            key={like.userId ?? i}
+            className="w-24 flex-shrink-0 text-center"
+          >
+            <div className="w-24 h-32 rounded-xl overflow-hidden bg-gray-100">
+              {like.profile?.photos[0] ? (
+                <img
+                  src={like.profile.photos[0]}
+                  alt={like.profile.firstName}
+                  className="w-full h-full object-cover"
+                />
+              ) : (
+                <div className="w-full h-full bg-gradient-to-br from-pink-200 to-purple-200 blur-sm" />
+              )}
+            </div>
+            <p className="mt-1 text-sm text-gray-700 truncate">
+              {like.profile?.firstName ?? "?"}
+            </p>
+          </div>
+        ))}
+      </div>
+      {data.locked && (
+        <p className="text-sm text-gray-500 mt-2">
+          Upgrade to see who liked you, or keep swiping to find them.
+        </p>
+      )}
+    </section>
+  );
+};
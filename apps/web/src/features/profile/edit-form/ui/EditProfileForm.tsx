@@ -1,3 +1,4 @@
+import axios from "axios";
 import { useForm } from "react-hook-form";
 import { zodResolver } from "@hookform/resolvers/zod";
 import { useState, useEffect } from "react";
@@ -8,15 +9,47 @@ import { getErrorMessage } from "@/shared/lib/error";
 import {
   profileSchema,
   type ProfileFormSchema,
+  type FieldPrivacy,
+  type PrivateField,
   getMyProfile,
   updateProfile,
 } from "@/entities/profile";
 
+const privacyOptions: { value: FieldPrivacy; label: string }[] = [
+  { value: "everyone", label: "Everyone" },
+  { value: "matches", label: "Matches only" },
+  { value: "nobody", label: "Only me" },
+];
+
+const PrivacySelect = ({
+  value,
+  onChange,
+}: {
+  value: FieldPrivacy;
+  onChange: (value: FieldPrivacy) => void;
+}) => (
+  <select
+    value={value}
+    onChange={(e) => onChange(e.target.value as FieldPrivacy)}
+    className="mt-2 w-full px-2 py-1 text-xs border border-gray-200 rounded-lg bg-white text-gray-600"
+    aria-label="Who can see this"
+  >
+    {privacyOptions.map((option) => (
+      <option key={option.value} value={option.value}>
+        Visible to: {option.label}
+      </option>
+    ))}
+  </select>
+);
+
 export const EditProfileForm = () => {
   const queryClient = useQueryClient();
   const navigate = useNavigate();
   const [uploading, setUploading] = useState(false);
   const [photoUrls, setPhotoUrls] = useState<string[]>([]);
+  const [fieldVisibility, setFieldVisibility] = useState<
+    Partial<Record<PrivateField, FieldPrivacy>>
+  >({});
 
   const { data: profile, isLoading: isLoadingProfile } = useQuery({
     queryKey: ["my-profile"],
@@ -44,9 +77,10 @@ export const EditProfileForm = () => {
         bio: profile.bio || "",
         gender: profile.gender,
         height: profile.height,
-        birthDate: profile.birthDate.split("T")[0], // Format for date input
+        birthDate: profile.birthDate?.split("T")[0] ?? "", // Format for date input
         photos: profile.photos,
       });
+      setFieldVisibility(profile.fieldVisibility ?? {});
       setPhotoUrls(profile.photos); // Assuming photos are URLs. If they are keys, we might need to resolve them or the API returns URLs.
       // In CreateForm, we stored keys in form and URLs in state.
       // If the API returns full URLs in `photos`, we need to handle that.
@@ -115,15 +149,27 @@ export const EditProfileForm = () => {
 
   const mutation = useMutation({
     mutationFn: (data: ProfileFormSchema) =>
-      updateProfile({
-        ...data,
-        birthDate: new Date(data.birthDate).toISOString(),
-        height: data.height ? Number(data.height) : undefined,
-      }),
+      updateProfile(
+        {
+          ...data,
+          birthDate: new Date(data.birthDate).toISOString(),
+          // An emptied height field clears the stored value.
+          height: data.height ? Number(data.height) : null,
+          fieldVisibility,
+        },
+        profile?.etag,
+      ),
     onSuccess: () => {
       queryClient.invalidateQueries({ queryKey: ["my-profile"] });
       navigate({ to: "/profile" });
     },
+    onError: (error) => {
+      // The profile was changed elsewhere; reload it so the next save is
+      // based on the latest version.
+      if (axios.isAxiosError(error) && error.response?.status === 412) {
+        queryClient.invalidateQueries({ queryKey: ["my-profile"] });
+      }
+    },
   });
 
   const onSubmit = (data: ProfileFormSchema) => {
@@ -140,7 +186,10 @@ export const EditProfileForm = () => {
 
       {mutation.isError && (
         <div className="mb-4 p-3 bg-red-50 text-red-600 text-sm rounded-lg border border-red-100">
-          {getErrorMessage(mutation.error)}
+          {axios.isAxiosError(mutation.error) &&
+          mutation.error.response?.status === 412
+            ? "Your profile was changed in another window. We loaded the latest version; please review and save again."
+            : getErrorMessage(mutation.error)}
         </div>
       )}
 
@@ -249,6 +298,12 @@ export const EditProfileForm = () => {
                 {errors.gender.message}
               </p>
             )}
+            <PrivacySelect
+              value={fieldVisibility.gender ?? "everyone"}
+              onChange={(gender) =>
+                setFieldVisibility({ ...fieldVisibility, gender })
+              }
+            />
           </div>
 
           <div>
@@ -266,6 +321,12 @@ export const EditProfileForm = () => {
                 {errors.height.message}
               </p>
             )}
+            <PrivacySelect
+              value={fieldVisibility.height ?? "everyone"}
+              onChange={(height) =>
+                setFieldVisibility({ ...fieldVisibility, height })
+              }
+            />
           </div>
         </div>
 
@@ -6,7 +6,7 @@ import { getRecommendations } from "../api/getRecommendations";
 import { ProfileCard } from "@/entities/profile";
 import { getErrorMessage } from "@/shared/lib/error";
 import { useIntersection } from "@/shared/lib/hooks/useIntersection";
-import { ProfileActions } from "@/features/interaction";
+import { ProfileActions, RewindButton } from "@/features/interaction";
 
 export const RecommendationFeed = () => {
   const {
@@ -85,9 +85,15 @@ export const RecommendationFeed = () => {
 
   return (
     <div className="space-y-6 pb-8">
+      <RewindButton />
       <div className="grid grid-cols-1 sm:grid-cols-2 lg:grid-cols-3 gap-6 p-4">
         {profiles.map((profile, index) => (
-          <div key={`${profile.id}-${index}`} className="flex flex-col">
+          // The interaction is part of the key so a rewound profile gets
+          // fresh actions.
+          <div
+            key={`${profile.userId}-${index}-${profile.interactionType ?? "none"}`}
+            className="flex flex-col"
+          >
             <ProfileCard profile={profile} />
             <ProfileActions 
               targetUserId={profile.userId} 
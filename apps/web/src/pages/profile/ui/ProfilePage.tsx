@@ -1,7 +1,11 @@
 import { useQuery } from "@tanstack/react-query";
 import { useState } from "react";
 import { useNavigate } from "@tanstack/react-router";
-import { getMyProfile, ProfileCard } from "@/entities/profile";
+import {
+  CompletenessChecklist,
+  getMyProfile,
+  ProfileCard,
+} from "@/entities/profile";
 import { EditProfileForm } from "@/features/profile/edit-form";
 import { CreateProfileForm } from "@/features/profile/create-form/ui/CreateProfileForm";
 import { getErrorMessage } from "@/shared/lib/error";
@@ -84,6 +88,10 @@ export const ProfilePage = () => {
             <ProfileCard profile={profile} />
           </div>
           <div className="md:col-span-2 space-y-6">
+            {profile.completeness && (
+              <CompletenessChecklist completeness={profile.completeness} />
+            )}
+
             <div className="bg-white p-6 rounded-xl shadow-sm border border-gray-100">
               <h2 className="text-xl font-semibold mb-4">About Me</h2>
               <p className="text-gray-600 whitespace-pre-wrap">
@@ -104,10 +112,7 @@ export const ProfilePage = () => {
                 </div>
                 <div>
                   <span className="block text-sm text-gray-500">Age</span>
-                  <span>
-                    {new Date().getFullYear() -
-                      new Date(profile.birthDate).getFullYear()}
-                  </span>
+                  <span>{profile.age ?? "-"}</span>
                 </div>
               </div>
             </div>
@@ -47,7 +47,7 @@ function MatchesPage() {
       <div className="grid grid-cols-2 md:grid-cols-3 gap-4">
         {matches.map((profile) => (
           <div
-            key={profile.id}
+            key={profile.userId}
             className="relative aspect-[3/4] rounded-xl overflow-hidden shadow-md group"
           >
             {profile.photos?.[0] ? (
@@ -1,2 +1,3 @@
 export * from "./CrossIcon";
 export * from "./HeartIcon";
+export * from "./StarIcon";